@@ -11,6 +11,9 @@ import (
 
 	"github.com/dangLuan01/user-manager/internal/app"
 	"github.com/dangLuan01/user-manager/internal/config"
+	"github.com/dangLuan01/user-manager/internal/db"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
 	"github.com/dangLuan01/user-manager/internal/utils"
 	"github.com/dangLuan01/user-manager/pkg/mail"
 	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
@@ -19,6 +22,7 @@ import (
 type Worker struct {
 	rabbitMQ rabbitmq.RabbitMQService
 	mailService mail.EmailProviderService
+	userRepo repository.UserRepository
 	cfg *config.Config
 }
 
@@ -38,9 +42,14 @@ func NewWorker(cfg *config.Config) *Worker {
 		log.Fatalf("⛔ Unable to init mail service:%s", err)
 	}
 
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("⛔ Unable to connect to sql:%s", err)
+	}
+
 	return &Worker{
 		rabbitMQ: rabbitMQ,
 		mailService: mailService,
+		userRepo: repository.NewSqlUserRepository(db.DB),
 		cfg: cfg,
 	}
 }
@@ -70,10 +79,38 @@ func (w *Worker) Start(ctx context.Context) error {
 		return err
 	}
 
+	go w.purgeDeletedUsers(ctx)
+
 	<-ctx.Done()
 	return ctx.Err()
 }
 
+// purgeDeletedUsers removes soft deleted users once USER_RETENTION_DAYS have
+// passed, checking every USER_PURGE_INTERVAL_MINUTES.
+func (w *Worker) purgeDeletedUsers(ctx context.Context) {
+	retention := time.Duration(utils.GetIntEnv("USER_RETENTION_DAYS", 30)) * 24 * time.Hour
+	interval := time.Duration(utils.GetIntEnv("USER_PURGE_INTERVAL_MINUTES", 60)) * time.Minute
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := v1service.PurgeDeletedUsers(w.userRepo, retention)
+		if err != nil {
+			log.Printf("Failed to purge deleted users:%s", err)
+		}
+		if purged > 0 {
+			log.Printf("Purged %d deleted users", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (w *Worker) Shutdown(ctx context.Context) error {
 	if err := w.rabbitMQ.Close(); err != nil {
 		log.Printf("Failed to close rabbitMq:%s", err)
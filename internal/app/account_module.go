@@ -0,0 +1,33 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+)
+
+type AccountModule struct {
+	routes routes.Route
+}
+
+func NewAccountModule(ctx *ModuleContext, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService, searchIndex search.SearchIndex) *AccountModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	organizationRepo := repository.NewSqlOrganizationRepository(ctx.DB)
+	accountService := v1service.NewAccountService(userRepo, organizationRepo, tokenService, cacheService, rabbitmqService, searchIndex)
+	accountHandler := v1handler.NewAccountHandler(accountService)
+	accountRoutes := v1routes.NewAccountRoutes(accountHandler)
+
+	return &AccountModule{
+		routes: accountRoutes,
+	}
+}
+func (m *AccountModule) Routes() routes.Route {
+	return m.routes
+}
@@ -5,13 +5,16 @@ import (
 
 	"github.com/dangLuan01/user-manager/internal/config"
 	"github.com/dangLuan01/user-manager/internal/db"
+	"github.com/dangLuan01/user-manager/internal/policy"
 	"github.com/dangLuan01/user-manager/internal/routes"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
 	"github.com/dangLuan01/user-manager/internal/utils"
 	"github.com/dangLuan01/user-manager/internal/validation"
 	"github.com/dangLuan01/user-manager/pkg/auth"
 	"github.com/dangLuan01/user-manager/pkg/cache"
 	"github.com/dangLuan01/user-manager/pkg/mail"
 	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -49,7 +52,30 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 
 	redisClient := config.NewRedisClient()
 	cacheRedisService := cache.NewRedisCacheService(redisClient)
-	tokenService := auth.NewJWTService(cacheRedisService)
+
+	keySet, err := auth.LoadKeySet(utils.GetEnv("JWT_KEYSET_FILE", ""))
+	if err != nil {
+		log.Fatalf("⛔ Unable to load JWT keyset:%s", err)
+		return nil, err
+	}
+
+	if keySet.Active().Algorithm == "HS256" {
+		log.Println("⚠️ Tokens are signed with the shared JWT_SECRET, set JWT_KEYSET_FILE so OIDC clients can verify ID tokens")
+	}
+
+	tokenService := auth.NewJWTService(cacheRedisService, keySet)
+
+	twoFactorKey, err := v1service.TwoFactorKey()
+	if err != nil {
+		log.Fatalf("⛔ Unable to load two-factor key:%s", err)
+		return nil, err
+	}
+
+	policyEngine, err := policy.Load(utils.GetEnv("POLICY_FILE", ""))
+	if err != nil {
+		log.Fatalf("⛔ Unable to load policies:%s", err)
+		return nil, err
+	}
 
 	factory, err := mail.NewProviderFactory(mail.ProviderMailtrap)
 	if err != nil {
@@ -69,9 +95,20 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 		Redis: redisClient,
 	}
 
+	searchIndex := search.NewMemoryIndex(v1service.UserSearchWeights)
+
 	modules := []Module{
-		NewUserModule(ctx),
-		NewAuthModule(ctx, tokenService, cacheRedisService, mailService, rabbitmqService),
+		NewUserModule(ctx, tokenService, cacheRedisService, rabbitmqService, policyEngine, searchIndex),
+		NewAuthModule(ctx, tokenService, cacheRedisService, mailService, rabbitmqService, searchIndex, twoFactorKey),
+		NewSessionModule(ctx, tokenService, cacheRedisService),
+		NewWellKnownModule(ctx, tokenService),
+		NewOAuthModule(ctx, tokenService, cacheRedisService, rabbitmqService),
+		NewServiceAccountModule(ctx, tokenService),
+		NewRBACModule(ctx, cacheRedisService),
+		NewOrganizationModule(ctx, tokenService, rabbitmqService),
+		NewInvitationModule(ctx, cacheRedisService, rabbitmqService, searchIndex),
+		NewGroupModule(ctx, cacheRedisService),
+		NewAccountModule(ctx, tokenService, cacheRedisService, rabbitmqService, searchIndex),
 	}
 
 	routes.RegisterRoute(r, tokenService, cacheRedisService ,getModuleRoutes(modules)...)
@@ -10,16 +10,20 @@ import (
 	"github.com/dangLuan01/user-manager/pkg/cache"
 	"github.com/dangLuan01/user-manager/pkg/mail"
 	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
 )
 
 type AuthModule struct {
 	routes routes.Route
 }
 
-func NewAuthModule(ctx *ModuleContext, tokenService auth.TokenService, cacheService cache.RedisCacheService, mailService mail.EmailProviderService, rabbitmqService rabbitmq.RabbitMQService) *AuthModule {
+func NewAuthModule(ctx *ModuleContext, tokenService auth.TokenService, cacheService cache.RedisCacheService, mailService mail.EmailProviderService, rabbitmqService rabbitmq.RabbitMQService, searchIndex search.SearchIndex, twoFactorKey []byte) *AuthModule {
 
 	userRepo := repository.NewSqlUserRepository(ctx.DB)
-	authService := v1service.NewAuthService(userRepo, tokenService, cacheService, mailService, rabbitmqService)
+	twoFactorRepo := repository.NewSqlTwoFactorRepository(ctx.DB)
+	recoveryCodeRepo := repository.NewSqlRecoveryCodeRepository(ctx.DB)
+	passkeyRepo := repository.NewSqlPasskeyRepository(ctx.DB)
+	authService := v1service.NewAuthService(userRepo, twoFactorRepo, recoveryCodeRepo, passkeyRepo, tokenService, cacheService, mailService, rabbitmqService, searchIndex, twoFactorKey)
 	authHandler := v1handler.NewAuthHandler(authService) 
 	authRoutes := v1routes.NewAuthRoutes(authHandler)
 
@@ -0,0 +1,32 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+)
+
+type GroupModule struct {
+	routes routes.Route
+}
+
+func NewGroupModule(ctx *ModuleContext, cacheService cache.RedisCacheService) *GroupModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	rbacRepo := repository.NewSqlRBACRepository(ctx.DB)
+	groupRepo := repository.NewSqlGroupRepository(ctx.DB)
+	rbacService := v1service.NewRBACService(rbacRepo, userRepo, groupRepo, cacheService)
+	groupService := v1service.NewGroupService(groupRepo, userRepo, rbacRepo, rbacService, cacheService)
+	groupHandler := v1handler.NewGroupHandler(groupService)
+	groupRoutes := v1routes.NewGroupRoutes(groupHandler)
+
+	return &GroupModule{
+		routes: groupRoutes,
+	}
+}
+func (m *GroupModule) Routes() routes.Route {
+	return m.routes
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+)
+
+type InvitationModule struct {
+	routes routes.Route
+}
+
+func NewInvitationModule(ctx *ModuleContext, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService, searchIndex search.SearchIndex) *InvitationModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	organizationRepo := repository.NewSqlOrganizationRepository(ctx.DB)
+	rbacRepo := repository.NewSqlRBACRepository(ctx.DB)
+	groupRepo := repository.NewSqlGroupRepository(ctx.DB)
+	rbacService := v1service.NewRBACService(rbacRepo, userRepo, groupRepo, cacheService)
+	invitationRepo := repository.NewSqlInvitationRepository(ctx.DB)
+	invitationService := v1service.NewInvitationService(invitationRepo, userRepo, organizationRepo, rbacRepo, rbacService, rabbitmqService, searchIndex)
+	invitationHandler := v1handler.NewInvitationHandler(invitationService)
+	invitationRoutes := v1routes.NewInvitationRoutes(invitationHandler)
+
+	return &InvitationModule{
+		routes: invitationRoutes,
+	}
+}
+func (m *InvitationModule) Routes() routes.Route {
+	return m.routes
+}
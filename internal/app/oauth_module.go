@@ -0,0 +1,34 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+)
+
+type OAuthModule struct {
+	routes routes.Route
+}
+
+func NewOAuthModule(ctx *ModuleContext, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService) *OAuthModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	clientRepo := repository.NewSqlOAuthClientRepository(ctx.DB)
+	serviceAccountRepo := repository.NewSqlServiceAccountRepository(ctx.DB)
+	consentRepo := repository.NewSqlOAuthConsentRepository(ctx.DB)
+	oauthService := v1service.NewOAuthService(userRepo, clientRepo, serviceAccountRepo, consentRepo, tokenService, cacheService, rabbitmqService)
+	oauthHandler := v1handler.NewOAuthHandler(oauthService)
+	oauthRoutes := v1routes.NewOAuthRoutes(oauthHandler)
+
+	return &OAuthModule{
+		routes: oauthRoutes,
+	}
+}
+func (m *OAuthModule) Routes() routes.Route {
+	return m.routes
+}
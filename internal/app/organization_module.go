@@ -0,0 +1,31 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+)
+
+type OrganizationModule struct {
+	routes routes.Route
+}
+
+func NewOrganizationModule(ctx *ModuleContext, tokenService auth.TokenService, rabbitmqService rabbitmq.RabbitMQService) *OrganizationModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	organizationRepo := repository.NewSqlOrganizationRepository(ctx.DB)
+	organizationService := v1service.NewOrganizationService(organizationRepo, userRepo, tokenService, rabbitmqService)
+	organizationHandler := v1handler.NewOrganizationHandler(organizationService)
+	organizationRoutes := v1routes.NewOrganizationRoutes(organizationHandler)
+
+	return &OrganizationModule{
+		routes: organizationRoutes,
+	}
+}
+func (m *OrganizationModule) Routes() routes.Route {
+	return m.routes
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"log"
+
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+)
+
+type RBACModule struct {
+	routes routes.Route
+}
+
+func NewRBACModule(ctx *ModuleContext, cacheService cache.RedisCacheService) *RBACModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	rbacRepo := repository.NewSqlRBACRepository(ctx.DB)
+	groupRepo := repository.NewSqlGroupRepository(ctx.DB)
+	rbacService := v1service.NewRBACService(rbacRepo, userRepo, groupRepo, cacheService)
+	middleware.InitPermissionChecker(rbacService)
+	if err := v1service.SeedPermissions(rbacRepo); err != nil {
+		log.Printf("⛔ Unable to seed permissions:%s", err)
+	}
+	rbacHandler := v1handler.NewRBACHandler(rbacService)
+	rbacRoutes := v1routes.NewRBACRoutes(rbacHandler)
+
+	return &RBACModule{
+		routes: rbacRoutes,
+	}
+}
+func (m *RBACModule) Routes() routes.Route {
+	return m.routes
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+)
+
+type ServiceAccountModule struct {
+	routes routes.Route
+}
+
+func NewServiceAccountModule(ctx *ModuleContext, tokenService auth.TokenService) *ServiceAccountModule {
+
+	serviceAccountRepo := repository.NewSqlServiceAccountRepository(ctx.DB)
+	serviceAccountService := v1service.NewServiceAccountService(serviceAccountRepo, tokenService)
+	serviceAccountHandler := v1handler.NewServiceAccountHandler(serviceAccountService)
+	serviceAccountRoutes := v1routes.NewServiceAccountRoutes(serviceAccountHandler)
+
+	return &ServiceAccountModule{
+		routes: serviceAccountRoutes,
+	}
+}
+func (m *ServiceAccountModule) Routes() routes.Route {
+	return m.routes
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+)
+
+type SessionModule struct {
+	routes routes.Route
+}
+
+func NewSessionModule(ctx *ModuleContext, tokenService auth.TokenService, cacheService cache.RedisCacheService) *SessionModule {
+
+	userRepo := repository.NewSqlUserRepository(ctx.DB)
+	rbacRepo := repository.NewSqlRBACRepository(ctx.DB)
+	groupRepo := repository.NewSqlGroupRepository(ctx.DB)
+	rbacService := v1service.NewRBACService(rbacRepo, userRepo, groupRepo, cacheService)
+	sessionService := v1service.NewSessionService(tokenService, userRepo, rbacService)
+	sessionHandler := v1handler.NewSessionHandler(sessionService)
+	sessionRoutes := v1routes.NewSessionRoutes(sessionHandler)
+
+	return &SessionModule{
+		routes: sessionRoutes,
+	}
+}
+func (m *SessionModule) Routes() routes.Route {
+	return m.routes
+}
@@ -1,24 +1,60 @@
 package app
 
 import (
+	"context"
+	"log"
+
 	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/policy"
 	"github.com/dangLuan01/user-manager/internal/repository"
 	"github.com/dangLuan01/user-manager/internal/routes"
 	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
 	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
 )
 
 type UserModule struct {
 	routes routes.Route
 }
 
-func NewUserModule(ctx *ModuleContext) *UserModule {
+func NewUserModule(ctx *ModuleContext, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService, policyEngine *policy.Engine, searchIndex search.SearchIndex) *UserModule {
 
 	userRepo := repository.NewSqlUserRepository(ctx.DB)
-	userService := v1service.NewUserService(userRepo)
+	rbacRepo := repository.NewSqlRBACRepository(ctx.DB)
+	groupRepo := repository.NewSqlGroupRepository(ctx.DB)
+	rbacService := v1service.NewRBACService(rbacRepo, userRepo, groupRepo, cacheService)
+	organizationRepo := repository.NewSqlOrganizationRepository(ctx.DB)
+	userService := v1service.NewUserService(userRepo, organizationRepo, rbacService, policyEngine, searchIndex, tokenService, cacheService, rabbitmqService)
 	UserHandler := v1handler.NewUserHandler(userService)
 	userRoutes := v1routes.NewUserRoutes(UserHandler)
 
+	// Imports are written here rather than by cmd/worker so the new users land
+	// in this process's search index.
+	if rabbitmqService != nil {
+		importWorker := v1service.NewUserImportWorker(userRepo, organizationRepo, cacheService, rabbitmqService, searchIndex)
+		if err := rabbitmqService.Consume(context.Background(), v1service.UserImportQueue, importWorker.Handle); err != nil {
+			log.Printf("⛔ Unable to consume user imports:%s", err)
+		}
+
+		exportWorker := v1service.NewUserExportWorker(userRepo, policyEngine, cacheService)
+		if err := rabbitmqService.Consume(context.Background(), v1service.UserExportQueue, exportWorker.Handle); err != nil {
+			log.Printf("⛔ Unable to consume user exports:%s", err)
+		}
+	}
+
+	// The in-process index starts empty, fill it without holding up startup.
+	go func() {
+		count, err := v1service.ReindexUsers(userRepo, searchIndex)
+		if err != nil {
+			log.Printf("⛔ Unable to build user search index:%s", err)
+			return
+		}
+		log.Printf("✅ Indexed %d users for search", count)
+	}()
+
 	return &UserModule{
 		routes: userRoutes,
 	}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/routes"
+	v1routes "github.com/dangLuan01/user-manager/internal/routes/v1"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+)
+
+type WellKnownModule struct {
+	routes routes.Route
+}
+
+func NewWellKnownModule(ctx *ModuleContext, tokenService auth.TokenService) *WellKnownModule {
+
+	wellKnownHandler := v1handler.NewWellKnownHandler(tokenService)
+	wellKnownRoutes := v1routes.NewWellKnownRoutes(wellKnownHandler)
+
+	return &WellKnownModule{
+		routes: wellKnownRoutes,
+	}
+}
+func (m *WellKnownModule) Routes() routes.Route {
+	return m.routes
+}
@@ -0,0 +1,23 @@
+package v1dto
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword 	string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangeEmailInput struct {
+	Email 			string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type ConfirmEmailInput struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+type RevertEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type CloseAccountInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
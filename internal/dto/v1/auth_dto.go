@@ -1,7 +1,10 @@
 package v1dto
 
 import (
+	"time"
+
 	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/pkg/webauthn"
 	"github.com/google/uuid"
 )
 
@@ -11,9 +14,11 @@ type LoginInput struct {
 }
 
 type LoginResponse struct {
-	AccessToken 	string 	`json:"access_token"`
-	RefreshToken 	string	`json:"refresh_token"`
+	AccessToken 	string 	`json:"access_token,omitempty"`
+	RefreshToken 	string	`json:"refresh_token,omitempty"`
 	ExpiresIn 		int 	`json:"expires_in"`
+	MFARequired 	bool 	`json:"mfa_required,omitempty"`
+	ChallengeToken 	string 	`json:"challenge_token,omitempty"`
 }
 
 type RefreshTokenInput struct {
@@ -39,6 +44,90 @@ type RequestOTPInput struct {
 	OTP string `json:"otp" binding:"required,max=6"`
 }
 
+type TwoFactorSetupResponse struct {
+	Secret 	string `json:"secret"`
+	URI 	string `json:"otpauth_uri"`
+}
+
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type TwoFactorVerifyInput struct {
+	ChallengeToken 	string `json:"challenge_token" binding:"required"`
+	Code 			string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode 	string `json:"recovery_code" binding:"required_without=Code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type PasskeyAttestationInput struct {
+	ClientDataJSON 		string `json:"clientDataJSON" binding:"required"`
+	AttestationObject 	string `json:"attestationObject" binding:"required"`
+}
+
+type PasskeyRegisterInput struct {
+	Name 		string `json:"name" binding:"omitempty,max=64"`
+	ID 			string `json:"id" binding:"required"`
+	Response 	PasskeyAttestationInput `json:"response"`
+}
+
+type PasskeyLoginBeginInput struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type PasskeyLoginBeginResponse struct {
+	SessionID 	string `json:"session_id"`
+	Options 	webauthn.RequestOptions `json:"options"`
+}
+
+type PasskeyAssertionInput struct {
+	ClientDataJSON 		string `json:"clientDataJSON" binding:"required"`
+	AuthenticatorData 	string `json:"authenticatorData" binding:"required"`
+	Signature 			string `json:"signature" binding:"required"`
+	UserHandle 			string `json:"userHandle"`
+}
+
+type PasskeyLoginInput struct {
+	SessionID 	string `json:"session_id" binding:"required"`
+	ID 			string `json:"id" binding:"required"`
+	Response 	PasskeyAssertionInput `json:"response"`
+}
+
+type PasskeyDTO struct {
+	ID 				int64 `json:"id"`
+	Name 			string `json:"name"`
+	BackupEligible 	bool `json:"backup_eligible"`
+	CloneWarning 	bool `json:"clone_warning"`
+	CreatedAt 		time.Time `json:"created_at"`
+	LastUsedAt 		*time.Time `json:"last_used_at"`
+}
+
+type PasskeyParam struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+func MapPasskeyDTO(credential models.PasskeyCredential) PasskeyDTO {
+	return PasskeyDTO{
+		ID: credential.ID,
+		Name: credential.Name,
+		BackupEligible: credential.BackupEligible,
+		CloneWarning: credential.CloneWarning,
+		CreatedAt: credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}
+}
+
+func MapPasskeysDTO(credentials []models.PasskeyCredential) []PasskeyDTO {
+	dtos := make([]PasskeyDTO, 0, len(credentials))
+	for _, credential := range credentials {
+		dtos = append(dtos, MapPasskeyDTO(credential))
+	}
+	return dtos
+}
+
 func RegisterDTOToModel(uuid uuid.UUID, user RegisterInput) models.User {
 	return models.User{
 		UUID: uuid,
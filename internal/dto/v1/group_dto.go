@@ -0,0 +1,94 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/google/uuid"
+)
+
+type GroupInput struct {
+	Name 		string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type GroupPermissionsInput struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type GroupParam struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+type GroupUserParam struct {
+	ID 		int64 `uri:"id" binding:"required,gt=0"`
+	UUID 	uuid.UUID `uri:"uuid" binding:"uuid"`
+}
+
+type GroupChildParam struct {
+	ID 		int64 `uri:"id" binding:"required,gt=0"`
+	ChildID int64 `uri:"child_id" binding:"required,gt=0"`
+}
+
+type GroupRoleParam struct {
+	ID 		int64 `uri:"id" binding:"required,gt=0"`
+	RoleID 	int64 `uri:"role_id" binding:"required,gt=0"`
+}
+
+type EffectiveQuery struct {
+	Effective bool `form:"effective"`
+}
+
+type GroupDTO struct {
+	ID 			int64 `json:"id"`
+	Name 		string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt 	time.Time `json:"created_at"`
+	UpdatedAt 	time.Time `json:"updated_at"`
+}
+
+type GroupMembersDTO struct {
+	Users 	[]uuid.UUID `json:"users"`
+	Groups 	[]GroupDTO `json:"groups,omitempty"`
+}
+
+func (input *GroupInput) MapGroupInputToModel() models.Group {
+	return models.Group{
+		Name: input.Name,
+		Description: input.Description,
+	}
+}
+
+func MapGroupDTO(group models.Group) GroupDTO {
+	return GroupDTO{
+		ID: group.ID,
+		Name: group.Name,
+		Description: group.Description,
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
+
+func MapGroupsDTO(groups []models.Group) []GroupDTO {
+	dtos := make([]GroupDTO, 0, len(groups))
+	for _, group := range groups {
+		dtos = append(dtos, MapGroupDTO(group))
+	}
+	return dtos
+}
+
+func MapGroupMembersDTO(users []uuid.UUID, groups []models.Group) GroupMembersDTO {
+	if users == nil {
+		users = []uuid.UUID{}
+	}
+
+	dto := GroupMembersDTO{
+		Users: users,
+	}
+
+	if groups != nil {
+		dto.Groups = MapGroupsDTO(groups)
+	}
+
+	return dto
+}
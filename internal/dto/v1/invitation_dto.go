@@ -0,0 +1,85 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/google/uuid"
+)
+
+type InvitationInput struct {
+	Email 				string `json:"email" binding:"required,email"`
+	RoleID 				*int64 `json:"role_id" binding:"omitempty,gt=0"`
+	OrganizationID 		*string `json:"organization_id" binding:"omitempty,uuid"`
+	OrganizationRole 	string `json:"organization_role" binding:"omitempty,oneof=owner admin support member"`
+}
+
+type InvitationQuery struct {
+	OrganizationID string `form:"organization_id" binding:"omitempty,uuid"`
+}
+
+type InvitationParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type AcceptInvitationInput struct {
+	Token 		string `json:"token" binding:"required"`
+	Name 		string `json:"name" binding:"required"`
+	Password 	string `json:"password" binding:"required,min=8"`
+	Age 		int16 `json:"age" binding:"omitempty,gt=0,lt=127"`
+}
+
+type InvitationDTO struct {
+	ID 					string `json:"id"`
+	Email 				string `json:"email"`
+	RoleID 				*int64 `json:"role_id,omitempty"`
+	OrganizationID 		*string `json:"organization_id,omitempty"`
+	OrganizationRole 	string `json:"organization_role,omitempty"`
+	InvitedBy 			uuid.UUID `json:"invited_by"`
+	Status 				string `json:"status"`
+	ExpiresAt 			time.Time `json:"expires_at"`
+	AcceptedAt 			*time.Time `json:"accepted_at,omitempty"`
+	RevokedAt 			*time.Time `json:"revoked_at,omitempty"`
+	CreatedAt 			time.Time `json:"created_at"`
+}
+
+func (input *InvitationInput) MapInvitationInputToModel() models.Invitation {
+	return models.Invitation{
+		Email: input.Email,
+		RoleID: input.RoleID,
+		OrganizationID: input.OrganizationID,
+		OrganizationRole: input.OrganizationRole,
+	}
+}
+
+func (input *AcceptInvitationInput) MapAcceptInputToModel() models.User {
+	return models.User{
+		Name: input.Name,
+		Password: input.Password,
+		Age: input.Age,
+	}
+}
+
+func MapInvitationDTO(invitation models.Invitation) InvitationDTO {
+	return InvitationDTO{
+		ID: invitation.ID,
+		Email: invitation.Email,
+		RoleID: invitation.RoleID,
+		OrganizationID: invitation.OrganizationID,
+		OrganizationRole: invitation.OrganizationRole,
+		InvitedBy: invitation.InvitedBy,
+		Status: invitation.Status(),
+		ExpiresAt: invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt: invitation.RevokedAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
+
+func MapInvitationsDTO(invitations []models.Invitation) []InvitationDTO {
+	dtos := make([]InvitationDTO, 0, len(invitations))
+	for _, invitation := range invitations {
+		dtos = append(dtos, MapInvitationDTO(invitation))
+	}
+	return dtos
+}
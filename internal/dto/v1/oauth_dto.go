@@ -0,0 +1,173 @@
+package v1dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+)
+
+type AuthorizeInput struct {
+	ResponseType 		string `form:"response_type" json:"response_type"`
+	ClientID 			string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI 		string `form:"redirect_uri" json:"redirect_uri" binding:"required"`
+	Scope 				string `form:"scope" json:"scope"`
+	State 				string `form:"state" json:"state"`
+	CodeChallenge 		string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	Nonce 				string `form:"nonce" json:"nonce" binding:"max=255"`
+}
+
+type AuthorizeDecisionInput struct {
+	AuthorizeInput
+	Approve bool `form:"approve" json:"approve"`
+}
+
+// AuthorizeResponse tells the login UI either to render a consent screen for
+// Client and Scopes, or to send the browser on to RedirectTo.
+type AuthorizeResponse struct {
+	ConsentRequired bool `json:"consent_required"`
+	Client 			OAuthClientSummaryDTO `json:"client"`
+	Scopes 			[]string `json:"scopes"`
+	RedirectTo 		string `json:"redirect_to,omitempty"`
+}
+
+// ClientAuthInput holds client credentials sent in the request body instead of
+// HTTP Basic.
+type ClientAuthInput struct {
+	ClientID 		string `form:"client_id"`
+	ClientSecret 	string `form:"client_secret"`
+}
+
+type TokenInput struct {
+	ClientAuthInput
+	GrantType 		string `form:"grant_type" binding:"required"`
+	Code 			string `form:"code"`
+	RedirectURI 	string `form:"redirect_uri"`
+	CodeVerifier 	string `form:"code_verifier"`
+	RefreshToken 	string `form:"refresh_token"`
+	Scope 			string `form:"scope"`
+}
+
+// TokenLookupInput is the body of the introspection and revocation endpoints.
+type TokenLookupInput struct {
+	ClientAuthInput
+	Token 			string `form:"token" binding:"required"`
+	TokenTypeHint 	string `form:"token_type_hint"`
+}
+
+// IntrospectionResponse follows RFC 7662. Inactive tokens only ever report
+// active=false.
+type IntrospectionResponse struct {
+	Active 		bool `json:"active"`
+	Scope 		string `json:"scope,omitempty"`
+	ClientID 	string `json:"client_id,omitempty"`
+	Sub 		string `json:"sub,omitempty"`
+	Exp 		int64 `json:"exp,omitempty"`
+	Iat 		int64 `json:"iat,omitempty"`
+	Jti 		string `json:"jti,omitempty"`
+	TokenType 	string `json:"token_type,omitempty"`
+}
+
+type TokenResponse struct {
+	AccessToken 	string `json:"access_token"`
+	TokenType 		string `json:"token_type"`
+	ExpiresIn 		int `json:"expires_in"`
+	RefreshToken 	string `json:"refresh_token,omitempty"`
+	Scope 			string `json:"scope,omitempty"`
+	IDToken 		string `json:"id_token,omitempty"`
+}
+
+type OpenIDConfiguration struct {
+	Issuer 								string `json:"issuer"`
+	AuthorizationEndpoint 				string `json:"authorization_endpoint"`
+	TokenEndpoint 						string `json:"token_endpoint"`
+	UserinfoEndpoint 					string `json:"userinfo_endpoint"`
+	IntrospectionEndpoint 				string `json:"introspection_endpoint"`
+	RevocationEndpoint 					string `json:"revocation_endpoint"`
+	JWKSURI 							string `json:"jwks_uri"`
+	ScopesSupported 					[]string `json:"scopes_supported"`
+	ResponseTypesSupported 				[]string `json:"response_types_supported"`
+	GrantTypesSupported 				[]string `json:"grant_types_supported"`
+	SubjectTypesSupported 				[]string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported 	[]string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported 	[]string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported 		[]string `json:"code_challenge_methods_supported"`
+	ClaimsSupported 					[]string `json:"claims_supported"`
+}
+
+type OAuthClientInput struct {
+	Name 			string `json:"name" binding:"required,max=100"`
+	RedirectURIs 	[]string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	Scopes 			[]string `json:"scopes" binding:"required,min=1"`
+	Public 			bool `json:"public"`
+}
+
+type OAuthClientParam struct {
+	ClientID string `uri:"client_id" binding:"required"`
+}
+
+type OAuthClientSummaryDTO struct {
+	ClientID 	string `json:"client_id"`
+	Name 		string `json:"name"`
+}
+
+type OAuthClientDTO struct {
+	ClientID 		string `json:"client_id"`
+	ClientSecret 	string `json:"client_secret,omitempty"`
+	Name 			string `json:"name"`
+	RedirectURIs 	[]string `json:"redirect_uris"`
+	Scopes 			[]string `json:"scopes"`
+	Public 			bool `json:"public"`
+	CreatedAt 		time.Time `json:"created_at"`
+	UpdatedAt 		time.Time `json:"updated_at"`
+}
+
+type OAuthConsentDTO struct {
+	ClientID 	string `json:"client_id"`
+	Scopes 		[]string `json:"scopes"`
+	CreatedAt 	time.Time `json:"created_at"`
+	UpdatedAt 	time.Time `json:"updated_at"`
+}
+
+func (input *OAuthClientInput) MapOAuthClientInputToModel() models.OAuthClient {
+	return models.OAuthClient{
+		Name: input.Name,
+		RedirectURIs: strings.Join(input.RedirectURIs, " "),
+		Scopes: strings.Join(input.Scopes, " "),
+		Public: input.Public,
+	}
+}
+
+func MapOAuthClientDTO(client models.OAuthClient) OAuthClientDTO {
+	return OAuthClientDTO{
+		ClientID: client.ClientID,
+		Name: client.Name,
+		RedirectURIs: strings.Fields(client.RedirectURIs),
+		Scopes: strings.Fields(client.Scopes),
+		Public: client.Public,
+		CreatedAt: client.CreatedAt,
+		UpdatedAt: client.UpdatedAt,
+	}
+}
+
+func MapOAuthClientsDTO(clients []models.OAuthClient) []OAuthClientDTO {
+	dtos := make([]OAuthClientDTO, 0, len(clients))
+	for _, client := range clients {
+		dtos = append(dtos, MapOAuthClientDTO(client))
+	}
+	return dtos
+}
+
+func MapOAuthConsentsDTO(consents []models.OAuthConsent) []OAuthConsentDTO {
+	dtos := make([]OAuthConsentDTO, 0, len(consents))
+	for _, consent := range consents {
+		dtos = append(dtos, OAuthConsentDTO{
+			ClientID: consent.ClientID,
+			Scopes: strings.Fields(consent.Scopes),
+			CreatedAt: consent.CreatedAt,
+			UpdatedAt: consent.UpdatedAt,
+		})
+	}
+	return dtos
+}
@@ -0,0 +1,83 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/google/uuid"
+)
+
+type OrganizationInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"omitempty,max=100"`
+}
+
+type OrganizationParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type OrganizationMemberParam struct {
+	ID 		string `uri:"id" binding:"required,uuid"`
+	UUID 	uuid.UUID `uri:"uuid" binding:"uuid"`
+}
+
+type OrganizationMemberInput struct {
+	Role string `json:"role" binding:"required,oneof=owner admin support member"`
+}
+
+type OrganizationDTO struct {
+	ID 			string `json:"id"`
+	Name 		string `json:"name"`
+	Slug 		string `json:"slug,omitempty"`
+	CreatedAt 	time.Time `json:"created_at"`
+	UpdatedAt 	time.Time `json:"updated_at"`
+}
+
+type OrganizationMemberDTO struct {
+	UserUUID 	uuid.UUID `json:"user_uuid"`
+	Role 		string `json:"role"`
+	CreatedAt 	time.Time `json:"created_at"`
+	UpdatedAt 	time.Time `json:"updated_at"`
+}
+
+func (input *OrganizationInput) MapOrganizationInputToModel() models.Organization {
+	return models.Organization{
+		Name: input.Name,
+		Slug: input.Slug,
+	}
+}
+
+func MapOrganizationDTO(organization models.Organization) OrganizationDTO {
+	return OrganizationDTO{
+		ID: organization.ID,
+		Name: organization.Name,
+		Slug: organization.Slug,
+		CreatedAt: organization.CreatedAt,
+		UpdatedAt: organization.UpdatedAt,
+	}
+}
+
+func MapOrganizationsDTO(organizations []models.Organization) []OrganizationDTO {
+	dtos := make([]OrganizationDTO, 0, len(organizations))
+	for _, organization := range organizations {
+		dtos = append(dtos, MapOrganizationDTO(organization))
+	}
+	return dtos
+}
+
+func MapOrganizationMemberDTO(member models.OrganizationMember) OrganizationMemberDTO {
+	return OrganizationMemberDTO{
+		UserUUID: member.UserUUID,
+		Role: member.Role,
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+	}
+}
+
+func MapOrganizationMembersDTO(members []models.OrganizationMember) []OrganizationMemberDTO {
+	dtos := make([]OrganizationMemberDTO, 0, len(members))
+	for _, member := range members {
+		dtos = append(dtos, MapOrganizationMemberDTO(member))
+	}
+	return dtos
+}
@@ -0,0 +1,86 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/google/uuid"
+)
+
+type RoleInput struct {
+	Name 		string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type RolePermissionsInput struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type RoleParam struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+type UserRoleParam struct {
+	UUID 	uuid.UUID `uri:"uuid" binding:"uuid"`
+	RoleID 	int64 `uri:"role_id" binding:"omitempty,gt=0"`
+}
+
+type RoleDTO struct {
+	ID 			int64 `json:"id"`
+	Name 		string `json:"name"`
+	Description string `json:"description"`
+	Permissions []string `json:"permissions,omitempty"`
+	CreatedAt 	time.Time `json:"created_at"`
+	UpdatedAt 	time.Time `json:"updated_at"`
+}
+
+type PermissionDTO struct {
+	Name 		string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (input *RoleInput) MapRoleInputToModel() models.Role {
+	return models.Role{
+		Name: input.Name,
+		Description: input.Description,
+	}
+}
+
+func MapRoleDTO(role models.Role, permissions []models.Permission) RoleDTO {
+	dto := RoleDTO{
+		ID: role.ID,
+		Name: role.Name,
+		Description: role.Description,
+		CreatedAt: role.CreatedAt,
+		UpdatedAt: role.UpdatedAt,
+	}
+
+	if permissions != nil {
+		dto.Permissions = make([]string, 0, len(permissions))
+		for _, permission := range permissions {
+			dto.Permissions = append(dto.Permissions, permission.Name)
+		}
+	}
+
+	return dto
+}
+
+func MapRolesDTO(roles []models.Role) []RoleDTO {
+	dtos := make([]RoleDTO, 0, len(roles))
+	for _, role := range roles {
+		dtos = append(dtos, MapRoleDTO(role, nil))
+	}
+	return dtos
+}
+
+func MapPermissionsDTO(permissions []models.Permission) []PermissionDTO {
+	dtos := make([]PermissionDTO, 0, len(permissions))
+	for _, permission := range permissions {
+		dtos = append(dtos, PermissionDTO{
+			Name: permission.Name,
+			Description: permission.Description,
+		})
+	}
+	return dtos
+}
@@ -0,0 +1,61 @@
+package v1dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+)
+
+type ServiceAccountInput struct {
+	Name 		string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+	Scopes 		[]string `json:"scopes" binding:"required,min=1"`
+}
+
+type ServiceAccountDTO struct {
+	ClientID 		string `json:"client_id"`
+	ClientSecret 	string `json:"client_secret,omitempty"`
+	Name 			string `json:"name"`
+	Description 	string `json:"description"`
+	Scopes 			[]string `json:"scopes"`
+	Enabled 		bool `json:"enabled"`
+	CreatedAt 		time.Time `json:"created_at"`
+	UpdatedAt 		time.Time `json:"updated_at"`
+	LastUsedAt 		*time.Time `json:"last_used_at"`
+}
+
+type ServiceAccountSecretDTO struct {
+	ClientID 					string `json:"client_id"`
+	ClientSecret 				string `json:"client_secret"`
+	PreviousSecretExpiresAt 	time.Time `json:"previous_secret_expires_at"`
+}
+
+func (input *ServiceAccountInput) MapServiceAccountInputToModel() models.ServiceAccount {
+	return models.ServiceAccount{
+		Name: input.Name,
+		Description: input.Description,
+		Scopes: strings.Join(input.Scopes, " "),
+	}
+}
+
+func MapServiceAccountDTO(account models.ServiceAccount) ServiceAccountDTO {
+	return ServiceAccountDTO{
+		ClientID: account.ClientID,
+		Name: account.Name,
+		Description: account.Description,
+		Scopes: strings.Fields(account.Scopes),
+		Enabled: account.Enabled,
+		CreatedAt: account.CreatedAt,
+		UpdatedAt: account.UpdatedAt,
+		LastUsedAt: account.LastUsedAt,
+	}
+}
+
+func MapServiceAccountsDTO(accounts []models.ServiceAccount) []ServiceAccountDTO {
+	dtos := make([]ServiceAccountDTO, 0, len(accounts))
+	for _, account := range accounts {
+		dtos = append(dtos, MapServiceAccountDTO(account))
+	}
+	return dtos
+}
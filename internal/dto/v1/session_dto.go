@@ -0,0 +1,43 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/google/uuid"
+)
+
+type SessionDTO struct {
+	ID 				string `json:"id"`
+	UserAgent 		string `json:"user_agent"`
+	IP 				string `json:"ip"`
+	CreatedAt 		time.Time `json:"created_at"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	ExpiresAt 		time.Time `json:"expires_at"`
+	Current 		bool `json:"current"`
+}
+
+type SessionParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type UserSessionParam struct {
+	UUID 	uuid.UUID `uri:"uuid" binding:"uuid"`
+	ID 		string `uri:"id" binding:"omitempty,uuid"`
+}
+
+func MapSessionsDTO(families []auth.RefreshFamily, currentJTI string) []SessionDTO {
+	dtos := make([]SessionDTO, 0, len(families))
+	for _, family := range families {
+		dtos = append(dtos, SessionDTO{
+			ID: family.ID,
+			UserAgent: family.UserAgent,
+			IP: family.IP,
+			CreatedAt: family.CreatedAt,
+			LastRefreshedAt: family.LastRefreshedAt,
+			ExpiresAt: family.ExpiresAt,
+			Current: currentJTI != "" && family.AccessJTI == currentJTI,
+		})
+	}
+	return dtos
+}
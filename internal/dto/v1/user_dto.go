@@ -1,7 +1,10 @@
 package v1dto
 
 import (
+	"time"
+
 	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/pkg/search"
 	"github.com/google/uuid"
 )
 
@@ -12,7 +15,65 @@ type UserDTO struct {
 	Age    int16 `json:"age"`
 	Level  string `json:"level"`
 	Status string `json:"status"`
+	OrganizationID *string `json:"organization_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+	PendingEmail *string `json:"pending_email,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UserListQuery pages through users.
+type UserListQuery struct {
+	Cursor 		string `form:"cursor"`
+	Limit 		uint `form:"limit" binding:"omitempty,min=1,max=100"`
+	UserFilterQuery
+}
+
+// UserFilterQuery holds the filters and order a listing and an export share.
+// Sort takes a whitelisted column, prefixed with "-" for descending order.
+type UserFilterQuery struct {
+	Sort 		string `form:"sort" binding:"omitempty,oneof=created_at -created_at updated_at -updated_at name -name email -email age -age"`
+	Status 		int8 `form:"status" binding:"omitempty,oneof=1 2"`
+	Level 		int8 `form:"level" binding:"omitempty,oneof=1 2"`
+	Email 		string `form:"email" binding:"omitempty,max=255"`
+	Name 		string `form:"name" binding:"omitempty,max=255"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo 	time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedFrom time.Time `form:"updated_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedTo 	time.Time `form:"updated_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastLoginFrom 	time.Time `form:"last_login_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastLoginTo 	time.Time `form:"last_login_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	PasswordChangedTo 	time.Time `form:"password_changed_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	EmailVerified 		*bool `form:"email_verified"`
+}
+
+type PageMeta struct {
+	NextCursor 		string `json:"next_cursor,omitempty"`
+	Limit 			uint `json:"limit"`
+	// Counted before access policies hide users, so pages may hold fewer rows.
+	// Only callers holding users:read get it, to anyone else it would reveal
+	// how many hidden users match a filter.
+	TotalEstimate 	*int64 `json:"total_estimate,omitempty"`
 }
+type UserSearchQuery struct {
+	Q 		string `form:"q" binding:"required,min=2,max=100"`
+	Limit 	uint `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type UserSearchResult struct {
+	User 		*UserDTO `json:"user"`
+	Score 		float64 `json:"score"`
+	// HTML escaped field text with the matched fragments wrapped in <em>.
+	Highlights 	map[string]string `json:"highlights"`
+}
+
+type ReindexResponse struct {
+	Indexed int `json:"indexed"`
+}
+
 type CreateUserInput struct {
 	UUID   uuid.UUID `json:"uuid"`
 	Name     string `json:"name" binding:"required"`
@@ -33,6 +94,41 @@ type UpdateUserInput struct {
 	Level    int8   `json:"level" binding:"omitempty,oneof=1 2"`
 }
 
+const (
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch = "application/json-patch+json"
+)
+
+// UserPatch is a PATCH body, MediaTypeJSONPatch selects RFC 6902 and anything
+// else RFC 7396.
+type UserPatch struct {
+	MediaType 	string
+	Body 		[]byte
+}
+
+// PatchUserInput holds the fields a patch changes, nil means untouched so
+// validation skips it.
+type PatchUserInput struct {
+	Name     *string `json:"name" binding:"omitnil,min=1,max=255"`
+	Email    *string `json:"email" binding:"omitnil,email"`
+	Password *string `json:"password" binding:"omitnil,min=8"`
+	Age      *int16  `json:"age" binding:"omitnil,gt=0,lt=127"`
+	Status   *int8   `json:"status" binding:"omitnil,oneof=1 2"`
+	Level    *int8   `json:"level" binding:"omitnil,oneof=1 2"`
+}
+
+// UserPatchDocument is the user as a patch sees it. The password is write-only,
+// a patch may add it but never reads it.
+func UserPatchDocument(user models.User) map[string]any {
+	return map[string]any{
+		"name": user.Name,
+		"email": user.Email,
+		"age": user.Age,
+		"level": user.Level,
+		"status": user.Status,
+	}
+}
+
 func (input * CreateUserInput) MapCreateInputToModel() models.User {
 	return models.User{
 		Name: input.Name,
@@ -63,6 +159,14 @@ func MapUserDTO(user models.User) *UserDTO {
 		Age: user.Age,
 		Level: formatLevel(user.Level),
 		Status: formatStatus(user.Status),
+		OrganizationID: user.OrganizationID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		LastLoginAt: user.LastLoginAt,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+		PendingEmail: user.PendingEmail,
+		DeletedAt: user.DeletedAt,
 	}
 }
 
@@ -74,6 +178,14 @@ func MapUsersDTO(users []models.User) []UserDTO {
 	return dtos
 }
 
+func MapUserSearchResult(user models.User, hit search.Hit) UserSearchResult {
+	return UserSearchResult{
+		User: MapUserDTO(user),
+		Score: hit.Score,
+		Highlights: hit.Highlights,
+	}
+}
+
 func formatLevel(level int8) string {
 	switch level {
 	case 1:
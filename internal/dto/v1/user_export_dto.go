@@ -0,0 +1,58 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+)
+
+// UserExportQuery takes the listing filters. Columns is a comma separated
+// list, Async queues the export whatever its size.
+type UserExportQuery struct {
+	Format 	string `form:"format" binding:"omitempty,oneof=csv ndjson xlsx"`
+	Columns string `form:"columns" binding:"omitempty,max=500"`
+	Async 	bool `form:"async"`
+	UserFilterQuery
+}
+
+type UserExportJobParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// UserExportDownloadQuery is the signature of a download link, the link
+// itself stands in for authentication.
+type UserExportDownloadQuery struct {
+	Expires 	int64 `form:"expires" binding:"required"`
+	Signature 	string `form:"signature" binding:"required,hexadecimal"`
+}
+
+type UserExportJobDTO struct {
+	ID 			string `json:"id"`
+	Status 		string `json:"status"`
+	Format 		string `json:"format"`
+	Columns 	[]string `json:"columns"`
+	Rows 		int `json:"rows"`
+	Error 		string `json:"error,omitempty"`
+	// Set once the file is written, the link expires before the file does.
+	DownloadURL string `json:"download_url,omitempty"`
+	CreatedAt 	time.Time `json:"created_at"`
+	StartedAt 	*time.Time `json:"started_at,omitempty"`
+	FinishedAt 	*time.Time `json:"finished_at,omitempty"`
+	ExpiresAt 	*time.Time `json:"expires_at,omitempty"`
+}
+
+func MapUserExportJobDTO(job models.UserExportJob, downloadURL string) *UserExportJobDTO {
+	return &UserExportJobDTO{
+		ID: job.ID,
+		Status: job.Status,
+		Format: job.Format,
+		Columns: job.Columns,
+		Rows: job.Rows,
+		Error: job.Error,
+		DownloadURL: downloadURL,
+		CreatedAt: job.CreatedAt,
+		StartedAt: job.StartedAt,
+		FinishedAt: job.FinishedAt,
+		ExpiresAt: job.ExpiresAt,
+	}
+}
@@ -0,0 +1,82 @@
+package v1dto
+
+import (
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+)
+
+const (
+	MediaTypeCSV = "text/csv"
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+type UserImportQuery struct {
+	DryRun 		bool `form:"dry_run"`
+	OnDuplicate string `form:"on_duplicate" binding:"omitempty,oneof=skip update"`
+	Invite 		bool `form:"invite"`
+}
+
+// UserImport is an uploaded file, MediaType picks the parser.
+type UserImport struct {
+	MediaType 	string
+	Body 		[]byte
+	UserImportQuery
+}
+
+type UserImportJobParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type UserImportRowErrorDTO struct {
+	Line 	int `json:"line"`
+	Email 	string `json:"email,omitempty"`
+	Errors 	map[string]string `json:"errors"`
+}
+
+// UserImportJobDTO reports a queued import, or for a dry run what the import
+// would do. A dry run has no ID.
+type UserImportJobDTO struct {
+	ID 			string `json:"id,omitempty"`
+	Status 		string `json:"status"`
+	DryRun 		bool `json:"dry_run"`
+	OnDuplicate string `json:"on_duplicate"`
+	Invite 		bool `json:"invite"`
+	Total 		int `json:"total"`
+	Created 	int `json:"created"`
+	Updated 	int `json:"updated"`
+	Skipped 	int `json:"skipped"`
+	Failed 		int `json:"failed"`
+	Errors 		[]UserImportRowErrorDTO `json:"errors"`
+	CreatedAt 	time.Time `json:"created_at"`
+	StartedAt 	*time.Time `json:"started_at,omitempty"`
+	FinishedAt 	*time.Time `json:"finished_at,omitempty"`
+}
+
+func MapUserImportJobDTO(job models.UserImportJob) *UserImportJobDTO {
+	errors := make([]UserImportRowErrorDTO, 0, len(job.Errors))
+	for _, rowError := range job.Errors {
+		errors = append(errors, UserImportRowErrorDTO{
+			Line: rowError.Line,
+			Email: rowError.Email,
+			Errors: rowError.Errors,
+		})
+	}
+
+	return &UserImportJobDTO{
+		ID: job.ID,
+		Status: job.Status,
+		DryRun: job.DryRun,
+		OnDuplicate: job.OnDuplicate,
+		Invite: job.Invite,
+		Total: job.Total,
+		Created: job.Created,
+		Updated: job.Updated,
+		Skipped: job.Skipped,
+		Failed: job.Failed,
+		Errors: errors,
+		CreatedAt: job.CreatedAt,
+		StartedAt: job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -0,0 +1,169 @@
+package v1handler
+
+import (
+	"net/http"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AccountHandler struct {
+	service v1service.AccountService
+}
+
+func NewAccountHandler(service v1service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		service: service,
+	}
+}
+
+func (ah *AccountHandler) GetAccount(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	user, err := ah.service.GetAccount(ctx, payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(user.Version))
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserDTO(user))
+}
+
+func (ah *AccountHandler) PatchAccount(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	patch, ok := bindUserPatch(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := ah.service.PatchAccount(ctx, payload.UserUUID, patch)
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(errs))
+		return
+	}
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(user.Version))
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserDTO(user))
+}
+
+func (ah *AccountHandler) ChangePassword(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.ChangePasswordInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := ah.service.ChangePassword(ctx, payload.UserUUID, input); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (ah *AccountHandler) ChangeEmail(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.ChangeEmailInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	user, err := ah.service.ChangeEmail(ctx, payload.UserUUID, input)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(user.Version))
+	utils.ResponseSuccess(ctx, http.StatusAccepted, "Confirmation code sent", v1dto.MapUserDTO(user))
+}
+
+func (ah *AccountHandler) ConfirmEmail(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.ConfirmEmailInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	user, err := ah.service.ConfirmEmail(ctx, payload.UserUUID, input.Code)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(user.Version))
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserDTO(user))
+}
+
+func (ah *AccountHandler) RevertEmail(ctx *gin.Context) {
+	var input v1dto.RevertEmailInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := ah.service.RevertEmail(ctx, input.Token); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (ah *AccountHandler) CloseAccount(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.CloseAccountInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := ah.service.CloseAccount(ctx, payload.UserUUID, input.CurrentPassword); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
@@ -7,6 +7,7 @@ import (
 	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
 	"github.com/dangLuan01/user-manager/internal/utils"
 	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,16 +29,15 @@ func (ah *AuthHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, expiresIn, err := ah.authService.Login(ctx, input.Email, input.Password)
+	response, err := ah.authService.Login(ctx, input.Email, input.Password)
 	if err != nil {
 		utils.ResponseError(ctx, err)
 		return
 	}
 
-	response := v1dto.LoginResponse{
-		AccessToken: 	accessToken,
-		RefreshToken: 	refreshToken,
-		ExpiresIn: 		expiresIn,
+	if response.MFARequired {
+		utils.ResponseSuccess(ctx, http.StatusOK, "Two-factor authentication required", response)
+		return
 	}
 
 	utils.ResponseSuccess(ctx, http.StatusOK, "Login successfully!", response)
@@ -141,4 +141,208 @@ func (ah *AuthHandler) RegisterOTP(ctx *gin.Context) {
 	}
 
 	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully!")
+}
+
+func (ah *AuthHandler) SetupTwoFactor(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	response, err := ah.authService.SetupTwoFactor(ctx, payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Scan the code with your authenticator app", response)
+}
+
+func (ah *AuthHandler) ConfirmTwoFactor(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	codes, err := ah.authService.ConfirmTwoFactor(ctx, payload.UserUUID, input.Code)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Two-factor authentication enabled", v1dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (ah *AuthHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	codes, err := ah.authService.RegenerateRecoveryCodes(ctx, payload.UserUUID, input.Code)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Recovery codes regenerated", v1dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (ah *AuthHandler) DisableTwoFactor(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := ah.authService.DisableTwoFactor(ctx, payload.UserUUID, input.Code); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Two-factor authentication disabled")
+}
+
+func (ah *AuthHandler) VerifyTwoFactor(ctx *gin.Context) {
+	var input v1dto.TwoFactorVerifyInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	response, err := ah.authService.VerifyTwoFactor(ctx, input.ChallengeToken, input.Code, input.RecoveryCode)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Login successfully!", response)
+}
+
+func (ah *AuthHandler) BeginPasskeyRegistration(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	options, err := ah.authService.BeginPasskeyRegistration(ctx, payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", options)
+}
+
+func (ah *AuthHandler) FinishPasskeyRegistration(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.PasskeyRegisterInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	credential, err := ah.authService.FinishPasskeyRegistration(ctx, payload.UserUUID, input)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Passkey registered", v1dto.MapPasskeyDTO(credential))
+}
+
+func (ah *AuthHandler) BeginPasskeyLogin(ctx *gin.Context) {
+	var input v1dto.PasskeyLoginBeginInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	response, err := ah.authService.BeginPasskeyLogin(ctx, input.Email)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", response)
+}
+
+func (ah *AuthHandler) FinishPasskeyLogin(ctx *gin.Context) {
+	var input v1dto.PasskeyLoginInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	response, err := ah.authService.FinishPasskeyLogin(ctx, input)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Login successfully!", response)
+}
+
+func (ah *AuthHandler) ListPasskeys(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	credentials, err := ah.authService.ListPasskeys(ctx, payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapPasskeysDTO(credentials))
+}
+
+func (ah *AuthHandler) DeletePasskey(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.PasskeyParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := ah.authService.DeletePasskey(ctx, payload.UserUUID, param.ID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
 }
\ No newline at end of file
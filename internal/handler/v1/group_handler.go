@@ -0,0 +1,287 @@
+package v1handler
+
+import (
+	"net/http"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type GroupHandler struct {
+	service v1service.GroupService
+}
+
+func NewGroupHandler(service v1service.GroupService) *GroupHandler {
+	return &GroupHandler{
+		service: service,
+	}
+}
+
+func (gh *GroupHandler) ListGroups(ctx *gin.Context) {
+	groups, err := gh.service.ListGroups(ctx)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapGroupsDTO(groups))
+}
+
+func (gh *GroupHandler) GetGroup(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	group, err := gh.service.GetGroup(ctx, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapGroupDTO(group))
+}
+
+func (gh *GroupHandler) CreateGroup(ctx *gin.Context) {
+	var input v1dto.GroupInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	group, err := gh.service.CreateGroup(ctx, input.MapGroupInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", v1dto.MapGroupDTO(group))
+}
+
+func (gh *GroupHandler) UpdateGroup(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.GroupInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	group, err := gh.service.UpdateGroup(ctx, param.ID, input.MapGroupInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapGroupDTO(group))
+}
+
+func (gh *GroupHandler) DeleteGroup(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.DeleteGroup(ctx, param.ID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) ListMembers(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var query v1dto.EffectiveQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	users, groups, err := gh.service.ListMembers(ctx, param.ID, query.Effective)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapGroupMembersDTO(users, groups))
+}
+
+func (gh *GroupHandler) AddUser(ctx *gin.Context) {
+	var param v1dto.GroupUserParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.AddUser(ctx, param.ID, param.UUID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) RemoveUser(ctx *gin.Context) {
+	var param v1dto.GroupUserParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.RemoveUser(ctx, param.ID, param.UUID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) AddChildGroup(ctx *gin.Context) {
+	var param v1dto.GroupChildParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.AddChildGroup(ctx, param.ID, param.ChildID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) RemoveChildGroup(ctx *gin.Context) {
+	var param v1dto.GroupChildParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.RemoveChildGroup(ctx, param.ID, param.ChildID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) ListGroupRoles(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	roles, err := gh.service.ListGroupRoles(ctx, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapRolesDTO(roles))
+}
+
+func (gh *GroupHandler) AssignGroupRole(ctx *gin.Context) {
+	var param v1dto.GroupRoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.AssignGroupRole(ctx, param.ID, param.RoleID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) RemoveGroupRole(ctx *gin.Context) {
+	var param v1dto.GroupRoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.RemoveGroupRole(ctx, param.ID, param.RoleID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) ListGroupPermissions(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	permissions, err := gh.service.ListGroupPermissions(ctx, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapPermissionsDTO(permissions))
+}
+
+func (gh *GroupHandler) SetGroupPermissions(ctx *gin.Context) {
+	var param v1dto.GroupParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.GroupPermissionsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := gh.service.SetGroupPermissions(ctx, param.ID, input.Permissions); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (gh *GroupHandler) ListUserGroups(ctx *gin.Context) {
+	var param GetUserByUUIDParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var query v1dto.EffectiveQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	groups, err := gh.service.UserGroups(ctx, param.Uuid, query.Effective)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapGroupsDTO(groups))
+}
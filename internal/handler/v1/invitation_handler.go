@@ -0,0 +1,125 @@
+package v1handler
+
+import (
+	"net/http"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	service v1service.InvitationService
+}
+
+func NewInvitationHandler(service v1service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		service: service,
+	}
+}
+
+func (ih *InvitationHandler) CreateInvitation(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.InvitationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	invitation, err := ih.service.CreateInvitation(ctx, payload.UserUUID, input.MapInvitationInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", v1dto.MapInvitationDTO(invitation))
+}
+
+func (ih *InvitationHandler) ListInvitations(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var query v1dto.InvitationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	invitations, err := ih.service.ListInvitations(ctx, payload.UserUUID, query.OrganizationID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapInvitationsDTO(invitations))
+}
+
+func (ih *InvitationHandler) ResendInvitation(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.InvitationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	invitation, err := ih.service.ResendInvitation(ctx, payload.UserUUID, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapInvitationDTO(invitation))
+}
+
+func (ih *InvitationHandler) RevokeInvitation(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.InvitationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := ih.service.RevokeInvitation(ctx, payload.UserUUID, param.ID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (ih *InvitationHandler) AcceptInvitation(ctx *gin.Context) {
+	var input v1dto.AcceptInvitationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	user, err := ih.service.AcceptInvitation(ctx, input.Token, input.MapAcceptInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", v1dto.MapUserDTO(user))
+}
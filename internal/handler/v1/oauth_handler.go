@@ -0,0 +1,287 @@
+package v1handler
+
+import (
+	"errors"
+	"net/http"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	service v1service.OAuthService
+}
+
+func NewOAuthHandler(service v1service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		service: service,
+	}
+}
+
+// responseOAuthError renders protocol errors the way OAuth clients expect them
+// and falls back to the regular error envelope for everything else.
+func responseOAuthError(ctx *gin.Context, err error) {
+	var oauthErr *v1service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	ctx.JSON(status, gin.H{
+		"error": oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
+
+func (oh *OAuthHandler) Authorize(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.AuthorizeInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	response, err := oh.service.Authorize(ctx, payload.UserUUID, input)
+	if err != nil {
+		responseOAuthError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", response)
+}
+
+func (oh *OAuthHandler) DecideAuthorization(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.AuthorizeDecisionInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	response, err := oh.service.DecideAuthorization(ctx, payload.UserUUID, input)
+	if err != nil {
+		responseOAuthError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", response)
+}
+
+func (oh *OAuthHandler) Token(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var input v1dto.TokenInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		responseOAuthError(ctx, &v1service.OAuthError{Code: "invalid_request", Description: "grant_type is required"})
+		return
+	}
+
+	response, err := oh.service.Token(ctx, input)
+	if err != nil {
+		responseOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (oh *OAuthHandler) Introspect(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var input v1dto.TokenLookupInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		responseOAuthError(ctx, &v1service.OAuthError{Code: "invalid_request", Description: "token is required"})
+		return
+	}
+
+	response, err := oh.service.Introspect(ctx, input)
+	if err != nil {
+		responseOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (oh *OAuthHandler) Revoke(ctx *gin.Context) {
+	var input v1dto.TokenLookupInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		responseOAuthError(ctx, &v1service.OAuthError{Code: "invalid_request", Description: "token is required"})
+		return
+	}
+
+	if err := oh.service.Revoke(ctx, input); err != nil {
+		responseOAuthError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusOK)
+}
+
+func (oh *OAuthHandler) CreateClient(ctx *gin.Context) {
+	var input v1dto.OAuthClientInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	client, secret, err := oh.service.CreateClient(ctx, input.MapOAuthClientInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	// The secret is only ever shown here, we keep nothing but its hash.
+	clientDTO := v1dto.MapOAuthClientDTO(client)
+	clientDTO.ClientSecret = secret
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", clientDTO)
+}
+
+func (oh *OAuthHandler) ListClients(ctx *gin.Context) {
+	clients, err := oh.service.ListClients(ctx)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOAuthClientsDTO(clients))
+}
+
+func (oh *OAuthHandler) GetClient(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	client, err := oh.service.GetClient(ctx, param.ClientID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOAuthClientDTO(client))
+}
+
+func (oh *OAuthHandler) UpdateClient(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.OAuthClientInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	client, err := oh.service.UpdateClient(ctx, param.ClientID, input.MapOAuthClientInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOAuthClientDTO(client))
+}
+
+func (oh *OAuthHandler) DeleteClient(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := oh.service.DeleteClient(ctx, param.ClientID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (oh *OAuthHandler) ListConsents(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	consents, err := oh.service.ListConsents(ctx, payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOAuthConsentsDTO(consents))
+}
+
+func (oh *OAuthHandler) RevokeConsent(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := oh.service.RevokeConsent(ctx, payload.UserUUID, param.ClientID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (oh *OAuthHandler) UserInfo(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	// First-party tokens are not scoped and may read every claim.
+	scopes, ok := auth.GetScopes(ctx)
+	if !ok {
+		scopes = []string{"openid", "profile", "email"}
+	}
+
+	claims, err := oh.service.UserInfo(ctx, payload.UserUUID, scopes)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, claims)
+}
+
+func (oh *OAuthHandler) OpenIDConfiguration(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, oh.service.OpenIDConfiguration(ctx))
+}
@@ -0,0 +1,224 @@
+package v1handler
+
+import (
+	"net/http"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	service v1service.OrganizationService
+}
+
+func NewOrganizationHandler(service v1service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+	}
+}
+
+func (oh *OrganizationHandler) CreateOrganization(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var input v1dto.OrganizationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	organization, err := oh.service.CreateOrganization(ctx, payload.UserUUID, input.MapOrganizationInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", v1dto.MapOrganizationDTO(organization))
+}
+
+func (oh *OrganizationHandler) ListOrganizations(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	organizations, err := oh.service.ListOrganizations(ctx, payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOrganizationsDTO(organizations))
+}
+
+func (oh *OrganizationHandler) GetOrganization(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	organization, err := oh.service.GetOrganization(ctx, payload.UserUUID, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOrganizationDTO(organization))
+}
+
+func (oh *OrganizationHandler) UpdateOrganization(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.OrganizationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	organization, err := oh.service.UpdateOrganization(ctx, payload.UserUUID, param.ID, input.MapOrganizationInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOrganizationDTO(organization))
+}
+
+func (oh *OrganizationHandler) DeleteOrganization(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := oh.service.DeleteOrganization(ctx, payload.UserUUID, param.ID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (oh *OrganizationHandler) ListMembers(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	members, err := oh.service.ListMembers(ctx, payload.UserUUID, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOrganizationMembersDTO(members))
+}
+
+func (oh *OrganizationHandler) SetMemberRole(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationMemberParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.OrganizationMemberInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	member, err := oh.service.SetMemberRole(ctx, payload.UserUUID, param.ID, param.UUID, input.Role)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapOrganizationMemberDTO(member))
+}
+
+func (oh *OrganizationHandler) RemoveMember(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationMemberParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := oh.service.RemoveMember(ctx, payload.UserUUID, param.ID, param.UUID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (oh *OrganizationHandler) SwitchOrganization(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	var param v1dto.OrganizationParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	response, err := oh.service.SwitchOrganization(ctx, payload.UserUUID, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", response)
+}
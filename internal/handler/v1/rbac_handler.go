@@ -0,0 +1,201 @@
+package v1handler
+
+import (
+	"net/http"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type RBACHandler struct {
+	service v1service.RBACService
+}
+
+func NewRBACHandler(service v1service.RBACService) *RBACHandler {
+	return &RBACHandler{
+		service: service,
+	}
+}
+
+func (rh *RBACHandler) ListRoles(ctx *gin.Context) {
+	roles, err := rh.service.ListRoles(ctx)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapRolesDTO(roles))
+}
+
+func (rh *RBACHandler) GetRole(ctx *gin.Context) {
+	var param v1dto.RoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	role, permissions, err := rh.service.GetRole(ctx, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapRoleDTO(role, permissions))
+}
+
+func (rh *RBACHandler) CreateRole(ctx *gin.Context) {
+	var input v1dto.RoleInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	role, err := rh.service.CreateRole(ctx, input.MapRoleInputToModel(), input.Permissions)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", v1dto.MapRoleDTO(role, nil))
+}
+
+func (rh *RBACHandler) UpdateRole(ctx *gin.Context) {
+	var param v1dto.RoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.RoleInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	role, err := rh.service.UpdateRole(ctx, param.ID, input.MapRoleInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	if input.Permissions != nil {
+		if err := rh.service.SetRolePermissions(ctx, param.ID, input.Permissions); err != nil {
+			utils.ResponseError(ctx, err)
+			return
+		}
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapRoleDTO(role, nil))
+}
+
+func (rh *RBACHandler) DeleteRole(ctx *gin.Context) {
+	var param v1dto.RoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := rh.service.DeleteRole(ctx, param.ID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (rh *RBACHandler) SetRolePermissions(ctx *gin.Context) {
+	var param v1dto.RoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.RolePermissionsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := rh.service.SetRolePermissions(ctx, param.ID, input.Permissions); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (rh *RBACHandler) ListPermissions(ctx *gin.Context) {
+	permissions, err := rh.service.ListPermissions(ctx)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapPermissionsDTO(permissions))
+}
+
+func (rh *RBACHandler) ListMyPermissions(ctx *gin.Context) {
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized"))
+		return
+	}
+
+	permissions, err := rh.service.UserPermissions(payload.UserUUID)
+	if err != nil {
+		utils.ResponseError(ctx, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err))
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", permissions)
+}
+
+func (rh *RBACHandler) ListUserRoles(ctx *gin.Context) {
+	var param v1dto.UserRoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	roles, err := rh.service.ListUserRoles(ctx, param.UUID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapRolesDTO(roles))
+}
+
+func (rh *RBACHandler) AssignUserRole(ctx *gin.Context) {
+	var param v1dto.UserRoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := rh.service.AssignUserRole(ctx, param.UUID, param.RoleID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (rh *RBACHandler) RemoveUserRole(ctx *gin.Context) {
+	var param v1dto.UserRoleParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := rh.service.RemoveUserRole(ctx, param.UUID, param.RoleID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
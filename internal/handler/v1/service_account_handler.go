@@ -0,0 +1,148 @@
+package v1handler
+
+import (
+	"net/http"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	v1service "github.com/dangLuan01/user-manager/internal/service/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceAccountHandler struct {
+	service v1service.ServiceAccountService
+}
+
+func NewServiceAccountHandler(service v1service.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		service: service,
+	}
+}
+
+func (sh *ServiceAccountHandler) CreateServiceAccount(ctx *gin.Context) {
+	var input v1dto.ServiceAccountInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	account, secret, err := sh.service.CreateServiceAccount(ctx, input.MapServiceAccountInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	// The secret is only ever shown here, we keep nothing but its hash.
+	accountDTO := v1dto.MapServiceAccountDTO(account)
+	accountDTO.ClientSecret = secret
+
+	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", accountDTO)
+}
+
+func (sh *ServiceAccountHandler) ListServiceAccounts(ctx *gin.Context) {
+	accounts, err := sh.service.ListServiceAccounts(ctx)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapServiceAccountsDTO(accounts))
+}
+
+func (sh *ServiceAccountHandler) GetServiceAccount(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	account, err := sh.service.GetServiceAccount(ctx, param.ClientID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapServiceAccountDTO(account))
+}
+
+func (sh *ServiceAccountHandler) UpdateServiceAccount(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var input v1dto.ServiceAccountInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	account, err := sh.service.UpdateServiceAccount(ctx, param.ClientID, input.MapServiceAccountInputToModel())
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapServiceAccountDTO(account))
+}
+
+func (sh *ServiceAccountHandler) EnableServiceAccount(ctx *gin.Context) {
+	sh.setEnabled(ctx, true)
+}
+
+func (sh *ServiceAccountHandler) DisableServiceAccount(ctx *gin.Context) {
+	sh.setEnabled(ctx, false)
+}
+
+func (sh *ServiceAccountHandler) setEnabled(ctx *gin.Context, enabled bool) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := sh.service.SetServiceAccountEnabled(ctx, param.ClientID, enabled); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
+
+func (sh *ServiceAccountHandler) RotateServiceAccountSecret(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	secret, err := sh.service.RotateServiceAccountSecret(ctx, param.ClientID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.ServiceAccountSecretDTO{
+		ClientID: param.ClientID,
+		ClientSecret: secret,
+		PreviousSecretExpiresAt: time.Now().Add(v1service.ServiceAccountSecretGracePeriod),
+	})
+}
+
+func (sh *ServiceAccountHandler) DeleteServiceAccount(ctx *gin.Context) {
+	var param v1dto.OAuthClientParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	if err := sh.service.DeleteServiceAccount(ctx, param.ClientID); err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSatus(ctx, http.StatusNoContent)
+}
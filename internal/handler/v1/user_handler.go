@@ -1,6 +1,11 @@
 package v1handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
 	"net/http"
 
 	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
@@ -10,6 +15,7 @@ import (
 	"github.com/dangLuan01/user-manager/internal/utils"
 	"github.com/dangLuan01/user-manager/internal/validation"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 type UserHandler struct {
@@ -24,12 +30,18 @@ func NewUserHandler(service v1service.UserService) *UserHandler {
 	}
 }
 func (uh *UserHandler) GetAllUser(ctx *gin.Context)  {
-	users, err := uh.service.GetAllUser()
+	var query v1dto.UserListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	users, meta, err := uh.service.GetAllUser(ctx, query)
 	if err != nil {
 		utils.ResponseError(ctx, err)
 		return
 	}
-	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully" ,v1dto.MapUsersDTO(users))
+	utils.ResponsePaginated(ctx, http.StatusOK, "Successfully", v1dto.MapUsersDTO(users), meta)
 	
 }
 func (uh *UserHandler) GetUserByUUID(ctx *gin.Context)  {
@@ -40,7 +52,7 @@ func (uh *UserHandler) GetUserByUUID(ctx *gin.Context)  {
 		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
 		return 
 	}
-	user, err := uh.service.GetUserByUUID(param.Uuid)
+	user, err := uh.service.GetUserByUUID(ctx, param.Uuid)
 
 	if err != nil {
 
@@ -48,6 +60,7 @@ func (uh *UserHandler) GetUserByUUID(ctx *gin.Context)  {
 		return
 	}
 	
+	ctx.Header("ETag", utils.ETag(user.Version))
 	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully",v1dto.MapUserDTO(user))
 }
 func (uh *UserHandler) CreateUser(ctx *gin.Context) {
@@ -61,7 +74,7 @@ func (uh *UserHandler) CreateUser(ctx *gin.Context) {
 	}
 	user := input.MapCreateInputToModel()
 	
-	createUser, err := uh.service.CreateUser(user)
+	createUser, err := uh.service.CreateUser(ctx, user)
 	if err != nil {
 
 		utils.ResponseError(ctx, err)
@@ -69,6 +82,7 @@ func (uh *UserHandler) CreateUser(ctx *gin.Context) {
 		return
 	}
 
+	ctx.Header("ETag", utils.ETag(createUser.Version))
 	utils.ResponseSuccess(ctx, http.StatusCreated, "Successfully", v1dto.MapUserDTO(createUser))
 }
 func (uh *UserHandler) UpdateUser(ctx *gin.Context)  {
@@ -89,14 +103,65 @@ func (uh *UserHandler) UpdateUser(ctx *gin.Context)  {
 
 	user := input.MapUpdateInputToModel()
 
-	updateUser, err := uh.service.UpdateUser(param.Uuid, user)
+	updateUser, err := uh.service.UpdateUser(ctx, param.Uuid, user)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(updateUser.Version))
+	utils.ResponseSuccess(ctx, http.StatusOK ,"Successfully", v1dto.MapUserDTO(updateUser))
+}
+// bindUserPatch reads a PATCH body in application/merge-patch+json,
+// application/json as its alias, or application/json-patch+json.
+func bindUserPatch(ctx *gin.Context) (v1dto.UserPatch, bool) {
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	switch mediaType {
+	case v1dto.MediaTypeMergePatch, v1dto.MediaTypeJSONPatch, "application/json":
+	default:
+		ctx.Header("Accept-Patch", v1dto.MediaTypeMergePatch + ", " + v1dto.MediaTypeJSONPatch)
+		utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeUnsupportedMediaType), "Unsupported patch format"))
+		return v1dto.UserPatch{}, false
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		utils.ResponseError(ctx, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to read body", err))
+		return v1dto.UserPatch{}, false
+	}
+
+	return v1dto.UserPatch{
+		MediaType: mediaType,
+		Body: body,
+	}, true
+}
+
+func (uh *UserHandler) PatchUser(ctx *gin.Context) {
+	var param GetUserByUUIDParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	patch, ok := bindUserPatch(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := uh.service.PatchUser(ctx, param.Uuid, patch)
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(errs))
+		return
+	}
 	if err != nil {
 		utils.ResponseError(ctx, err)
 		return
 	}
 
-	utils.ResponseSuccess(ctx, http.StatusOK ,"Successfully", updateUser)
+	ctx.Header("ETag", utils.ETag(user.Version))
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserDTO(user))
 }
+
 func (uh *UserHandler) DeleteUser(ctx *gin.Context)  {
 	var param GetUserByUUIDParam
 	err := ctx.ShouldBindUri(&param)
@@ -105,10 +170,195 @@ func (uh *UserHandler) DeleteUser(ctx *gin.Context)  {
 		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
 		return 
 	}
-	if err := uh.service.DeleteUser(param.Uuid); err != nil {
+	if err := uh.service.DeleteUser(ctx, param.Uuid); err != nil {
 		utils.ResponseError(ctx, err)
 		return
 	}
 	
 	utils.ResponseSatus(ctx, http.StatusNoContent)
 }
+
+func (uh *UserHandler) ListDeletedUsers(ctx *gin.Context) {
+	var query v1dto.UserListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	users, meta, err := uh.service.ListDeletedUsers(ctx, query)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponsePaginated(ctx, http.StatusOK, "Successfully", v1dto.MapUsersDTO(users), meta)
+}
+
+func (uh *UserHandler) RestoreUser(ctx *gin.Context) {
+	var param GetUserByUUIDParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	user, err := uh.service.RestoreUser(ctx, param.Uuid)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(user.Version))
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserDTO(user))
+}
+
+func (uh *UserHandler) SearchUsers(ctx *gin.Context) {
+	var query v1dto.UserSearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	results, err := uh.service.SearchUsers(ctx, query)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", results)
+}
+
+func (uh *UserHandler) ReindexUsers(ctx *gin.Context) {
+	count, err := uh.service.ReindexUsers(ctx)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.ReindexResponse{Indexed: count})
+}
+
+func (uh *UserHandler) ImportUsers(ctx *gin.Context) {
+	var query v1dto.UserImportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, v1service.MaxUserImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ResponseError(ctx, utils.NewError(string(utils.ErrCodeBadRequest), fmt.Sprintf("The file is larger than %d bytes, split it", tooLarge.Limit)))
+			return
+		}
+
+		utils.ResponseError(ctx, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to read body", err))
+		return
+	}
+
+	job, err := uh.service.ImportUsers(ctx, v1dto.UserImport{
+		MediaType: mediaType,
+		Body: body,
+		UserImportQuery: query,
+	})
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	if job.DryRun {
+		utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserImportJobDTO(job))
+		return
+	}
+
+	ctx.Header("Location", "/api/v1/users/import/" + job.ID)
+	utils.ResponseSuccess(ctx, http.StatusAccepted, "Import queued", v1dto.MapUserImportJobDTO(job))
+}
+
+func (uh *UserHandler) GetImportJob(ctx *gin.Context) {
+	var param v1dto.UserImportJobParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	job, err := uh.service.GetImportJob(ctx, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserImportJobDTO(job))
+}
+
+func (uh *UserHandler) ExportUsers(ctx *gin.Context) {
+	var query v1dto.UserExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	export, err := uh.service.ExportUsers(ctx, query)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	if export.Job != nil {
+		ctx.Header("Location", "/api/v1/users/export/" + export.Job.ID)
+		utils.ResponseSuccess(ctx, http.StatusAccepted, "Export queued", v1dto.MapUserExportJobDTO(*export.Job, ""))
+		return
+	}
+
+	ctx.Header("Content-Type", export.ContentType)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.FileName}))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusOK)
+
+	// The status is gone once rows are written, a failure can only cut the file short.
+	if _, err := export.Write(ctx.Writer); err != nil {
+		log.Printf("Failed to stream user export:%s", err)
+		ctx.Abort()
+	}
+}
+
+func (uh *UserHandler) GetExportJob(ctx *gin.Context) {
+	var param v1dto.UserExportJobParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	job, downloadURL, err := uh.service.GetExportJob(ctx, param.ID)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	utils.ResponseSuccess(ctx, http.StatusOK, "Successfully", v1dto.MapUserExportJobDTO(job, downloadURL))
+}
+
+func (uh *UserHandler) DownloadUserExport(ctx *gin.Context) {
+	var param v1dto.UserExportJobParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	var query v1dto.UserExportDownloadQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ResponseValidator(ctx, validation.HandlerValidationErrors(err))
+		return
+	}
+
+	job, path, err := uh.service.OpenUserExport(param.ID, query.Expires, query.Signature)
+	if err != nil {
+		utils.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Type", v1service.UserExportContentType(job.Format))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.FileAttachment(path, job.FileName)
+}
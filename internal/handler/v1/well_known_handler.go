@@ -0,0 +1,25 @@
+package v1handler
+
+import (
+	"net/http"
+
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type WellKnownHandler struct {
+	tokenService auth.TokenService
+}
+
+func NewWellKnownHandler(tokenService auth.TokenService) *WellKnownHandler {
+	return &WellKnownHandler{
+		tokenService: tokenService,
+	}
+}
+
+// JWKS is consumed by other services, so it is served as a bare key set
+// rather than inside the usual response envelope.
+func (wh *WellKnownHandler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, wh.tokenService.JWKS())
+}
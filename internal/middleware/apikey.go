@@ -3,7 +3,9 @@ package middleware
 import (
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/dangLuan01/user-manager/pkg/auth"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,6 +16,11 @@ func ApiKeyMiddleware() gin.HandlerFunc {
 	}
 	return func(ctx *gin.Context)  {
 		apiKey := ctx.GetHeader("X-API-Key")
+		// Service accounts authenticate with their own token instead of the shared key.
+		if apiKey == "" && hasServiceToken(ctx) {
+			ctx.Next()
+			return
+		}
 		if apiKey == "" {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 				"error": "API key is required",
@@ -28,4 +35,18 @@ func ApiKeyMiddleware() gin.HandlerFunc {
 		}
 		ctx.Next()
 	}
-}
\ No newline at end of file
+}
+
+func hasServiceToken(ctx *gin.Context) bool {
+	authHeder := ctx.GetHeader("Authorization")
+	if jwtService == nil || !strings.HasPrefix(authHeder, "Bearer ") {
+		return false
+	}
+
+	payload, err := jwtService.DecryptAccessTokenPayload(strings.TrimPrefix(authHeder, "Bearer "))
+	if err != nil {
+		return false
+	}
+
+	return payload.PrincipalType == auth.PrincipalService
+}
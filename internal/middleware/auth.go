@@ -42,9 +42,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		if jti, ok := claims["jti"].(string); ok {
-			key := "blacklist:" + jti
-			exists, err := cacheService.Exits(key)
-			if err == nil && exists {
+			revoked, err := jwtService.IsAccessTokenBlacklisted(jti)
+			if err == nil && revoked {
 				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 					"error": "Token revoked",
 				})
@@ -59,7 +58,36 @@ func AuthMiddleware() gin.HandlerFunc {
 			})
 			return 
 		}
-		ctx.Set("data", payload)
+
+		principalType := payload.PrincipalType
+		if principalType == "" {
+			principalType = auth.PrincipalUser
+		}
+
+		if principalType == auth.PrincipalService {
+			disabled, err := jwtService.IsServicePrincipalDisabled(payload.ClientID)
+			if err == nil && disabled {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "Service account disabled",
+				})
+				return 
+			}
+		}
+
+		ctx.Set(auth.ContextPayloadKey, payload)
+		ctx.Set(auth.ContextPrincipalTypeKey, principalType)
+		if jti, ok := claims["jti"].(string); ok {
+			ctx.Set(auth.ContextTokenIDKey, jti)
+		}
+		if scopes, ok := auth.ScopesFromClaims(claims); ok {
+			ctx.Set(auth.ContextScopesKey, scopes)
+		}
+		if clientID, ok := claims["client_id"].(string); ok {
+			ctx.Set(auth.ContextClientIDKey, clientID)
+		}
+		if organizationID, ok := claims["org"].(string); ok {
+			ctx.Set(auth.ContextOrganizationKey, organizationID)
+		}
 		
 		ctx.Next()
 		
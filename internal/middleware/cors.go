@@ -9,7 +9,8 @@ import (
 func CORSMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Writer.Header().Set("Access-Control-Allow_Origin", "*")
-		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, x-api-key, Authorization")
+		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, x-api-key, Authorization, If-Match")
+		ctx.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		ctx.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		ctx.Writer.Header().Set("Access-Control-Max-Age", "86400")
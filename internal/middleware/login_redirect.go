@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginRedirectMiddleware sends browsers that arrive without a bearer token to
+// the login UI, passing the original query along so it can resume the request
+// once the user has signed in.
+func LoginRedirectMiddleware() gin.HandlerFunc {
+	loginURL := utils.GetEnv("OAUTH_LOGIN_URL", "")
+
+	return func(ctx *gin.Context) {
+		if loginURL == "" || ctx.GetHeader("Authorization") != "" {
+			ctx.Next()
+			return
+		}
+
+		separator := "?"
+		if strings.Contains(loginURL, "?") {
+			separator = "&"
+		}
+
+		ctx.Redirect(http.StatusFound, loginURL + separator + ctx.Request.URL.RawQuery)
+		ctx.Abort()
+	}
+}
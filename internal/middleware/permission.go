@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PermissionChecker interface {
+	HasPermission(userUUID uuid.UUID, permission string) (bool, error)
+}
+
+var permissionChecker PermissionChecker
+
+func InitPermissionChecker(checker PermissionChecker) {
+	permissionChecker = checker
+}
+
+// RequirePermission checks the caller's roles. Service accounts have no roles,
+// they are granted a permission when their token carries a scope of that name.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if auth.GetPrincipalType(ctx) == auth.PrincipalService {
+			scopes, _ := auth.GetScopes(ctx)
+			for _, permission := range permissions {
+				if !slices.Contains(scopes, permission) {
+					ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+						"error": "Permission denied",
+					})
+					return
+				}
+			}
+
+			ctx.Next()
+			return
+		}
+
+		payload, ok := auth.GetPayload(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized",
+			})
+			return
+		}
+
+		for _, permission := range permissions {
+			granted, err := permissionChecker.HasPermission(payload.UserUUID, permission)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Unable to check permissions",
+				})
+				return
+			}
+
+			if !granted {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "Permission denied",
+				})
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
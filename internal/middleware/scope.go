@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope only restricts OAuth access tokens. First-party tokens issued by
+// /auth/login carry no scope claim and keep full access.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		granted, ok := auth.GetScopes(ctx)
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				ctx.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="` + scope + `"`)
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "Insufficient scope",
+				})
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireFirstPartyToken rejects tokens issued to an OAuth client or a service
+// account, so a client can never answer a consent prompt on the user's behalf.
+func RequireFirstPartyToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, scoped := auth.GetScopes(ctx); scoped || auth.GetClientID(ctx) != "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Only a signed-in user may do this",
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group collects users and other groups. Members of a child group are
+// effective members of every group above it.
+type Group struct {
+	ID          int64     `db:"id" goqu:"skipinsert"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type GroupUser struct {
+	GroupID   int64     `db:"group_id"`
+	UserUUID  uuid.UUID `db:"user_uuid"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type GroupChild struct {
+	GroupID      int64     `db:"group_id"`
+	ChildGroupID int64     `db:"child_group_id"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type GroupRole struct {
+	GroupID   int64     `db:"group_id"`
+	RoleID    int64     `db:"role_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type GroupPermission struct {
+	GroupID      int64 `db:"group_id"`
+	PermissionID int64 `db:"permission_id"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation lets an admin bring in a user without choosing their password.
+// Only a hash of the emailed token is stored.
+type Invitation struct {
+	ID               string     `db:"id"`
+	Email            string     `db:"email"`
+	TokenHash        string     `db:"token_hash"`
+	RoleID           *int64     `db:"role_id"`
+	OrganizationID   *string    `db:"organization_id"`
+	OrganizationRole string     `db:"organization_role"`
+	InvitedBy        uuid.UUID  `db:"invited_by"`
+	ExpiresAt        time.Time  `db:"expires_at"`
+	AcceptedAt       *time.Time `db:"accepted_at"`
+	RevokedAt        *time.Time `db:"revoked_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+}
+
+func (invitation Invitation) Status() string {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return InvitationAccepted
+	case invitation.RevokedAt != nil:
+		return InvitationRevoked
+	case invitation.ExpiresAt.Before(time.Now()):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
@@ -0,0 +1,9 @@
+package models
+
+// Statuses of a background job kept in Redis.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
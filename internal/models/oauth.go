@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RedirectURIs and Scopes are space separated, the same format OAuth uses on the wire.
+type OAuthClient struct {
+	ClientID         string    `db:"client_id"`
+	ClientSecretHash *string   `db:"client_secret_hash"`
+	Name             string    `db:"name"`
+	RedirectURIs     string    `db:"redirect_uris"`
+	Scopes           string    `db:"scopes"`
+	Public           bool      `db:"public"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
+}
+
+type OAuthConsent struct {
+	UserUUID  uuid.UUID `db:"user_uuid"`
+	ClientID  string    `db:"client_id"`
+	Scopes    string    `db:"scopes"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
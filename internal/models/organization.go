@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OrganizationRoleOwner   = "owner"
+	OrganizationRoleAdmin   = "admin"
+	OrganizationRoleSupport = "support"
+	OrganizationRoleMember  = "member"
+)
+
+type Organization struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	Slug      string    `db:"slug"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID string    `db:"organization_id"`
+	UserUUID       uuid.UUID `db:"user_uuid"`
+	Role           string    `db:"role"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
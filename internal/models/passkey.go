@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasskeyCredential struct {
+	ID             int64      `db:"id" goqu:"skipinsert"`
+	UserUUID       uuid.UUID  `db:"user_uuid"`
+	CredentialID   string     `db:"credential_id"`
+	PublicKey      []byte     `db:"public_key"`
+	SignCount      int64      `db:"sign_count"`
+	AAGUID         string     `db:"aaguid"`
+	Name           string     `db:"name"`
+	BackupEligible bool       `db:"backup_eligible"`
+	CloneWarning   bool       `db:"clone_warning"`
+	CreatedAt      time.Time  `db:"created_at"`
+	LastUsedAt     *time.Time `db:"last_used_at"`
+}
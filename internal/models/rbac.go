@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LevelAdmin is the legacy users.level value for administrators.
+const LevelAdmin int8 = 1
+
+type Role struct {
+	ID          int64     `db:"id" goqu:"skipinsert"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type Permission struct {
+	ID          int64  `db:"id" goqu:"skipinsert"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
+}
+
+type RolePermission struct {
+	RoleID       int64 `db:"role_id"`
+	PermissionID int64 `db:"permission_id"`
+}
+
+type UserRole struct {
+	UserUUID  uuid.UUID `db:"user_uuid"`
+	RoleID    int64     `db:"role_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecoveryCode struct {
+	ID        int64      `db:"id" goqu:"skipinsert"`
+	UserUUID  uuid.UUID  `db:"user_uuid"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	UsedIP    *string    `db:"used_ip"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// A rotated secret keeps working until PreviousSecretExpiresAt so callers can
+// roll out the new one without downtime.
+type ServiceAccount struct {
+	ClientID                string     `db:"client_id"`
+	Name                    string     `db:"name"`
+	Description             string     `db:"description"`
+	ClientSecretHash        string     `db:"client_secret_hash"`
+	PreviousSecretHash      *string    `db:"previous_secret_hash"`
+	PreviousSecretExpiresAt *time.Time `db:"previous_secret_expires_at"`
+	Scopes                  string     `db:"scopes"`
+	Enabled                 bool       `db:"enabled"`
+	CreatedAt               time.Time  `db:"created_at"`
+	UpdatedAt               time.Time  `db:"updated_at"`
+	LastUsedAt              *time.Time `db:"last_used_at"`
+}
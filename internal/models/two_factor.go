@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TwoFactor struct {
+	UserUUID    uuid.UUID  `db:"user_uuid"`
+	Secret      string     `db:"secret"`
+	Enabled     bool       `db:"enabled"`
+	ConfirmedAt *time.Time `db:"confirmed_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
@@ -1,6 +1,17 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// users.level values other than LevelAdmin, and users.status values.
+const (
+	LevelCustomer int8 = 2
+	StatusActive  int8 = 1
+	StatusHidden  int8 = 2
+)
 
 type User struct {
 	UUID     uuid.UUID `db:"uuid"`
@@ -10,4 +21,19 @@ type User struct {
 	Age      int16  `db:"age" goqu:"omitempty"`
 	Level    int8   `db:"level"`
 	Status   int8   `db:"status"`
+	// The tenant the account lives in, nil for users outside any organization.
+	OrganizationID *string `db:"organization_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	LastLoginAt       *time.Time `db:"last_login_at"`
+	EmailVerifiedAt   *time.Time `db:"email_verified_at"`
+	PasswordChangedAt *time.Time `db:"password_changed_at"`
+	// The address an email change waits to confirm, Email stays in use until then.
+	PendingEmail *string `db:"pending_email"`
+	// Bumped by every write, the ETag clients send back in If-Match.
+	Version int64 `db:"version"`
+	// Soft deleted users keep their row until the purge, PurgedAt marks a row
+	// that was anonymized rather than removed.
+	DeletedAt *time.Time `db:"deleted_at"`
+	PurgedAt  *time.Time `db:"purged_at"`
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// UserExportJob tracks an export written to a file in the background. It is
+// kept in Redis as JSON until the file expires. RequestedBy holds the user
+// UUID or service client that asked for it, the only caller who may fetch it.
+type UserExportJob struct {
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`
+	Format         string     `json:"format"`
+	Columns        []string   `json:"columns"`
+	OrganizationID *string    `json:"organization_id"`
+	RequestedBy    string     `json:"requested_by"`
+	Rows           int        `json:"rows"`
+	FileName       string     `json:"file_name"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// What an import does with a row whose email already belongs to a user.
+const (
+	UserImportSkipDuplicates   = "skip"
+	UserImportUpdateDuplicates = "update"
+)
+
+// UserImportJob tracks one bulk import. It is kept in Redis as JSON and its
+// counters move as the queued rows are written.
+type UserImportJob struct {
+	ID             string               `json:"id"`
+	Status         string               `json:"status"`
+	DryRun         bool                 `json:"dry_run"`
+	OnDuplicate    string               `json:"on_duplicate"`
+	Invite         bool                 `json:"invite"`
+	OrganizationID *string              `json:"organization_id"`
+	Total          int                  `json:"total"`
+	Created        int                  `json:"created"`
+	Updated        int                  `json:"updated"`
+	Skipped        int                  `json:"skipped"`
+	Failed         int                  `json:"failed"`
+	Errors         []UserImportRowError `json:"errors"`
+	CreatedAt      time.Time            `json:"created_at"`
+	StartedAt      *time.Time           `json:"started_at"`
+	FinishedAt     *time.Time           `json:"finished_at"`
+}
+
+// UserImportRowError points at a line of the uploaded file, Errors is keyed by
+// field like a validation response.
+type UserImportRowError struct {
+	Line   int               `json:"line"`
+	Email  string            `json:"email"`
+	Errors map[string]string `json:"errors"`
+}
@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"net"
+	"slices"
+)
+
+var operators = []string{"eq", "ne", "in", "not_in", "contains", "not_contains", "gt", "gte", "lt", "lte", "cidr"}
+
+func (c Condition) holds(attributes map[string]any) bool {
+	left, ok := attributes[c.Attribute]
+	if !ok {
+		return false
+	}
+
+	right := c.Value
+	if c.Ref != "" {
+		if right, ok = attributes[c.Ref]; !ok {
+			return false
+		}
+	}
+
+	switch c.Operator {
+	case "eq":
+		return equal(left, right)
+	case "ne":
+		return !equal(left, right)
+	case "in":
+		return slices.ContainsFunc(list(right), func(item any) bool { return equal(left, item) })
+	case "not_in":
+		return !slices.ContainsFunc(list(right), func(item any) bool { return equal(left, item) })
+	case "contains":
+		return slices.ContainsFunc(list(left), func(item any) bool { return equal(item, right) })
+	case "not_contains":
+		return !slices.ContainsFunc(list(left), func(item any) bool { return equal(item, right) })
+	case "gt", "gte", "lt", "lte":
+		return compare(c.Operator, left, right)
+	case "cidr":
+		return inNetworks(left, list(right))
+	}
+
+	return false
+}
+
+// normalize brings attribute values and values decoded from JSON to the same
+// types, numbers become float64.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+
+	return value
+}
+
+func equal(left, right any) bool {
+	return normalize(left) == normalize(right)
+}
+
+func list(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case []string:
+		items := make([]any, 0, len(v))
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return items
+	case nil:
+		return nil
+	}
+
+	return []any{value}
+}
+
+func compare(operator string, left, right any) bool {
+	l, ok := normalize(left).(float64)
+	if !ok {
+		return false
+	}
+
+	r, ok := normalize(right).(float64)
+	if !ok {
+		return false
+	}
+
+	switch operator {
+	case "gt":
+		return l > r
+	case "gte":
+		return l >= r
+	case "lt":
+		return l < r
+	default:
+		return l <= r
+	}
+}
+
+func inNetworks(value any, networks []any) bool {
+	address, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		cidr, ok := network.(string)
+		if !ok {
+			continue
+		}
+
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
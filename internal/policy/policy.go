@@ -0,0 +1,256 @@
+package policy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/google/uuid"
+)
+
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny Effect = "deny"
+	// Silently drops the listed fields from a write instead of rejecting it.
+	EffectStrip Effect = "strip"
+)
+
+// Condition compares an attribute such as "subject.uuid" with a literal Value,
+// or with another attribute named by Ref.
+type Condition struct {
+	Attribute 	string `json:"attr"`
+	Operator 	string `json:"op"`
+	Value 		any `json:"value,omitempty"`
+	Ref 		string `json:"ref,omitempty"`
+}
+
+// Policy applies when the action matches and every condition holds. With Fields
+// set it is a field rule and only affects those attributes of a write.
+type Policy struct {
+	ID 			string `json:"id"`
+	Description string `json:"description"`
+	Effect 		Effect `json:"effect"`
+	Actions 	[]string `json:"actions"`
+	Fields 		[]string `json:"fields,omitempty"`
+	Conditions 	[]Condition `json:"conditions"`
+}
+
+type Subject struct {
+	UserUUID 		uuid.UUID
+	Email 			string
+	Level 			int8
+	PrincipalType 	string
+	ClientID 		string
+	Scopes 			[]string
+	Permissions 	[]string
+	// The organization the subject acts in and their role there, empty outside one.
+	OrganizationID 		string
+	OrganizationRole 	string
+}
+
+type Environment struct {
+	IP 		string
+	Time 	time.Time
+}
+
+// Request describes one access. Resource is the target as stored, Changes holds
+// the attributes a write would set, keyed by field name.
+// ResourceOrganizationRole is the target's role in the subject's organization,
+// empty when it is not a member there.
+type Request struct {
+	Subject 	Subject
+	Action 		string
+	Resource 	models.User
+	ResourceOrganizationRole 	string
+	Changes 	map[string]any
+	Environment Environment
+}
+
+type Decision struct {
+	Allowed 	bool
+	PolicyID 	string
+	Strip 		[]string
+	Rejected 	[]string
+}
+
+type Engine struct {
+	policies []Policy
+}
+
+//go:embed default_policies.json
+var defaultPolicies []byte
+
+// Load reads the policies from the JSON file at path, the built-in set is used
+// when path is empty.
+func Load(path string) (*Engine, error) {
+	raw := defaultPolicies
+	if path != "" {
+		var err error
+		raw, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read policies: %w", err)
+		}
+	}
+
+	var file struct {
+		Policies []Policy `json:"policies"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse policies: %w", err)
+	}
+
+	return NewEngine(file.Policies)
+}
+
+func NewEngine(policies []Policy) (*Engine, error) {
+	for _, policy := range policies {
+		if err := policy.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Engine{
+		policies: policies,
+	}, nil
+}
+
+func (p Policy) validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("policy: missing id")
+	}
+
+	if len(p.Actions) == 0 {
+		return fmt.Errorf("policy %q: no actions", p.ID)
+	}
+
+	switch p.Effect {
+	case EffectAllow:
+		if len(p.Fields) > 0 {
+			return fmt.Errorf("policy %q: fields can only be denied or stripped", p.ID)
+		}
+	case EffectDeny:
+	case EffectStrip:
+		if len(p.Fields) == 0 {
+			return fmt.Errorf("policy %q: strip needs fields", p.ID)
+		}
+	default:
+		return fmt.Errorf("policy %q: unknown effect %q", p.ID, p.Effect)
+	}
+
+	for _, condition := range p.Conditions {
+		if !slices.Contains(operators, condition.Operator) {
+			return fmt.Errorf("policy %q: unknown operator %q", p.ID, condition.Operator)
+		}
+	}
+
+	return nil
+}
+
+// Evaluate denies by default. A matching deny policy overrides every allow,
+// field rules then decide which of the requested changes survive.
+func (e *Engine) Evaluate(req Request) Decision {
+	attributes := req.attributes()
+
+	var decision Decision
+	for _, policy := range e.policies {
+		if !policy.appliesTo(req.Action) || !policy.matches(attributes) {
+			continue
+		}
+
+		if len(policy.Fields) == 0 {
+			if policy.Effect == EffectDeny {
+				return Decision{Allowed: false, PolicyID: policy.ID}
+			}
+
+			if !decision.Allowed {
+				decision.Allowed = true
+				decision.PolicyID = policy.ID
+			}
+			continue
+		}
+
+		for field := range req.Changes {
+			if !slices.Contains(policy.Fields, field) {
+				continue
+			}
+
+			if policy.Effect == EffectDeny && !slices.Contains(decision.Rejected, field) {
+				decision.Rejected = append(decision.Rejected, field)
+			}
+
+			if policy.Effect == EffectStrip && !slices.Contains(decision.Strip, field) {
+				decision.Strip = append(decision.Strip, field)
+			}
+		}
+	}
+
+	// A stripped field is never written, so there is nothing left to reject.
+	decision.Rejected = slices.DeleteFunc(decision.Rejected, func(field string) bool {
+		return slices.Contains(decision.Strip, field)
+	})
+
+	slices.Sort(decision.Rejected)
+	slices.Sort(decision.Strip)
+
+	return decision
+}
+
+func (p Policy) appliesTo(action string) bool {
+	return slices.Contains(p.Actions, action) || slices.Contains(p.Actions, "*")
+}
+
+func (p Policy) matches(attributes map[string]any) bool {
+	for _, condition := range p.Conditions {
+		if !condition.holds(attributes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (req Request) attributes() map[string]any {
+	subjectUUID := ""
+	if req.Subject.UserUUID != uuid.Nil {
+		subjectUUID = req.Subject.UserUUID.String()
+	}
+
+	resourceOrganizationID := ""
+	if req.Resource.OrganizationID != nil {
+		resourceOrganizationID = *req.Resource.OrganizationID
+	}
+
+	attributes := map[string]any{
+		"subject.uuid": subjectUUID,
+		"subject.email": req.Subject.Email,
+		"subject.level": req.Subject.Level,
+		"subject.principal_type": req.Subject.PrincipalType,
+		"subject.client_id": req.Subject.ClientID,
+		"subject.scopes": req.Subject.Scopes,
+		"subject.permissions": req.Subject.Permissions,
+		"subject.organization_id": req.Subject.OrganizationID,
+		"subject.organization_role": req.Subject.OrganizationRole,
+		"resource.uuid": req.Resource.UUID.String(),
+		"resource.email": req.Resource.Email,
+		"resource.age": req.Resource.Age,
+		"resource.level": req.Resource.Level,
+		"resource.status": req.Resource.Status,
+		"resource.organization_id": resourceOrganizationID,
+		"resource.organization_role": req.ResourceOrganizationRole,
+		"env.ip": req.Environment.IP,
+		"env.hour": req.Environment.Time.Hour(),
+		"env.weekday": req.Environment.Time.Weekday().String(),
+	}
+
+	for field, value := range req.Changes {
+		attributes["change." + field] = value
+	}
+
+	return attributes
+}
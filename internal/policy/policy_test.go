@@ -0,0 +1,296 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestEvaluateDefaultPolicies(t *testing.T) {
+	engine, err := Load("")
+	if err != nil {
+		t.Fatalf("Load default policies: %v", err)
+	}
+
+	organizationID := "org-1"
+	otherOrganizationID := "org-2"
+
+	self := uuid.New()
+	other := uuid.New()
+
+	user := func(id uuid.UUID, level int8, organization *string) models.User {
+		return models.User{UUID: id, Email: "user@example.com", Level: level, Status: 1, OrganizationID: organization}
+	}
+	member := user(other, 2, &organizationID)
+	admin := user(other, models.LevelAdmin, nil)
+
+	withPermissions := func(permissions ...string) Subject {
+		return Subject{UserUUID: self, PrincipalType: "user", Permissions: permissions}
+	}
+	inOrganization := func(role string) Subject {
+		return Subject{UserUUID: self, PrincipalType: "user", OrganizationID: organizationID, OrganizationRole: role}
+	}
+	service := func(scopes ...string) Subject {
+		return Subject{PrincipalType: "service", ClientID: "client", Scopes: scopes}
+	}
+
+	tests := []struct {
+		name 		string
+		request 	Request
+		allowed 	bool
+		strip 		[]string
+		rejected 	[]string
+	}{
+		{
+			name: "nothing granted",
+			request: Request{Subject: withPermissions(), Action: "users:read", Resource: member},
+		},
+		{
+			name: "users:read reads anyone",
+			request: Request{Subject: withPermissions("users:read"), Action: "users:read", Resource: member},
+			allowed: true,
+		},
+		{
+			name: "users:read does not write",
+			request: Request{Subject: withPermissions("users:read"), Action: "users:update", Resource: member},
+		},
+		{
+			name: "users:write updates a password",
+			request: Request{Subject: withPermissions("users:write"), Action: "users:update", Resource: member, Changes: map[string]any{"password": "secret"}},
+			allowed: true,
+		},
+		{
+			name: "self read",
+			request: Request{Subject: withPermissions(), Action: "users:read", Resource: user(self, 2, nil)},
+			allowed: true,
+		},
+		{
+			name: "self cannot delete",
+			request: Request{Subject: withPermissions(), Action: "users:delete", Resource: user(self, 2, nil)},
+		},
+		{
+			name: "self level and status are stripped",
+			request: Request{Subject: withPermissions(), Action: "users:update", Resource: user(self, 2, nil), Changes: map[string]any{"name": "Ann", "level": int8(1), "status": int8(2)}},
+			allowed: true,
+			strip: []string{"level", "status"},
+		},
+		{
+			name: "self password is rejected",
+			request: Request{Subject: withPermissions("users:write"), Action: "users:update", Resource: user(self, 2, nil), Changes: map[string]any{"password": "secret"}},
+			allowed: true,
+			rejected: []string{"password"},
+		},
+		{
+			name: "granting admin needs roles:write",
+			request: Request{Subject: withPermissions("users:write"), Action: "users:update", Resource: member, Changes: map[string]any{"level": int8(1)}},
+			allowed: true,
+			rejected: []string{"level"},
+		},
+		{
+			name: "roles:write grants admin",
+			request: Request{Subject: withPermissions("users:write", "roles:write"), Action: "users:update", Resource: member, Changes: map[string]any{"level": int8(1)}},
+			allowed: true,
+		},
+		{
+			name: "admins are protected",
+			request: Request{Subject: withPermissions("users:write", "users:delete"), Action: "users:delete", Resource: admin},
+		},
+		{
+			name: "roles:write deletes an admin",
+			request: Request{Subject: withPermissions("users:delete", "roles:write"), Action: "users:delete", Resource: admin},
+			allowed: true,
+		},
+		{
+			name: "service reads with scope",
+			request: Request{Subject: service("users:read"), Action: "users:read", Resource: member},
+			allowed: true,
+		},
+		{
+			name: "service without scope",
+			request: Request{Subject: service("openid"), Action: "users:read", Resource: member},
+		},
+		{
+			name: "service never deletes",
+			request: Request{Subject: service("users:read", "users:write"), Action: "users:delete", Resource: member},
+		},
+		{
+			name: "org admin updates a member",
+			request: Request{Subject: inOrganization("admin"), Action: "users:update", Resource: member, ResourceOrganizationRole: "member"},
+			allowed: true,
+		},
+		{
+			name: "org admin reads another tenant",
+			request: Request{Subject: inOrganization("admin"), Action: "users:read", Resource: user(other, 2, &otherOrganizationID)},
+		},
+		{
+			name: "org admin updates an owner",
+			request: Request{Subject: inOrganization("admin"), Action: "users:update", Resource: member, ResourceOrganizationRole: "owner", Changes: map[string]any{"password": "secret"}},
+		},
+		{
+			name: "org admin deletes an owner",
+			request: Request{Subject: inOrganization("admin"), Action: "users:delete", Resource: member, ResourceOrganizationRole: "owner"},
+		},
+		{
+			name: "org owner updates an owner",
+			request: Request{Subject: inOrganization("owner"), Action: "users:update", Resource: member, ResourceOrganizationRole: "owner"},
+			allowed: true,
+		},
+		{
+			name: "org support reads",
+			request: Request{Subject: inOrganization("support"), Action: "users:read", Resource: member},
+			allowed: true,
+		},
+		{
+			name: "org support cannot update",
+			request: Request{Subject: inOrganization("support"), Action: "users:update", Resource: member},
+		},
+		{
+			name: "org member reads nobody",
+			request: Request{Subject: inOrganization("member"), Action: "users:read", Resource: member},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(tt.request)
+			if decision.Allowed != tt.allowed {
+				t.Errorf("Allowed = %v (policy %q), want %v", decision.Allowed, decision.PolicyID, tt.allowed)
+			}
+
+			if !slices.Equal(decision.Strip, tt.strip) {
+				t.Errorf("Strip = %v, want %v", decision.Strip, tt.strip)
+			}
+
+			if !slices.Equal(decision.Rejected, tt.rejected) {
+				t.Errorf("Rejected = %v, want %v", decision.Rejected, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestEvaluateOrder(t *testing.T) {
+	allowAll := Policy{ID: "allow", Effect: EffectAllow, Actions: []string{"*"}}
+
+	tests := []struct {
+		name 		string
+		policies 	[]Policy
+		changes 	map[string]any
+		allowed 	bool
+		strip 		[]string
+		rejected 	[]string
+	}{
+		{
+			name: "deny by default",
+		},
+		{
+			name: "deny overrides allow",
+			policies: []Policy{allowAll, {ID: "deny", Effect: EffectDeny, Actions: []string{"users:update"}}},
+		},
+		{
+			name: "deny for another action",
+			policies: []Policy{allowAll, {ID: "deny", Effect: EffectDeny, Actions: []string{"users:delete"}}},
+			allowed: true,
+		},
+		{
+			name: "field deny only rejects the field",
+			policies: []Policy{allowAll, {ID: "deny", Effect: EffectDeny, Actions: []string{"users:update"}, Fields: []string{"level", "email"}}},
+			changes: map[string]any{"name": "Ann", "level": 1},
+			allowed: true,
+			rejected: []string{"level"},
+		},
+		{
+			name: "strip wins over a field deny",
+			policies: []Policy{
+				allowAll,
+				{ID: "deny", Effect: EffectDeny, Actions: []string{"users:update"}, Fields: []string{"level", "status"}},
+				{ID: "strip", Effect: EffectStrip, Actions: []string{"users:update"}, Fields: []string{"status"}},
+			},
+			changes: map[string]any{"level": 1, "status": 2},
+			allowed: true,
+			strip: []string{"status"},
+			rejected: []string{"level"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine(tt.policies)
+			if err != nil {
+				t.Fatalf("NewEngine error: %v", err)
+			}
+
+			decision := engine.Evaluate(Request{Action: "users:update", Changes: tt.changes})
+			if decision.Allowed != tt.allowed || !slices.Equal(decision.Strip, tt.strip) || !slices.Equal(decision.Rejected, tt.rejected) {
+				t.Errorf("Evaluate = %+v, want allowed %v, strip %v, rejected %v", decision, tt.allowed, tt.strip, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name 	string
+		policy 	Policy
+	}{
+		{"missing id", Policy{Effect: EffectAllow, Actions: []string{"users:read"}}},
+		{"no actions", Policy{ID: "p", Effect: EffectAllow}},
+		{"allow with fields", Policy{ID: "p", Effect: EffectAllow, Actions: []string{"users:update"}, Fields: []string{"level"}}},
+		{"strip without fields", Policy{ID: "p", Effect: EffectStrip, Actions: []string{"users:update"}}},
+		{"unknown effect", Policy{ID: "p", Effect: "maybe", Actions: []string{"users:read"}}},
+		{"unknown operator", Policy{ID: "p", Effect: EffectAllow, Actions: []string{"users:read"}, Conditions: []Condition{{Attribute: "subject.uuid", Operator: "like"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine([]Policy{tt.policy}); err == nil {
+				t.Errorf("NewEngine(%+v) succeeded, want an error", tt.policy)
+			}
+		})
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	attributes := map[string]any{
+		"subject.uuid": "a",
+		"resource.uuid": "a",
+		"resource.level": int8(2),
+		"resource.age": int16(30),
+		"subject.permissions": []string{"users:read"},
+		"env.ip": "10.1.2.3",
+		"env.hour": time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC).Hour(),
+	}
+
+	tests := []struct {
+		name 		string
+		condition 	Condition
+		want 		bool
+	}{
+		{"eq ref", Condition{Attribute: "subject.uuid", Operator: "eq", Ref: "resource.uuid"}, true},
+		{"eq number from JSON", Condition{Attribute: "resource.level", Operator: "eq", Value: float64(2)}, true},
+		{"ne", Condition{Attribute: "resource.level", Operator: "ne", Value: float64(1)}, true},
+		{"in", Condition{Attribute: "resource.level", Operator: "in", Value: []any{float64(1), float64(2)}}, true},
+		{"not_in", Condition{Attribute: "resource.level", Operator: "not_in", Value: []any{float64(1)}}, true},
+		{"contains", Condition{Attribute: "subject.permissions", Operator: "contains", Value: "users:read"}, true},
+		{"not_contains", Condition{Attribute: "subject.permissions", Operator: "not_contains", Value: "users:read"}, false},
+		{"gt", Condition{Attribute: "resource.age", Operator: "gt", Value: float64(18)}, true},
+		{"gte equal", Condition{Attribute: "env.hour", Operator: "gte", Value: float64(9)}, true},
+		{"lt", Condition{Attribute: "env.hour", Operator: "lt", Value: float64(9)}, false},
+		{"lte", Condition{Attribute: "resource.age", Operator: "lte", Value: float64(30)}, true},
+		{"compare text", Condition{Attribute: "subject.uuid", Operator: "gt", Value: float64(1)}, false},
+		{"cidr", Condition{Attribute: "env.ip", Operator: "cidr", Value: []any{"192.168.0.0/16", "10.0.0.0/8"}}, true},
+		{"cidr outside", Condition{Attribute: "env.ip", Operator: "cidr", Value: "192.168.0.0/16"}, false},
+		{"missing attribute", Condition{Attribute: "resource.unknown", Operator: "ne", Value: "x"}, false},
+		{"missing ref", Condition{Attribute: "subject.uuid", Operator: "eq", Ref: "resource.unknown"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.holds(attributes); got != tt.want {
+				t.Errorf("holds(%+v) = %v, want %v", tt.condition, got, tt.want)
+			}
+		})
+	}
+}
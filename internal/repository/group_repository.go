@@ -0,0 +1,396 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlGroupRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlGroupRepository(DB *goqu.Database) GroupRepository {
+	return &SqlGroupRepository{
+		db: DB,
+	}
+}
+
+func (gr *SqlGroupRepository) Create(group models.Group) (models.Group, error) {
+	result, err := gr.db.Insert(goqu.T("groups")).Rows(group).Executor().Exec()
+	if err != nil {
+		return models.Group{}, fmt.Errorf("faile insert group:%v", err)
+	}
+
+	group.ID, err = result.LastInsertId()
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	return group, nil
+}
+
+func (gr *SqlGroupRepository) FindByID(id int64) (models.Group, bool, error) {
+	ds := gr.db.From(goqu.T("groups")).Where(
+		goqu.C("id").Eq(id),
+	).Limit(1)
+
+	var group models.Group
+	found, err := ds.ScanStruct(&group)
+	if err != nil {
+		return models.Group{}, false, fmt.Errorf("faile get group:%v", err)
+	}
+
+	return group, found, nil
+}
+
+func (gr *SqlGroupRepository) FindByName(name string) (models.Group, bool, error) {
+	ds := gr.db.From(goqu.T("groups")).Where(
+		goqu.C("name").Eq(name),
+	).Limit(1)
+
+	var group models.Group
+	found, err := ds.ScanStruct(&group)
+	if err != nil {
+		return models.Group{}, false, fmt.Errorf("faile get group:%v", err)
+	}
+
+	return group, found, nil
+}
+
+func (gr *SqlGroupRepository) FindAll() ([]models.Group, error) {
+	ds := gr.db.From(goqu.T("groups")).Order(goqu.C("name").Asc())
+
+	var groups []models.Group
+	if err := ds.ScanStructs(&groups); err != nil {
+		return nil, fmt.Errorf("faile get groups:%v", err)
+	}
+
+	return groups, nil
+}
+
+func (gr *SqlGroupRepository) FindByIDs(ids []int64) ([]models.Group, error) {
+	if len(ids) == 0 {
+		return []models.Group{}, nil
+	}
+
+	ds := gr.db.From(goqu.T("groups")).Where(
+		goqu.C("id").In(ids),
+	).Order(goqu.C("name").Asc())
+
+	var groups []models.Group
+	if err := ds.ScanStructs(&groups); err != nil {
+		return nil, fmt.Errorf("faile get groups:%v", err)
+	}
+
+	return groups, nil
+}
+
+func (gr *SqlGroupRepository) Update(group models.Group) (bool, error) {
+	result, err := gr.db.Update(goqu.T("groups")).Set(goqu.Record{
+		"name": group.Name,
+		"description": group.Description,
+		"updated_at": time.Now(),
+	}).Where(
+		goqu.C("id").Eq(group.ID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update group:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Delete removes the group with its memberships, nesting and grants. Child
+// groups are kept, they only lose this parent.
+func (gr *SqlGroupRepository) Delete(id int64) (bool, error) {
+	var deleted bool
+	err := gr.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Delete(goqu.T("group_users")).Where(
+			goqu.C("group_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete group users:%v", err)
+		}
+
+		if _, err := tx.Delete(goqu.T("group_children")).Where(
+			goqu.Or(
+				goqu.C("group_id").Eq(id),
+				goqu.C("child_group_id").Eq(id),
+			),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete group children:%v", err)
+		}
+
+		if _, err := tx.Delete(goqu.T("group_roles")).Where(
+			goqu.C("group_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete group roles:%v", err)
+		}
+
+		if _, err := tx.Delete(goqu.T("group_permissions")).Where(
+			goqu.C("group_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete group permissions:%v", err)
+		}
+
+		result, err := tx.Delete(goqu.T("groups")).Where(
+			goqu.C("id").Eq(id),
+		).Executor().Exec()
+		if err != nil {
+			return fmt.Errorf("faile delete group:%v", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		deleted = affected > 0
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (gr *SqlGroupRepository) FindUserUUIDs(groupIDs []int64) ([]uuid.UUID, error) {
+	if len(groupIDs) == 0 {
+		return []uuid.UUID{}, nil
+	}
+
+	ds := gr.db.From(goqu.T("group_users")).Where(
+		goqu.C("group_id").In(groupIDs),
+	).Select(goqu.C("user_uuid")).Distinct()
+
+	var userUUIDs []uuid.UUID
+	if err := ds.ScanVals(&userUUIDs); err != nil {
+		return nil, fmt.Errorf("faile get group users:%v", err)
+	}
+
+	return userUUIDs, nil
+}
+
+func (gr *SqlGroupRepository) FindUserGroupIDs(userUUID uuid.UUID) ([]int64, error) {
+	ds := gr.db.From(goqu.T("group_users")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Select(goqu.C("group_id"))
+
+	var groupIDs []int64
+	if err := ds.ScanVals(&groupIDs); err != nil {
+		return nil, fmt.Errorf("faile get user groups:%v", err)
+	}
+
+	return groupIDs, nil
+}
+
+func (gr *SqlGroupRepository) AddUser(groupID int64, userUUID uuid.UUID) error {
+	_, err := gr.db.Insert(goqu.T("group_users")).Rows(models.GroupUser{
+		GroupID: groupID,
+		UserUUID: userUUID,
+		CreatedAt: time.Now(),
+	}).OnConflict(goqu.DoNothing()).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile add group user:%v", err)
+	}
+
+	return nil
+}
+
+func (gr *SqlGroupRepository) RemoveUser(groupID int64, userUUID uuid.UUID) (bool, error) {
+	result, err := gr.db.Delete(goqu.T("group_users")).Where(
+		goqu.C("group_id").Eq(groupID),
+		goqu.C("user_uuid").Eq(userUUID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile remove group user:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FindEdges returns every parent/child link, hierarchies are resolved in memory.
+func (gr *SqlGroupRepository) FindEdges() ([]models.GroupChild, error) {
+	var edges []models.GroupChild
+	if err := gr.db.From(goqu.T("group_children")).ScanStructs(&edges); err != nil {
+		return nil, fmt.Errorf("faile get group children:%v", err)
+	}
+
+	return edges, nil
+}
+
+func (gr *SqlGroupRepository) AddChild(groupID, childGroupID int64) error {
+	_, err := gr.db.Insert(goqu.T("group_children")).Rows(models.GroupChild{
+		GroupID: groupID,
+		ChildGroupID: childGroupID,
+		CreatedAt: time.Now(),
+	}).OnConflict(goqu.DoNothing()).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile add child group:%v", err)
+	}
+
+	return nil
+}
+
+func (gr *SqlGroupRepository) RemoveChild(groupID, childGroupID int64) (bool, error) {
+	result, err := gr.db.Delete(goqu.T("group_children")).Where(
+		goqu.C("group_id").Eq(groupID),
+		goqu.C("child_group_id").Eq(childGroupID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile remove child group:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (gr *SqlGroupRepository) FindRoles(groupID int64) ([]models.Role, error) {
+	ds := gr.db.From(goqu.T("roles").As("r")).
+	Join(goqu.T("group_roles").As("gr"), goqu.On(goqu.I("gr.role_id").Eq(goqu.I("r.id")))).
+	Where(
+		goqu.I("gr.group_id").Eq(groupID),
+	).
+	Select(goqu.I("r.id"), goqu.I("r.name"), goqu.I("r.description"), goqu.I("r.created_at"), goqu.I("r.updated_at")).
+	Order(goqu.I("r.name").Asc())
+
+	var roles []models.Role
+	if err := ds.ScanStructs(&roles); err != nil {
+		return nil, fmt.Errorf("faile get group roles:%v", err)
+	}
+
+	return roles, nil
+}
+
+func (gr *SqlGroupRepository) FindGroupIDsByRole(roleID int64) ([]int64, error) {
+	ds := gr.db.From(goqu.T("group_roles")).Where(
+		goqu.C("role_id").Eq(roleID),
+	).Select(goqu.C("group_id"))
+
+	var groupIDs []int64
+	if err := ds.ScanVals(&groupIDs); err != nil {
+		return nil, fmt.Errorf("faile get role groups:%v", err)
+	}
+
+	return groupIDs, nil
+}
+
+func (gr *SqlGroupRepository) AssignRole(groupID, roleID int64) error {
+	_, err := gr.db.Insert(goqu.T("group_roles")).Rows(models.GroupRole{
+		GroupID: groupID,
+		RoleID: roleID,
+		CreatedAt: time.Now(),
+	}).OnConflict(goqu.DoNothing()).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile assign group role:%v", err)
+	}
+
+	return nil
+}
+
+func (gr *SqlGroupRepository) RemoveRole(groupID, roleID int64) (bool, error) {
+	result, err := gr.db.Delete(goqu.T("group_roles")).Where(
+		goqu.C("group_id").Eq(groupID),
+		goqu.C("role_id").Eq(roleID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile remove group role:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (gr *SqlGroupRepository) FindPermissions(groupID int64) ([]models.Permission, error) {
+	ds := gr.db.From(goqu.T("permissions").As("p")).
+	Join(goqu.T("group_permissions").As("gp"), goqu.On(goqu.I("gp.permission_id").Eq(goqu.I("p.id")))).
+	Where(
+		goqu.I("gp.group_id").Eq(groupID),
+	).
+	Select(goqu.I("p.id"), goqu.I("p.name"), goqu.I("p.description")).
+	Order(goqu.I("p.name").Asc())
+
+	var permissions []models.Permission
+	if err := ds.ScanStructs(&permissions); err != nil {
+		return nil, fmt.Errorf("faile get group permissions:%v", err)
+	}
+
+	return permissions, nil
+}
+
+func (gr *SqlGroupRepository) ReplacePermissions(groupID int64, permissionIDs []int64) error {
+	return gr.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Delete(goqu.T("group_permissions")).Where(
+			goqu.C("group_id").Eq(groupID),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete group permissions:%v", err)
+		}
+
+		if len(permissionIDs) == 0 {
+			return nil
+		}
+
+		rows := make([]models.GroupPermission, 0, len(permissionIDs))
+		for _, permissionID := range permissionIDs {
+			rows = append(rows, models.GroupPermission{
+				GroupID: groupID,
+				PermissionID: permissionID,
+			})
+		}
+
+		if _, err := tx.Insert(goqu.T("group_permissions")).Rows(rows).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile insert group permissions:%v", err)
+		}
+
+		return nil
+	})
+}
+
+// FindPermissionNames returns what the groups grant, directly and through
+// their roles.
+func (gr *SqlGroupRepository) FindPermissionNames(groupIDs []int64) ([]string, error) {
+	if len(groupIDs) == 0 {
+		return []string{}, nil
+	}
+
+	direct := gr.db.From(goqu.T("permissions").As("p")).
+	Join(goqu.T("group_permissions").As("gp"), goqu.On(goqu.I("gp.permission_id").Eq(goqu.I("p.id")))).
+	Where(
+		goqu.I("gp.group_id").In(groupIDs),
+	).
+	Select(goqu.I("p.name"))
+
+	throughRoles := gr.db.From(goqu.T("permissions").As("p")).
+	Join(goqu.T("role_permissions").As("rp"), goqu.On(goqu.I("rp.permission_id").Eq(goqu.I("p.id")))).
+	Join(goqu.T("group_roles").As("gr"), goqu.On(goqu.I("gr.role_id").Eq(goqu.I("rp.role_id")))).
+	Where(
+		goqu.I("gr.group_id").In(groupIDs),
+	).
+	Select(goqu.I("p.name"))
+
+	var names []string
+	if err := direct.Union(throughRoles).ScanVals(&names); err != nil {
+		return nil, fmt.Errorf("faile get group permissions:%v", err)
+	}
+
+	return names, nil
+}
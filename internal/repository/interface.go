@@ -1,16 +1,156 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/dangLuan01/user-manager/internal/models"
 	"github.com/google/uuid"
 )
 
 type UserRepository interface {
 	FindAll() ([]models.User, error)
+	FindPage(page UserPage) ([]models.User, error)
+	Iterate(page UserPage) (UserIterator, error)
+	Count(filter UserFilter) (int64, error)
 	FindBYUUID(uuid uuid.UUID) (models.User, error)
+	FindByUUIDs(uuids []uuid.UUID) ([]models.User, error)
 	Create(user models.User) error
-	Update(uuid uuid.UUID, user models.User) error
-	Delete(uuid uuid.UUID) error
+	Update(uuid uuid.UUID, user models.User) (bool, error)
+	UpdateFields(uuid uuid.UUID, version int64, fields map[string]any) (bool, error)
+	Delete(uuid uuid.UUID, version int64) (bool, error)
+	Restore(uuid uuid.UUID) error
+	Purge(deletedBefore time.Time, limit uint) (int, error)
 	FindByEmail(email string) (models.User, error)
+	FindByEmails(emails []string) ([]models.User, error)
+	FindPassword(uuid uuid.UUID) (string, error)
 	UpdatePassword(uuid uuid.UUID, password string) error
-}
\ No newline at end of file
+	TouchLastLogin(uuid uuid.UUID) error
+	ForOrganization(organizationID string) UserRepository
+	OnlyDeleted() UserRepository
+}
+
+// UserIterator yields the rows of a query while they are read from the
+// database. Next reports false once the rows run out or fail, Err tells which.
+type UserIterator interface {
+	Next() bool
+	User() (models.User, error)
+	Err() error
+	Close() error
+}
+
+type TwoFactorRepository interface {
+	FindByUserUUID(userUUID uuid.UUID) (models.TwoFactor, bool, error)
+	Save(twoFactor models.TwoFactor) error
+	Enable(userUUID uuid.UUID) error
+	Delete(userUUID uuid.UUID) error
+}
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(userUUID uuid.UUID, codes []models.RecoveryCode) error
+	FindUnusedByHash(userUUID uuid.UUID, codeHash string) (models.RecoveryCode, bool, error)
+	MarkUsed(id int64, ip string) (bool, error)
+	CountUnused(userUUID uuid.UUID) (int64, error)
+	DeleteByUser(userUUID uuid.UUID) error
+}
+
+type PasskeyRepository interface {
+	Create(credential models.PasskeyCredential) error
+	FindByCredentialID(credentialID string) (models.PasskeyCredential, bool, error)
+	FindByUser(userUUID uuid.UUID) ([]models.PasskeyCredential, error)
+	UpdateSignCount(id int64, signCount int64) error
+	MarkCloneWarning(id int64) error
+	Delete(userUUID uuid.UUID, id int64) (bool, error)
+}
+
+type OAuthClientRepository interface {
+	Create(client models.OAuthClient) error
+	FindByClientID(clientID string) (models.OAuthClient, bool, error)
+	FindAll() ([]models.OAuthClient, error)
+	Update(client models.OAuthClient) (bool, error)
+	Delete(clientID string) (bool, error)
+}
+
+type OAuthConsentRepository interface {
+	Find(userUUID uuid.UUID, clientID string) (models.OAuthConsent, bool, error)
+	FindByUser(userUUID uuid.UUID) ([]models.OAuthConsent, error)
+	Save(consent models.OAuthConsent) error
+	Delete(userUUID uuid.UUID, clientID string) (bool, error)
+	DeleteByClient(clientID string) error
+}
+
+type ServiceAccountRepository interface {
+	Create(account models.ServiceAccount) error
+	FindByClientID(clientID string) (models.ServiceAccount, bool, error)
+	FindAll() ([]models.ServiceAccount, error)
+	Update(account models.ServiceAccount) (bool, error)
+	SetEnabled(clientID string, enabled bool) (bool, error)
+	UpdateSecret(clientID, secretHash string, previousHash *string, previousExpiresAt *time.Time) (bool, error)
+	TouchLastUsed(clientID string) error
+	Delete(clientID string) (bool, error)
+}
+
+type RBACRepository interface {
+	CreateRole(role models.Role) (models.Role, error)
+	FindRoleByID(id int64) (models.Role, bool, error)
+	FindAllRoles() ([]models.Role, error)
+	UpdateRole(role models.Role) (bool, error)
+	DeleteRole(id int64) (bool, error)
+	FindAllPermissions() ([]models.Permission, error)
+	SeedPermissions(permissions []models.Permission) error
+	FindPermissionsByNames(names []string) ([]models.Permission, error)
+	FindRolePermissions(roleID int64) ([]models.Permission, error)
+	ReplaceRolePermissions(roleID int64, permissionIDs []int64) error
+	FindUserRoles(userUUID uuid.UUID) ([]models.Role, error)
+	FindUserPermissionNames(userUUID uuid.UUID) ([]string, error)
+	FindUserUUIDsByRole(roleID int64) ([]uuid.UUID, error)
+	AssignUserRole(userUUID uuid.UUID, roleID int64) error
+	RemoveUserRole(userUUID uuid.UUID, roleID int64) (bool, error)
+}
+
+type OrganizationRepository interface {
+	Create(organization models.Organization, owner models.OrganizationMember) error
+	FindByID(id string) (models.Organization, bool, error)
+	FindByUser(userUUID uuid.UUID) ([]models.Organization, error)
+	Update(organization models.Organization) (bool, error)
+	Delete(id string) (bool, error)
+	FindMember(organizationID string, userUUID uuid.UUID) (models.OrganizationMember, bool, error)
+	FindMembers(organizationID string) ([]models.OrganizationMember, error)
+	SaveMember(member models.OrganizationMember) error
+	RemoveMember(organizationID string, userUUID uuid.UUID) (bool, error)
+	CountMembersWithRole(organizationID, role string) (int64, error)
+}
+
+type InvitationRepository interface {
+	Create(invitation models.Invitation) error
+	FindByID(id string) (models.Invitation, bool, error)
+	FindByTokenHash(tokenHash string) (models.Invitation, bool, error)
+	FindPending(email string, organizationID *string) (models.Invitation, bool, error)
+	FindAll(organizationID string) ([]models.Invitation, error)
+	Renew(id, tokenHash string, expiresAt time.Time) (bool, error)
+	Revoke(id string) (bool, error)
+	Accept(invitation models.Invitation, user models.User) (bool, error)
+}
+
+type GroupRepository interface {
+	Create(group models.Group) (models.Group, error)
+	FindByID(id int64) (models.Group, bool, error)
+	FindByName(name string) (models.Group, bool, error)
+	FindAll() ([]models.Group, error)
+	FindByIDs(ids []int64) ([]models.Group, error)
+	Update(group models.Group) (bool, error)
+	Delete(id int64) (bool, error)
+	FindUserUUIDs(groupIDs []int64) ([]uuid.UUID, error)
+	FindUserGroupIDs(userUUID uuid.UUID) ([]int64, error)
+	AddUser(groupID int64, userUUID uuid.UUID) error
+	RemoveUser(groupID int64, userUUID uuid.UUID) (bool, error)
+	FindEdges() ([]models.GroupChild, error)
+	AddChild(groupID, childGroupID int64) error
+	RemoveChild(groupID, childGroupID int64) (bool, error)
+	FindRoles(groupID int64) ([]models.Role, error)
+	FindGroupIDsByRole(roleID int64) ([]int64, error)
+	AssignRole(groupID, roleID int64) error
+	RemoveRole(groupID, roleID int64) (bool, error)
+	FindPermissions(groupID int64) ([]models.Permission, error)
+	ReplacePermissions(groupID int64, permissionIDs []int64) error
+	FindPermissionNames(groupIDs []int64) ([]string, error)
+}
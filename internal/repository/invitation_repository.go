@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+type SqlInvitationRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlInvitationRepository(DB *goqu.Database) InvitationRepository {
+	return &SqlInvitationRepository{
+		db: DB,
+	}
+}
+
+func pendingInvitation() []exp.Expression {
+	return []exp.Expression{
+		goqu.C("accepted_at").IsNull(),
+		goqu.C("revoked_at").IsNull(),
+		goqu.C("expires_at").Gt(time.Now()),
+	}
+}
+
+func (ir *SqlInvitationRepository) Create(invitation models.Invitation) error {
+	if _, err := ir.db.Insert(goqu.T("invitations")).Rows(invitation).Executor().Exec(); err != nil {
+		return fmt.Errorf("faile insert invitation:%v", err)
+	}
+
+	return nil
+}
+
+func (ir *SqlInvitationRepository) FindByID(id string) (models.Invitation, bool, error) {
+	return ir.findOne(goqu.C("id").Eq(id))
+}
+
+func (ir *SqlInvitationRepository) FindByTokenHash(tokenHash string) (models.Invitation, bool, error) {
+	return ir.findOne(goqu.C("token_hash").Eq(tokenHash))
+}
+
+// FindPending returns the open invitation for email into organizationID, nil
+// meaning no organization.
+func (ir *SqlInvitationRepository) FindPending(email string, organizationID *string) (models.Invitation, bool, error) {
+	conditions := append(pendingInvitation(), goqu.C("email").Eq(email))
+	if organizationID == nil {
+		conditions = append(conditions, goqu.C("organization_id").IsNull())
+	} else {
+		conditions = append(conditions, goqu.C("organization_id").Eq(*organizationID))
+	}
+
+	return ir.findOne(conditions...)
+}
+
+func (ir *SqlInvitationRepository) findOne(conditions ...exp.Expression) (models.Invitation, bool, error) {
+	ds := ir.db.From(goqu.T("invitations")).Where(conditions...).Limit(1)
+
+	var invitation models.Invitation
+	found, err := ds.ScanStruct(&invitation)
+	if err != nil {
+		return models.Invitation{}, false, fmt.Errorf("faile get invitation:%v", err)
+	}
+
+	return invitation, found, nil
+}
+
+// FindAll lists invitations newest first, limited to one organization unless
+// organizationID is empty.
+func (ir *SqlInvitationRepository) FindAll(organizationID string) ([]models.Invitation, error) {
+	ds := ir.db.From(goqu.T("invitations")).Order(goqu.C("created_at").Desc())
+	if organizationID != "" {
+		ds = ds.Where(goqu.C("organization_id").Eq(organizationID))
+	}
+
+	var invitations []models.Invitation
+	if err := ds.ScanStructs(&invitations); err != nil {
+		return nil, fmt.Errorf("faile get invitations:%v", err)
+	}
+
+	return invitations, nil
+}
+
+// Renew replaces the token of an invitation that has not been used or revoked,
+// which also revives an expired one.
+func (ir *SqlInvitationRepository) Renew(id, tokenHash string, expiresAt time.Time) (bool, error) {
+	result, err := ir.db.Update(goqu.T("invitations")).Set(goqu.Record{
+		"token_hash": tokenHash,
+		"expires_at": expiresAt,
+		"updated_at": time.Now(),
+	}).Where(
+		goqu.C("id").Eq(id),
+		goqu.C("accepted_at").IsNull(),
+		goqu.C("revoked_at").IsNull(),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile renew invitation:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (ir *SqlInvitationRepository) Revoke(id string) (bool, error) {
+	result, err := ir.db.Update(goqu.T("invitations")).Set(goqu.Record{
+		"revoked_at": time.Now(),
+		"updated_at": time.Now(),
+	}).Where(
+		goqu.C("id").Eq(id),
+		goqu.C("accepted_at").IsNull(),
+		goqu.C("revoked_at").IsNull(),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile revoke invitation:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Accept claims the invitation and creates the user with their membership and
+// role in one transaction. It reports false when the invitation was no longer
+// pending, so a token can only ever be used once.
+func (ir *SqlInvitationRepository) Accept(invitation models.Invitation, user models.User) (bool, error) {
+	var accepted bool
+	err := ir.db.WithTx(func(tx *goqu.TxDatabase) error {
+		result, err := tx.Update(goqu.T("invitations")).Set(goqu.Record{
+			"accepted_at": time.Now(),
+			"updated_at": time.Now(),
+		}).Where(
+			append(pendingInvitation(), goqu.C("id").Eq(invitation.ID))...,
+		).Executor().Exec()
+		if err != nil {
+			return fmt.Errorf("faile accept invitation:%v", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		user.PasswordChangedAt = &now
+		// Accepting the emailed link proves the address.
+		user.EmailVerifiedAt = &now
+		user.Version = 1
+		if _, err := tx.Insert(goqu.T("users")).Rows(user).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile insert rows user:%v", err)
+		}
+
+		if invitation.OrganizationID != nil {
+			if _, err := tx.Insert(goqu.T("organization_members")).Rows(models.OrganizationMember{
+				OrganizationID: *invitation.OrganizationID,
+				UserUUID: user.UUID,
+				Role: invitation.OrganizationRole,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}).Executor().Exec(); err != nil {
+				return fmt.Errorf("faile insert organization member:%v", err)
+			}
+		}
+
+		if invitation.RoleID != nil {
+			if _, err := tx.Insert(goqu.T("user_roles")).Rows(models.UserRole{
+				UserUUID: user.UUID,
+				RoleID: *invitation.RoleID,
+				CreatedAt: time.Now(),
+			}).Executor().Exec(); err != nil {
+				return fmt.Errorf("faile assign user role:%v", err)
+			}
+		}
+
+		accepted = true
+		return nil
+	})
+
+	return accepted, err
+}
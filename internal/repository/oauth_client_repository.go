@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+)
+
+type SqlOAuthClientRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlOAuthClientRepository(DB *goqu.Database) OAuthClientRepository {
+	return &SqlOAuthClientRepository{
+		db: DB,
+	}
+}
+
+func (cr *SqlOAuthClientRepository) Create(client models.OAuthClient) error {
+	if _, err := cr.db.Insert(goqu.T("oauth_clients")).Rows(client).Executor().Exec(); err != nil {
+		return fmt.Errorf("faile insert oauth client:%v", err)
+	}
+
+	return nil
+}
+
+func (cr *SqlOAuthClientRepository) FindByClientID(clientID string) (models.OAuthClient, bool, error) {
+	ds := cr.db.From(goqu.T("oauth_clients")).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Limit(1)
+
+	var client models.OAuthClient
+	found, err := ds.ScanStruct(&client)
+	if err != nil {
+		return models.OAuthClient{}, false, fmt.Errorf("faile get oauth client:%v", err)
+	}
+
+	return client, found, nil
+}
+
+func (cr *SqlOAuthClientRepository) FindAll() ([]models.OAuthClient, error) {
+	ds := cr.db.From(goqu.T("oauth_clients")).Order(goqu.C("created_at").Asc())
+
+	var clients []models.OAuthClient
+	if err := ds.ScanStructs(&clients); err != nil {
+		return nil, fmt.Errorf("faile get oauth clients:%v", err)
+	}
+
+	return clients, nil
+}
+
+func (cr *SqlOAuthClientRepository) Update(client models.OAuthClient) (bool, error) {
+	result, err := cr.db.Update(goqu.T("oauth_clients")).Set(goqu.Record{
+		"name": client.Name,
+		"redirect_uris": client.RedirectURIs,
+		"scopes": client.Scopes,
+		"updated_at": time.Now(),
+	}).Where(
+		goqu.C("client_id").Eq(client.ClientID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update oauth client:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (cr *SqlOAuthClientRepository) Delete(clientID string) (bool, error) {
+	result, err := cr.db.Delete(goqu.T("oauth_clients")).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile delete oauth client:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
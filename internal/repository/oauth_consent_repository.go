@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlOAuthConsentRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlOAuthConsentRepository(DB *goqu.Database) OAuthConsentRepository {
+	return &SqlOAuthConsentRepository{
+		db: DB,
+	}
+}
+
+func (cr *SqlOAuthConsentRepository) Find(userUUID uuid.UUID, clientID string) (models.OAuthConsent, bool, error) {
+	ds := cr.db.From(goqu.T("oauth_consents")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+		goqu.C("client_id").Eq(clientID),
+	).Limit(1)
+
+	var consent models.OAuthConsent
+	found, err := ds.ScanStruct(&consent)
+	if err != nil {
+		return models.OAuthConsent{}, false, fmt.Errorf("faile get oauth consent:%v", err)
+	}
+
+	return consent, found, nil
+}
+
+func (cr *SqlOAuthConsentRepository) FindByUser(userUUID uuid.UUID) ([]models.OAuthConsent, error) {
+	ds := cr.db.From(goqu.T("oauth_consents")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Order(goqu.C("created_at").Asc())
+
+	var consents []models.OAuthConsent
+	if err := ds.ScanStructs(&consents); err != nil {
+		return nil, fmt.Errorf("faile get oauth consents:%v", err)
+	}
+
+	return consents, nil
+}
+
+func (cr *SqlOAuthConsentRepository) Save(consent models.OAuthConsent) error {
+	_, err := cr.db.Insert(goqu.T("oauth_consents")).Rows(consent).
+	OnConflict(
+		goqu.DoUpdate("user_uuid, client_id", goqu.Record{
+			"scopes": consent.Scopes,
+			"updated_at": time.Now(),
+		}),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile save oauth consent:%v", err)
+	}
+
+	return nil
+}
+
+func (cr *SqlOAuthConsentRepository) Delete(userUUID uuid.UUID, clientID string) (bool, error) {
+	result, err := cr.db.Delete(goqu.T("oauth_consents")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+		goqu.C("client_id").Eq(clientID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile delete oauth consent:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (cr *SqlOAuthConsentRepository) DeleteByClient(clientID string) error {
+	_, err := cr.db.Delete(goqu.T("oauth_consents")).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile delete oauth consents:%v", err)
+	}
+
+	return nil
+}
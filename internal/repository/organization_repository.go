@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlOrganizationRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlOrganizationRepository(DB *goqu.Database) OrganizationRepository {
+	return &SqlOrganizationRepository{
+		db: DB,
+	}
+}
+
+// Create stores the organization together with its first owner.
+func (or *SqlOrganizationRepository) Create(organization models.Organization, owner models.OrganizationMember) error {
+	return or.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Insert(goqu.T("organizations")).Rows(organization).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile insert organization:%v", err)
+		}
+
+		if _, err := tx.Insert(goqu.T("organization_members")).Rows(owner).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile insert organization owner:%v", err)
+		}
+
+		return nil
+	})
+}
+
+func (or *SqlOrganizationRepository) FindByID(id string) (models.Organization, bool, error) {
+	ds := or.db.From(goqu.T("organizations")).Where(
+		goqu.C("id").Eq(id),
+	).Limit(1)
+
+	var organization models.Organization
+	found, err := ds.ScanStruct(&organization)
+	if err != nil {
+		return models.Organization{}, false, fmt.Errorf("faile get organization:%v", err)
+	}
+
+	return organization, found, nil
+}
+
+func (or *SqlOrganizationRepository) FindByUser(userUUID uuid.UUID) ([]models.Organization, error) {
+	ds := or.db.From(goqu.T("organizations").As("o")).
+	Join(goqu.T("organization_members").As("m"), goqu.On(goqu.I("m.organization_id").Eq(goqu.I("o.id")))).
+	Where(
+		goqu.I("m.user_uuid").Eq(userUUID),
+	).
+	Select(goqu.I("o.id"), goqu.I("o.name"), goqu.I("o.slug"), goqu.I("o.created_at"), goqu.I("o.updated_at")).
+	Order(goqu.I("o.name").Asc())
+
+	var organizations []models.Organization
+	if err := ds.ScanStructs(&organizations); err != nil {
+		return nil, fmt.Errorf("faile get organizations:%v", err)
+	}
+
+	return organizations, nil
+}
+
+func (or *SqlOrganizationRepository) Update(organization models.Organization) (bool, error) {
+	result, err := or.db.Update(goqu.T("organizations")).Set(goqu.Record{
+		"name": organization.Name,
+		"slug": organization.Slug,
+		"updated_at": time.Now(),
+	}).Where(
+		goqu.C("id").Eq(organization.ID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update organization:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (or *SqlOrganizationRepository) Delete(id string) (bool, error) {
+	var deleted bool
+	err := or.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Delete(goqu.T("organization_members")).Where(
+			goqu.C("organization_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete organization members:%v", err)
+		}
+
+		result, err := tx.Delete(goqu.T("organizations")).Where(
+			goqu.C("id").Eq(id),
+		).Executor().Exec()
+		if err != nil {
+			return fmt.Errorf("faile delete organization:%v", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		deleted = affected > 0
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (or *SqlOrganizationRepository) FindMember(organizationID string, userUUID uuid.UUID) (models.OrganizationMember, bool, error) {
+	ds := or.db.From(goqu.T("organization_members")).Where(
+		goqu.C("organization_id").Eq(organizationID),
+		goqu.C("user_uuid").Eq(userUUID),
+	).Limit(1)
+
+	var member models.OrganizationMember
+	found, err := ds.ScanStruct(&member)
+	if err != nil {
+		return models.OrganizationMember{}, false, fmt.Errorf("faile get organization member:%v", err)
+	}
+
+	return member, found, nil
+}
+
+func (or *SqlOrganizationRepository) FindMembers(organizationID string) ([]models.OrganizationMember, error) {
+	ds := or.db.From(goqu.T("organization_members")).Where(
+		goqu.C("organization_id").Eq(organizationID),
+	).Order(goqu.C("created_at").Asc())
+
+	var members []models.OrganizationMember
+	if err := ds.ScanStructs(&members); err != nil {
+		return nil, fmt.Errorf("faile get organization members:%v", err)
+	}
+
+	return members, nil
+}
+
+func (or *SqlOrganizationRepository) SaveMember(member models.OrganizationMember) error {
+	_, err := or.db.Insert(goqu.T("organization_members")).Rows(member).
+	OnConflict(
+		goqu.DoUpdate("organization_id, user_uuid", goqu.Record{
+			"role": member.Role,
+			"updated_at": time.Now(),
+		}),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile save organization member:%v", err)
+	}
+
+	return nil
+}
+
+func (or *SqlOrganizationRepository) RemoveMember(organizationID string, userUUID uuid.UUID) (bool, error) {
+	result, err := or.db.Delete(goqu.T("organization_members")).Where(
+		goqu.C("organization_id").Eq(organizationID),
+		goqu.C("user_uuid").Eq(userUUID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile remove organization member:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (or *SqlOrganizationRepository) CountMembersWithRole(organizationID, role string) (int64, error) {
+	count, err := or.db.From(goqu.T("organization_members")).Where(
+		goqu.C("organization_id").Eq(organizationID),
+		goqu.C("role").Eq(role),
+	).Count()
+	if err != nil {
+		return 0, fmt.Errorf("faile count organization members:%v", err)
+	}
+
+	return count, nil
+}
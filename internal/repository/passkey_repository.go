@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlPasskeyRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlPasskeyRepository(DB *goqu.Database) PasskeyRepository {
+	return &SqlPasskeyRepository{
+		db: DB,
+	}
+}
+
+func (pr *SqlPasskeyRepository) Create(credential models.PasskeyCredential) error {
+	if _, err := pr.db.Insert(goqu.T("webauthn_credentials")).Rows(credential).Executor().Exec(); err != nil {
+		return fmt.Errorf("faile insert passkey:%v", err)
+	}
+
+	return nil
+}
+
+func (pr *SqlPasskeyRepository) FindByCredentialID(credentialID string) (models.PasskeyCredential, bool, error) {
+	ds := pr.db.From(goqu.T("webauthn_credentials")).Where(
+		goqu.C("credential_id").Eq(credentialID),
+	).Limit(1)
+
+	var credential models.PasskeyCredential
+	found, err := ds.ScanStruct(&credential)
+	if err != nil {
+		return models.PasskeyCredential{}, false, fmt.Errorf("faile get passkey:%v", err)
+	}
+
+	return credential, found, nil
+}
+
+func (pr *SqlPasskeyRepository) FindByUser(userUUID uuid.UUID) ([]models.PasskeyCredential, error) {
+	ds := pr.db.From(goqu.T("webauthn_credentials")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Order(goqu.C("created_at").Asc())
+
+	var credentials []models.PasskeyCredential
+	if err := ds.ScanStructs(&credentials); err != nil {
+		return nil, fmt.Errorf("faile get passkeys:%v", err)
+	}
+
+	return credentials, nil
+}
+
+func (pr *SqlPasskeyRepository) UpdateSignCount(id int64, signCount int64) error {
+	_, err := pr.db.Update(goqu.T("webauthn_credentials")).Set(goqu.Record{
+		"sign_count": signCount,
+		"last_used_at": time.Now(),
+	}).Where(
+		goqu.C("id").Eq(id),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile update passkey:%v", err)
+	}
+
+	return nil
+}
+
+func (pr *SqlPasskeyRepository) MarkCloneWarning(id int64) error {
+	_, err := pr.db.Update(goqu.T("webauthn_credentials")).Set(goqu.Record{
+		"clone_warning": true,
+	}).Where(
+		goqu.C("id").Eq(id),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile flag passkey:%v", err)
+	}
+
+	return nil
+}
+
+func (pr *SqlPasskeyRepository) Delete(userUUID uuid.UUID, id int64) (bool, error) {
+	result, err := pr.db.Delete(goqu.T("webauthn_credentials")).Where(
+		goqu.C("id").Eq(id),
+		goqu.C("user_uuid").Eq(userUUID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile delete passkey:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
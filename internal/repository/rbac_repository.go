@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlRBACRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlRBACRepository(DB *goqu.Database) RBACRepository {
+	return &SqlRBACRepository{
+		db: DB,
+	}
+}
+
+func (rr *SqlRBACRepository) CreateRole(role models.Role) (models.Role, error) {
+	result, err := rr.db.Insert(goqu.T("roles")).Rows(role).Executor().Exec()
+	if err != nil {
+		return models.Role{}, fmt.Errorf("faile insert role:%v", err)
+	}
+
+	role.ID, err = result.LastInsertId()
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (rr *SqlRBACRepository) FindRoleByID(id int64) (models.Role, bool, error) {
+	ds := rr.db.From(goqu.T("roles")).Where(
+		goqu.C("id").Eq(id),
+	).Limit(1)
+
+	var role models.Role
+	found, err := ds.ScanStruct(&role)
+	if err != nil {
+		return models.Role{}, false, fmt.Errorf("faile get role:%v", err)
+	}
+
+	return role, found, nil
+}
+
+func (rr *SqlRBACRepository) FindAllRoles() ([]models.Role, error) {
+	ds := rr.db.From(goqu.T("roles")).Order(goqu.C("name").Asc())
+
+	var roles []models.Role
+	if err := ds.ScanStructs(&roles); err != nil {
+		return nil, fmt.Errorf("faile get roles:%v", err)
+	}
+
+	return roles, nil
+}
+
+func (rr *SqlRBACRepository) UpdateRole(role models.Role) (bool, error) {
+	result, err := rr.db.Update(goqu.T("roles")).Set(goqu.Record{
+		"name": role.Name,
+		"description": role.Description,
+		"updated_at": time.Now(),
+	}).Where(
+		goqu.C("id").Eq(role.ID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update role:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (rr *SqlRBACRepository) DeleteRole(id int64) (bool, error) {
+	var deleted bool
+	err := rr.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Delete(goqu.T("role_permissions")).Where(
+			goqu.C("role_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete role permissions:%v", err)
+		}
+
+		if _, err := tx.Delete(goqu.T("user_roles")).Where(
+			goqu.C("role_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete user roles:%v", err)
+		}
+
+		if _, err := tx.Delete(goqu.T("group_roles")).Where(
+			goqu.C("role_id").Eq(id),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete group roles:%v", err)
+		}
+
+		result, err := tx.Delete(goqu.T("roles")).Where(
+			goqu.C("id").Eq(id),
+		).Executor().Exec()
+		if err != nil {
+			return fmt.Errorf("faile delete role:%v", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		deleted = affected > 0
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (rr *SqlRBACRepository) FindAllPermissions() ([]models.Permission, error) {
+	ds := rr.db.From(goqu.T("permissions")).Order(goqu.C("name").Asc())
+
+	var permissions []models.Permission
+	if err := ds.ScanStructs(&permissions); err != nil {
+		return nil, fmt.Errorf("faile get permissions:%v", err)
+	}
+
+	return permissions, nil
+}
+
+// SeedPermissions inserts the permissions missing by name and leaves the
+// existing ones alone.
+func (rr *SqlRBACRepository) SeedPermissions(permissions []models.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	_, err := rr.db.Insert(goqu.T("permissions")).Rows(permissions).
+	OnConflict(goqu.DoNothing()).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile seed permissions:%v", err)
+	}
+
+	return nil
+}
+
+func (rr *SqlRBACRepository) FindPermissionsByNames(names []string) ([]models.Permission, error) {
+	if len(names) == 0 {
+		return []models.Permission{}, nil
+	}
+
+	ds := rr.db.From(goqu.T("permissions")).Where(
+		goqu.C("name").In(names),
+	)
+
+	var permissions []models.Permission
+	if err := ds.ScanStructs(&permissions); err != nil {
+		return nil, fmt.Errorf("faile get permissions:%v", err)
+	}
+
+	return permissions, nil
+}
+
+func (rr *SqlRBACRepository) FindRolePermissions(roleID int64) ([]models.Permission, error) {
+	ds := rr.db.From(goqu.T("permissions").As("p")).
+	Join(goqu.T("role_permissions").As("rp"), goqu.On(goqu.I("rp.permission_id").Eq(goqu.I("p.id")))).
+	Where(
+		goqu.I("rp.role_id").Eq(roleID),
+	).
+	Select(goqu.I("p.id"), goqu.I("p.name"), goqu.I("p.description")).
+	Order(goqu.I("p.name").Asc())
+
+	var permissions []models.Permission
+	if err := ds.ScanStructs(&permissions); err != nil {
+		return nil, fmt.Errorf("faile get role permissions:%v", err)
+	}
+
+	return permissions, nil
+}
+
+func (rr *SqlRBACRepository) ReplaceRolePermissions(roleID int64, permissionIDs []int64) error {
+	return rr.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Delete(goqu.T("role_permissions")).Where(
+			goqu.C("role_id").Eq(roleID),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete role permissions:%v", err)
+		}
+
+		if len(permissionIDs) == 0 {
+			return nil
+		}
+
+		rows := make([]models.RolePermission, 0, len(permissionIDs))
+		for _, permissionID := range permissionIDs {
+			rows = append(rows, models.RolePermission{
+				RoleID: roleID,
+				PermissionID: permissionID,
+			})
+		}
+
+		if _, err := tx.Insert(goqu.T("role_permissions")).Rows(rows).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile insert role permissions:%v", err)
+		}
+
+		return nil
+	})
+}
+
+func (rr *SqlRBACRepository) FindUserRoles(userUUID uuid.UUID) ([]models.Role, error) {
+	ds := rr.db.From(goqu.T("roles").As("r")).
+	Join(goqu.T("user_roles").As("ur"), goqu.On(goqu.I("ur.role_id").Eq(goqu.I("r.id")))).
+	Where(
+		goqu.I("ur.user_uuid").Eq(userUUID),
+	).
+	Select(goqu.I("r.id"), goqu.I("r.name"), goqu.I("r.description"), goqu.I("r.created_at"), goqu.I("r.updated_at")).
+	Order(goqu.I("r.name").Asc())
+
+	var roles []models.Role
+	if err := ds.ScanStructs(&roles); err != nil {
+		return nil, fmt.Errorf("faile get user roles:%v", err)
+	}
+
+	return roles, nil
+}
+
+func (rr *SqlRBACRepository) FindUserPermissionNames(userUUID uuid.UUID) ([]string, error) {
+	ds := rr.db.From(goqu.T("permissions").As("p")).
+	Join(goqu.T("role_permissions").As("rp"), goqu.On(goqu.I("rp.permission_id").Eq(goqu.I("p.id")))).
+	Join(goqu.T("user_roles").As("ur"), goqu.On(goqu.I("ur.role_id").Eq(goqu.I("rp.role_id")))).
+	Where(
+		goqu.I("ur.user_uuid").Eq(userUUID),
+	).
+	Select(goqu.I("p.name")).
+	Distinct()
+
+	var names []string
+	if err := ds.ScanVals(&names); err != nil {
+		return nil, fmt.Errorf("faile get user permissions:%v", err)
+	}
+
+	return names, nil
+}
+
+func (rr *SqlRBACRepository) FindUserUUIDsByRole(roleID int64) ([]uuid.UUID, error) {
+	ds := rr.db.From(goqu.T("user_roles")).Where(
+		goqu.C("role_id").Eq(roleID),
+	).Select(goqu.C("user_uuid"))
+
+	var userUUIDs []uuid.UUID
+	if err := ds.ScanVals(&userUUIDs); err != nil {
+		return nil, fmt.Errorf("faile get role users:%v", err)
+	}
+
+	return userUUIDs, nil
+}
+
+func (rr *SqlRBACRepository) AssignUserRole(userUUID uuid.UUID, roleID int64) error {
+	_, err := rr.db.Insert(goqu.T("user_roles")).Rows(models.UserRole{
+		UserUUID: userUUID,
+		RoleID: roleID,
+		CreatedAt: time.Now(),
+	}).OnConflict(goqu.DoNothing()).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile assign user role:%v", err)
+	}
+
+	return nil
+}
+
+func (rr *SqlRBACRepository) RemoveUserRole(userUUID uuid.UUID, roleID int64) (bool, error) {
+	result, err := rr.db.Delete(goqu.T("user_roles")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+		goqu.C("role_id").Eq(roleID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile remove user role:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlRecoveryCodeRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlRecoveryCodeRepository(DB *goqu.Database) RecoveryCodeRepository {
+	return &SqlRecoveryCodeRepository{
+		db: DB,
+	}
+}
+
+func (rr *SqlRecoveryCodeRepository) ReplaceForUser(userUUID uuid.UUID, codes []models.RecoveryCode) error {
+	return rr.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if _, err := tx.Delete(goqu.T("user_recovery_codes")).Where(
+			goqu.C("user_uuid").Eq(userUUID),
+		).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile delete recovery codes:%v", err)
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		if _, err := tx.Insert(goqu.T("user_recovery_codes")).Rows(codes).Executor().Exec(); err != nil {
+			return fmt.Errorf("faile insert recovery codes:%v", err)
+		}
+
+		return nil
+	})
+}
+
+func (rr *SqlRecoveryCodeRepository) FindUnusedByHash(userUUID uuid.UUID, codeHash string) (models.RecoveryCode, bool, error) {
+	ds := rr.db.From(goqu.T("user_recovery_codes")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+		goqu.C("code_hash").Eq(codeHash),
+		goqu.C("used_at").IsNull(),
+	).Limit(1)
+
+	var code models.RecoveryCode
+	found, err := ds.ScanStruct(&code)
+	if err != nil {
+		return models.RecoveryCode{}, false, fmt.Errorf("faile get recovery code:%v", err)
+	}
+
+	return code, found, nil
+}
+
+// MarkUsed only succeeds for a code that has not been consumed yet, so two
+// concurrent logins cannot spend the same code.
+func (rr *SqlRecoveryCodeRepository) MarkUsed(id int64, ip string) (bool, error) {
+	result, err := rr.db.Update(goqu.T("user_recovery_codes")).Set(goqu.Record{
+		"used_at": time.Now(),
+		"used_ip": ip,
+	}).Where(
+		goqu.C("id").Eq(id),
+		goqu.C("used_at").IsNull(),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile mark recovery code used:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (rr *SqlRecoveryCodeRepository) CountUnused(userUUID uuid.UUID) (int64, error) {
+	count, err := rr.db.From(goqu.T("user_recovery_codes")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+		goqu.C("used_at").IsNull(),
+	).Count()
+	if err != nil {
+		return 0, fmt.Errorf("faile count recovery codes:%v", err)
+	}
+
+	return count, nil
+}
+
+func (rr *SqlRecoveryCodeRepository) DeleteByUser(userUUID uuid.UUID) error {
+	if _, err := rr.db.Delete(goqu.T("user_recovery_codes")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Executor().Exec(); err != nil {
+		return fmt.Errorf("faile delete recovery codes:%v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+)
+
+type SqlServiceAccountRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlServiceAccountRepository(DB *goqu.Database) ServiceAccountRepository {
+	return &SqlServiceAccountRepository{
+		db: DB,
+	}
+}
+
+func (sr *SqlServiceAccountRepository) Create(account models.ServiceAccount) error {
+	if _, err := sr.db.Insert(goqu.T("service_accounts")).Rows(account).Executor().Exec(); err != nil {
+		return fmt.Errorf("faile insert service account:%v", err)
+	}
+
+	return nil
+}
+
+func (sr *SqlServiceAccountRepository) FindByClientID(clientID string) (models.ServiceAccount, bool, error) {
+	ds := sr.db.From(goqu.T("service_accounts")).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Limit(1)
+
+	var account models.ServiceAccount
+	found, err := ds.ScanStruct(&account)
+	if err != nil {
+		return models.ServiceAccount{}, false, fmt.Errorf("faile get service account:%v", err)
+	}
+
+	return account, found, nil
+}
+
+func (sr *SqlServiceAccountRepository) FindAll() ([]models.ServiceAccount, error) {
+	ds := sr.db.From(goqu.T("service_accounts")).Order(goqu.C("created_at").Asc())
+
+	var accounts []models.ServiceAccount
+	if err := ds.ScanStructs(&accounts); err != nil {
+		return nil, fmt.Errorf("faile get service accounts:%v", err)
+	}
+
+	return accounts, nil
+}
+
+func (sr *SqlServiceAccountRepository) Update(account models.ServiceAccount) (bool, error) {
+	return sr.update(account.ClientID, goqu.Record{
+		"name": account.Name,
+		"description": account.Description,
+		"scopes": account.Scopes,
+	})
+}
+
+func (sr *SqlServiceAccountRepository) SetEnabled(clientID string, enabled bool) (bool, error) {
+	return sr.update(clientID, goqu.Record{
+		"enabled": enabled,
+	})
+}
+
+func (sr *SqlServiceAccountRepository) UpdateSecret(clientID, secretHash string, previousHash *string, previousExpiresAt *time.Time) (bool, error) {
+	return sr.update(clientID, goqu.Record{
+		"client_secret_hash": secretHash,
+		"previous_secret_hash": previousHash,
+		"previous_secret_expires_at": previousExpiresAt,
+	})
+}
+
+func (sr *SqlServiceAccountRepository) TouchLastUsed(clientID string) error {
+	_, err := sr.db.Update(goqu.T("service_accounts")).Set(goqu.Record{
+		"last_used_at": time.Now(),
+	}).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile update service account:%v", err)
+	}
+
+	return nil
+}
+
+func (sr *SqlServiceAccountRepository) update(clientID string, record goqu.Record) (bool, error) {
+	record["updated_at"] = time.Now()
+
+	result, err := sr.db.Update(goqu.T("service_accounts")).Set(record).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update service account:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (sr *SqlServiceAccountRepository) Delete(clientID string) (bool, error) {
+	result, err := sr.db.Delete(goqu.T("service_accounts")).Where(
+		goqu.C("client_id").Eq(clientID),
+	).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile delete service account:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type SqlTwoFactorRepository struct {
+	db *goqu.Database
+}
+
+func NewSqlTwoFactorRepository(DB *goqu.Database) TwoFactorRepository {
+	return &SqlTwoFactorRepository{
+		db: DB,
+	}
+}
+
+func (tr *SqlTwoFactorRepository) FindByUserUUID(userUUID uuid.UUID) (models.TwoFactor, bool, error) {
+	ds := tr.db.From(goqu.T("user_two_factors")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Limit(1)
+
+	var twoFactor models.TwoFactor
+	found, err := ds.ScanStruct(&twoFactor)
+	if err != nil {
+		return models.TwoFactor{}, false, fmt.Errorf("faile get two factor:%v", err)
+	}
+
+	return twoFactor, found, nil
+}
+
+func (tr *SqlTwoFactorRepository) Save(twoFactor models.TwoFactor) error {
+	_, err := tr.db.Insert(goqu.T("user_two_factors")).Rows(twoFactor).
+	OnConflict(
+		goqu.DoUpdate("user_uuid", goqu.Record{
+			"secret": twoFactor.Secret,
+			"enabled": twoFactor.Enabled,
+			"confirmed_at": twoFactor.ConfirmedAt,
+			"created_at": twoFactor.CreatedAt,
+		}),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile save two factor:%v", err)
+	}
+
+	return nil
+}
+
+func (tr *SqlTwoFactorRepository) Enable(userUUID uuid.UUID) error {
+	_, err := tr.db.Update(goqu.T("user_two_factors")).Set(goqu.Record{
+		"enabled": true,
+		"confirmed_at": time.Now(),
+	}).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile enable two factor:%v", err)
+	}
+
+	return nil
+}
+
+func (tr *SqlTwoFactorRepository) Delete(userUUID uuid.UUID) error {
+	_, err := tr.db.Delete(goqu.T("user_two_factors")).Where(
+		goqu.C("user_uuid").Eq(userUUID),
+	).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile delete two factor:%v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exec"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/google/uuid"
+)
+
+// UserSortFields whitelists the columns a user listing can be ordered by.
+var UserSortFields = []string{"created_at", "updated_at", "name", "email", "age"}
+
+// UserFilter narrows a listing, zero values filter nothing. LastLoginTo also
+// matches users that never logged in and were created before it, which is
+// what an inactive account cleanup asks for.
+type UserFilter struct {
+	Status 		int8
+	Level 		int8
+	EmailPrefix string
+	NamePrefix 	string
+	CreatedFrom time.Time
+	CreatedTo 	time.Time
+	UpdatedFrom time.Time
+	UpdatedTo 	time.Time
+	LastLoginFrom 	time.Time
+	LastLoginTo 	time.Time
+	PasswordChangedTo 	time.Time
+	EmailVerified 		*bool
+}
+
+// UserPage asks for one page of a keyset pagination. Rows are ordered by Sort
+// with the uuid as tie-breaker, After continues behind the previous page.
+type UserPage struct {
+	Filter 	UserFilter
+	Sort 	string
+	Desc 	bool
+	After 	*UserCursor
+	Limit 	uint
+}
+
+// UserCursor points at the last row of a page. Value holds that row's sort
+// column as text so the cursor survives the round trip through a client.
+type UserCursor struct {
+	Sort 	string `json:"s"`
+	Desc 	bool `json:"d,omitempty"`
+	Value 	string `json:"v"`
+	UUID 	uuid.UUID `json:"u"`
+}
+
+func NewUserCursor(page UserPage, user models.User) UserCursor {
+	cursor := UserCursor{
+		Sort: page.Sort,
+		Desc: page.Desc,
+		UUID: user.UUID,
+	}
+
+	switch page.Sort {
+	case "name":
+		cursor.Value = user.Name
+	case "email":
+		cursor.Value = user.Email
+	case "age":
+		cursor.Value = strconv.Itoa(int(user.Age))
+	case "updated_at":
+		cursor.Value = user.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		cursor.Value = user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return cursor
+}
+
+func (cursor UserCursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeUserCursor(encoded string) (UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return UserCursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	var cursor UserCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return UserCursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	return cursor, nil
+}
+
+func (filter UserFilter) conditions() []exp.Expression {
+	conditions := []exp.Expression{}
+
+	if filter.Status != 0 {
+		conditions = append(conditions, goqu.C("status").Eq(filter.Status))
+	}
+	if filter.Level != 0 {
+		conditions = append(conditions, goqu.C("level").Eq(filter.Level))
+	}
+	if filter.EmailPrefix != "" {
+		conditions = append(conditions, goqu.C("email").ILike(likePrefix(filter.EmailPrefix)))
+	}
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, goqu.C("name").ILike(likePrefix(filter.NamePrefix)))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, goqu.C("created_at").Gte(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, goqu.C("created_at").Lt(filter.CreatedTo))
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		conditions = append(conditions, goqu.C("updated_at").Gte(filter.UpdatedFrom))
+	}
+	if !filter.UpdatedTo.IsZero() {
+		conditions = append(conditions, goqu.C("updated_at").Lt(filter.UpdatedTo))
+	}
+	if !filter.LastLoginFrom.IsZero() {
+		conditions = append(conditions, goqu.C("last_login_at").Gte(filter.LastLoginFrom))
+	}
+	if !filter.LastLoginTo.IsZero() {
+		conditions = append(conditions, goqu.Or(
+			goqu.C("last_login_at").Lt(filter.LastLoginTo),
+			goqu.And(goqu.C("last_login_at").IsNull(), goqu.C("created_at").Lt(filter.LastLoginTo)),
+		))
+	}
+	if !filter.PasswordChangedTo.IsZero() {
+		conditions = append(conditions, goqu.C("password_changed_at").Lt(filter.PasswordChangedTo))
+	}
+	if filter.EmailVerified != nil {
+		if *filter.EmailVerified {
+			conditions = append(conditions, goqu.C("email_verified_at").IsNotNull())
+		} else {
+			conditions = append(conditions, goqu.C("email_verified_at").IsNull())
+		}
+	}
+
+	return conditions
+}
+
+// keyset continues strictly after the cursor row in the page's order.
+func (page UserPage) keyset() (exp.Expression, error) {
+	cursor := page.After
+
+	var value any = cursor.Value
+	switch page.Sort {
+	case "age":
+		age, err := strconv.Atoi(cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed cursor")
+		}
+		value = age
+	case "created_at", "updated_at":
+		at, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed cursor")
+		}
+		value = at
+	}
+
+	column := goqu.C(page.Sort)
+	if page.Desc {
+		return goqu.Or(
+			column.Lt(value),
+			goqu.And(column.Eq(value), goqu.C("uuid").Lt(cursor.UUID)),
+		), nil
+	}
+
+	return goqu.Or(
+		column.Gt(value),
+		goqu.And(column.Eq(value), goqu.C("uuid").Gt(cursor.UUID)),
+	), nil
+}
+
+func likePrefix(prefix string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(prefix) + "%"
+}
+
+type sqlUserIterator struct {
+	scanner exec.Scanner
+}
+
+func (it *sqlUserIterator) Next() bool {
+	return it.scanner.Next()
+}
+
+func (it *sqlUserIterator) User() (models.User, error) {
+	var user models.User
+	if err := it.scanner.ScanStruct(&user); err != nil {
+		return models.User{}, fmt.Errorf("faile scan user:%v", err)
+	}
+
+	return user, nil
+}
+
+func (it *sqlUserIterator) Err() error {
+	if err := it.scanner.Err(); err != nil {
+		return fmt.Errorf("faile iterate users:%v", err)
+	}
+
+	return nil
+}
+
+func (it *sqlUserIterator) Close() error {
+	return it.scanner.Close()
+}
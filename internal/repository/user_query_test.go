@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/mysql"
+	"github.com/google/uuid"
+)
+
+var cursorUUID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+func TestNewUserCursor(t *testing.T) {
+	local := time.FixedZone("UTC+7", 7 * 60 * 60)
+	user := models.User{
+		UUID: cursorUUID,
+		Name: "Ann",
+		Email: "ann@example.com",
+		Age: 30,
+		CreatedAt: time.Date(2026, 1, 2, 10, 4, 5, 123456789, local),
+		UpdatedAt: time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC),
+	}
+
+	tests := []struct {
+		sort 	string
+		want 	string
+	}{
+		{"name", "Ann"},
+		{"email", "ann@example.com"},
+		{"age", "30"},
+		{"created_at", "2026-01-02T03:04:05.123456789Z"},
+		{"updated_at", "2026-02-03T04:05:06Z"},
+	}
+
+	for _, tt := range tests {
+		cursor := NewUserCursor(UserPage{Sort: tt.sort, Desc: true}, user)
+		if cursor.Value != tt.want || cursor.Sort != tt.sort || !cursor.Desc || cursor.UUID != cursorUUID {
+			t.Errorf("NewUserCursor(%s) = %+v, want value %q", tt.sort, cursor, tt.want)
+		}
+	}
+}
+
+func TestUserCursorRoundTrip(t *testing.T) {
+	tests := []UserCursor{
+		{Sort: "created_at", Value: "2026-01-02T03:04:05.123456789Z", UUID: cursorUUID},
+		{Sort: "name", Desc: true, Value: "Ann \"&\" Bob/ÿ", UUID: cursorUUID},
+		{Sort: "age", Value: "0", UUID: uuid.Nil},
+	}
+
+	for _, cursor := range tests {
+		encoded := cursor.Encode()
+		got, err := DecodeUserCursor(encoded)
+		if err != nil {
+			t.Errorf("DecodeUserCursor(%q) error: %v", encoded, err)
+			continue
+		}
+
+		if got != cursor {
+			t.Errorf("DecodeUserCursor(%q) = %+v, want %+v", encoded, got, cursor)
+		}
+	}
+}
+
+func TestDecodeUserCursorMalformed(t *testing.T) {
+	tests := []struct {
+		name 	string
+		encoded string
+	}{
+		{"not base64", "not a cursor!"},
+		{"padded base64", "e30="},
+		{"not JSON", "bm90IGpzb24"},
+		{"bad uuid", "eyJ1Ijoibm9wZSJ9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := DecodeUserCursor(tt.encoded); err == nil {
+				t.Errorf("DecodeUserCursor(%q) = %+v, want an error", tt.encoded, cursor)
+			}
+		})
+	}
+}
+
+func TestUserPageKeyset(t *testing.T) {
+	tests := []struct {
+		sort 	string
+		desc 	bool
+		value 	string
+		want 	string
+	}{
+		{"name", false, "Ann", "((`name` > 'Ann') OR ((`name` = 'Ann') AND (`uuid` > '00000000-0000-0000-0000-000000000001')))"},
+		{"name", true, "Ann", "((`name` < 'Ann') OR ((`name` = 'Ann') AND (`uuid` < '00000000-0000-0000-0000-000000000001')))"},
+		{"age", false, "30", "((`age` > 30) OR ((`age` = 30) AND (`uuid` > '00000000-0000-0000-0000-000000000001')))"},
+		{"created_at", true, "2026-01-02T03:04:05Z", "((`created_at` < '2026-01-02 03:04:05') OR ((`created_at` = '2026-01-02 03:04:05') AND (`uuid` < '00000000-0000-0000-0000-000000000001')))"},
+	}
+
+	for _, tt := range tests {
+		page := UserPage{Sort: tt.sort, Desc: tt.desc, After: &UserCursor{Sort: tt.sort, Desc: tt.desc, Value: tt.value, UUID: cursorUUID}}
+		keyset, err := page.keyset()
+		if err != nil {
+			t.Errorf("keyset(%s %q) error: %v", tt.sort, tt.value, err)
+			continue
+		}
+
+		sql, _, err := goqu.Dialect("mysql").From("users").Where(keyset).ToSQL()
+		if err != nil {
+			t.Fatalf("ToSQL error: %v", err)
+		}
+
+		if want := "SELECT * FROM `users` WHERE " + tt.want; sql != want {
+			t.Errorf("keyset(%s %q) = %s, want %s", tt.sort, tt.value, sql, want)
+		}
+	}
+}
+
+func TestUserPageKeysetMalformed(t *testing.T) {
+	tests := []struct {
+		sort 	string
+		value 	string
+	}{
+		{"age", "thirty"},
+		{"created_at", "yesterday"},
+		{"updated_at", "2026-01-02"},
+	}
+
+	for _, tt := range tests {
+		page := UserPage{Sort: tt.sort, After: &UserCursor{Sort: tt.sort, Value: tt.value, UUID: cursorUUID}}
+		if _, err := page.keyset(); err == nil {
+			t.Errorf("keyset(%s %q) succeeded, want an error", tt.sort, tt.value)
+		}
+	}
+}
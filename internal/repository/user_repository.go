@@ -2,36 +2,105 @@ package repository
 
 import (
 	"fmt"
-	"slices"
+	"time"
 
 	"github.com/dangLuan01/user-manager/internal/models"
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/google/uuid"
 )
 
 type SqlUserRepository struct {
-	users []models.User
 	db *goqu.Database
+	// Set through ForOrganization, a nil organizationID then means users without a tenant.
+	scoped bool
+	organizationID *string
+	// Set through OnlyDeleted, the repository then sees soft deleted users instead of live ones.
+	deleted bool
+}
+
+var userColumns = []any{
+	goqu.I("uuid"),
+	goqu.I("name"),
+	goqu.I("email"),
+	goqu.I("age"),
+	goqu.I("level"),
+	goqu.I("status"),
+	goqu.I("organization_id"),
+	goqu.I("created_at"),
+	goqu.I("updated_at"),
+	goqu.I("last_login_at"),
+	goqu.I("email_verified_at"),
+	goqu.I("password_changed_at"),
+	goqu.I("pending_email"),
+	goqu.I("version"),
+	goqu.I("deleted_at"),
+}
+
+// Rows that only make sense while the user exists, purging removes them.
+var userOwnedTables = []string{
+	"user_roles",
+	"group_users",
+	"organization_members",
+	"oauth_consents",
+	"user_two_factors",
+	"user_recovery_codes",
+	"webauthn_credentials",
 }
 
 func NewSqlUserRepository(DB *goqu.Database) UserRepository {
 	return &SqlUserRepository{
-		users : make([]models.User, 0),
 		db: DB,
 	}
 }
 
+// ForOrganization returns a repository whose every query is limited to one
+// tenant. An empty organizationID selects the users that belong to none.
+func (ur *SqlUserRepository) ForOrganization(organizationID string) UserRepository {
+	scoped := &SqlUserRepository{
+		db: ur.db,
+		scoped: true,
+		deleted: ur.deleted,
+	}
+	if organizationID != "" {
+		scoped.organizationID = &organizationID
+	}
+
+	return scoped
+}
+
+// OnlyDeleted returns a repository over the soft deleted users that are not
+// purged yet, keeping the organization scope.
+func (ur *SqlUserRepository) OnlyDeleted() UserRepository {
+	deleted := *ur
+	deleted.deleted = true
+
+	return &deleted
+}
+
+func (ur *SqlUserRepository) where(conditions ...exp.Expression) []exp.Expression {
+	if ur.deleted {
+		conditions = append(conditions, goqu.C("deleted_at").IsNotNull(), goqu.C("purged_at").IsNull())
+	} else {
+		conditions = append(conditions, goqu.C("deleted_at").IsNull())
+	}
+
+	if !ur.scoped {
+		return conditions
+	}
+
+	if ur.organizationID == nil {
+		return append(conditions, goqu.C("organization_id").IsNull())
+	}
+
+	return append(conditions, goqu.C("organization_id").Eq(*ur.organizationID))
+}
+
 func (ur *SqlUserRepository) FindAll() ([]models.User, error){
 	
 	ds := ur.db.From(goqu.T("users")).
-	Select(
-		goqu.I("uuid"),
-		goqu.I("name"),
-		goqu.I("email"),
-		goqu.I("age"),
-		goqu.I("level"),
-		goqu.I("status"),
-	)
+	Where(ur.where()...).
+	Select(userColumns...)
 	var users []models.User
 	if err := ds.ScanStructs(&users); err != nil {
 		return nil, fmt.Errorf("faile get all user:%v", err)
@@ -40,19 +109,80 @@ func (ur *SqlUserRepository) FindAll() ([]models.User, error){
 	return users, nil
 }
 
+// pageDataset selects page in its order, a zero Limit selects every row.
+func (ur *SqlUserRepository) pageDataset(page UserPage) (*goqu.SelectDataset, error) {
+	conditions := ur.where(page.Filter.conditions()...)
+	if page.After != nil {
+		keyset, err := page.keyset()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, keyset)
+	}
+
+	order := []exp.OrderedExpression{goqu.C(page.Sort).Asc(), goqu.C("uuid").Asc()}
+	if page.Desc {
+		order = []exp.OrderedExpression{goqu.C(page.Sort).Desc(), goqu.C("uuid").Desc()}
+	}
+
+	ds := ur.db.From(goqu.T("users")).
+	Where(conditions...).
+	Select(userColumns...).
+	Order(order...)
+	if page.Limit > 0 {
+		ds = ds.Limit(page.Limit)
+	}
+
+	return ds, nil
+}
+
+// FindPage returns up to page.Limit users, the caller asks for one more row
+// than it shows to learn whether another page follows.
+func (ur *SqlUserRepository) FindPage(page UserPage) ([]models.User, error) {
+	ds, err := ur.pageDataset(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	if err := ds.ScanStructs(&users); err != nil {
+		return nil, fmt.Errorf("faile get users page:%v", err)
+	}
+
+	return users, nil
+}
+
+// Iterate walks page one row at a time instead of loading it, the caller must
+// Close the iterator to release the connection.
+func (ur *SqlUserRepository) Iterate(page UserPage) (UserIterator, error) {
+	ds, err := ur.pageDataset(page)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := ds.Executor().Scanner()
+	if err != nil {
+		return nil, fmt.Errorf("faile iterate users:%v", err)
+	}
+
+	return &sqlUserIterator{scanner: scanner}, nil
+}
+
+func (ur *SqlUserRepository) Count(filter UserFilter) (int64, error) {
+	count, err := ur.db.From(goqu.T("users")).Where(ur.where(filter.conditions()...)...).Count()
+	if err != nil {
+		return 0, fmt.Errorf("faile count users:%v", err)
+	}
+
+	return count, nil
+}
+
 func (ur *SqlUserRepository) FindBYUUID(uuid uuid.UUID) (models.User, error) {
 	ds := ur.db.From(goqu.T("users")).
-	Where(
+	Where(ur.where(
 		goqu.C("uuid").Eq(uuid),
-	).
-	Select(
-		goqu.I("uuid"),
-		goqu.I("name"),
-		goqu.I("email"),
-		goqu.I("age"),
-		goqu.I("level"),
-		goqu.I("status"),
-	)
+	)...).
+	Select(userColumns...)
 	var user models.User
 
 	found, err := ds.ScanStruct(&user)
@@ -63,7 +193,42 @@ func (ur *SqlUserRepository) FindBYUUID(uuid uuid.UUID) (models.User, error) {
 	return user, err
 }
 
+func (ur *SqlUserRepository) FindByUUIDs(uuids []uuid.UUID) ([]models.User, error) {
+	users := make([]models.User, 0, len(uuids))
+	if len(uuids) == 0 {
+		return users, nil
+	}
+
+	ds := ur.db.From(goqu.T("users")).
+	Where(ur.where(
+		goqu.C("uuid").In(uuids),
+	)...).
+	Select(userColumns...)
+	if err := ds.ScanStructs(&users); err != nil {
+		return nil, fmt.Errorf("faile get users by uuid:%v", err)
+	}
+
+	return users, nil
+}
+
 func (ur *SqlUserRepository) Create(user models.User) error {
+	if ur.scoped {
+		user.OrganizationID = ur.organizationID
+	}
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	if user.PasswordChangedAt == nil && user.Password != "" {
+		user.PasswordChangedAt = &now
+	}
+	if user.Version == 0 {
+		user.Version = 1
+	}
+
 	insertUser := ur.db.Insert("users").Rows(user).Executor()
 	if _, err := insertUser.Exec(); err != nil {
        return fmt.Errorf("faile insert rows user")
@@ -72,43 +237,194 @@ func (ur *SqlUserRepository) Create(user models.User) error {
 	return nil
 }
 
-func (ur *SqlUserRepository) Update(uuid uuid.UUID, user models.User) error {
-	// for i, u := range ur.users{
-	// 	if u.UUID == uuid {
-	// 		ur.users[i] = user
-	// 		return nil
-	// 	}
-	// }
-	
-	_, err := ur.db.Update(goqu.T("users")).Set(
-		models.User{
-			Name: user.Name,
-			Email: user.Email,
-		},
-	).Executor().Exec()
+// Update writes every profile field, the password only when a new hash is given.
+// It only applies while the row is still at user.Version and reports false
+// when another write got there first.
+func (ur *SqlUserRepository) Update(uuid uuid.UUID, user models.User) (bool, error) {
+	now := time.Now()
+	record := goqu.Record{
+		"name": user.Name,
+		"email": user.Email,
+		"age": user.Age,
+		"level": user.Level,
+		"status": user.Status,
+		"email_verified_at": user.EmailVerifiedAt,
+		"pending_email": user.PendingEmail,
+		"updated_at": now,
+		"version": goqu.L("version + 1"),
+	}
+	if user.Password != "" {
+		record["password"] = user.Password
+		record["password_changed_at"] = now
+	}
+
+	result, err := ur.db.Update(goqu.T("users")).Set(record).
+	Where(ur.where(
+		goqu.C("uuid").Eq(uuid),
+		goqu.C("version").Eq(user.Version),
+	)...).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update user:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// UpdateFields writes only the given columns, under the same version check
+// as Update.
+func (ur *SqlUserRepository) UpdateFields(uuid uuid.UUID, version int64, fields map[string]any) (bool, error) {
+	record := goqu.Record{
+		"updated_at": time.Now(),
+		"version": goqu.L("version + 1"),
+	}
+	for column, value := range fields {
+		record[column] = value
+	}
+
+	result, err := ur.db.Update(goqu.T("users")).Set(record).
+	Where(ur.where(
+		goqu.C("uuid").Eq(uuid),
+		goqu.C("version").Eq(version),
+	)...).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile update user fields:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Delete soft deletes the user, the row stays until the retention window ends.
+// Like Update it only applies at version.
+func (ur *SqlUserRepository) Delete(uuid uuid.UUID, version int64) (bool, error) {
+	result, err := ur.db.Update(goqu.T("users")).Set(goqu.Record{
+		"deleted_at": time.Now(),
+		"version": goqu.L("version + 1"),
+	}).
+	Where(ur.where(
+		goqu.C("uuid").Eq(uuid),
+		goqu.C("version").Eq(version),
+	)...).Executor().Exec()
+	if err != nil {
+		return false, fmt.Errorf("faile delete user:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (ur *SqlUserRepository) Restore(uuid uuid.UUID) error {
+	deleted := ur.OnlyDeleted().(*SqlUserRepository)
+
+	result, err := ur.db.Update(goqu.T("users")).Set(goqu.Record{
+		"deleted_at": nil,
+		"updated_at": time.Now(),
+		"version": goqu.L("version + 1"),
+	}).
+	Where(deleted.where(
+		goqu.C("uuid").Eq(uuid),
+	)...).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile restore user:%v", err)
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
-	
-	return fmt.Errorf("user not found")
+
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
 }
 
-func (ur *SqlUserRepository) Delete(uuid uuid.UUID) error {
+// Purge permanently removes up to limit users soft deleted before
+// deletedBefore, ignoring the organization scope. A user that invitations
+// still point to keeps an anonymized row instead.
+func (ur *SqlUserRepository) Purge(deletedBefore time.Time, limit uint) (int, error) {
+	var users []models.User
+	if err := ur.db.From(goqu.T("users")).Where(
+		goqu.C("deleted_at").Lt(deletedBefore),
+		goqu.C("purged_at").IsNull(),
+	).Select(userColumns...).Order(goqu.C("deleted_at").Asc()).Limit(limit).ScanStructs(&users); err != nil {
+		return 0, fmt.Errorf("faile get deleted users:%v", err)
+	}
 
-	for i, u := range ur.users{
-		if u.UUID == uuid {
-			ur.users = slices.Delete(ur.users, i, i + 1)
-			return nil
+	purged := 0
+	for _, user := range users {
+		if err := ur.db.WithTx(func(tx *goqu.TxDatabase) error {
+			return purgeUser(tx, user)
+		}); err != nil {
+			return purged, fmt.Errorf("faile purge user %s:%v", user.UUID, err)
 		}
+		purged++
 	}
 
-	return fmt.Errorf("user not found")
+	return purged, nil
 }
+
+func purgeUser(tx *goqu.TxDatabase, user models.User) error {
+	for _, table := range userOwnedTables {
+		if _, err := tx.Delete(goqu.T(table)).Where(goqu.C("user_uuid").Eq(user.UUID)).Executor().Exec(); err != nil {
+			return err
+		}
+	}
+
+	anonymousEmail := fmt.Sprintf("deleted-%s@deleted.invalid", user.UUID)
+
+	// Invitations sent to the address before the account went away.
+	if _, err := tx.Update(goqu.T("invitations")).Set(goqu.Record{"email": anonymousEmail}).Where(
+		goqu.C("email").Eq(user.Email),
+		goqu.C("created_at").Lte(*user.DeletedAt),
+	).Executor().Exec(); err != nil {
+		return err
+	}
+
+	var invited int64
+	if _, err := tx.From(goqu.T("invitations")).Select(goqu.COUNT("*")).Where(
+		goqu.C("invited_by").Eq(user.UUID),
+	).ScanVal(&invited); err != nil {
+		return err
+	}
+
+	if invited == 0 {
+		_, err := tx.Delete(goqu.T("users")).Where(goqu.C("uuid").Eq(user.UUID)).Executor().Exec()
+		return err
+	}
+
+	_, err := tx.Update(goqu.T("users")).Set(goqu.Record{
+		"name": "Deleted user",
+		"email": anonymousEmail,
+		"password": "",
+		"pending_email": nil,
+		"updated_at": time.Now(),
+		"purged_at": time.Now(),
+		"version": goqu.L("version + 1"),
+	}).Where(goqu.C("uuid").Eq(user.UUID)).Executor().Exec()
+
+	return err
+}
+
 func (ur *SqlUserRepository) FindByEmail(email string) (models.User, error) {
 	
-	ds := ur.db.From(goqu.T("users")).Where(
+	ds := ur.db.From(goqu.T("users")).Where(ur.where(
 		goqu.C("email").Eq(email),
-	).Limit(1)
+	)...).Limit(1)
 	
     var user models.User
     found, err := ds.ScanStruct(&user)
@@ -123,16 +439,71 @@ func (ur *SqlUserRepository) FindByEmail(email string) (models.User, error) {
 	return models.User{}, err
 }
 
+func (ur *SqlUserRepository) FindByEmails(emails []string) ([]models.User, error) {
+	users := make([]models.User, 0, len(emails))
+	if len(emails) == 0 {
+		return users, nil
+	}
+
+	ds := ur.db.From(goqu.T("users")).
+	Where(ur.where(
+		goqu.C("email").In(emails),
+	)...).
+	Select(userColumns...)
+	if err := ds.ScanStructs(&users); err != nil {
+		return nil, fmt.Errorf("faile get users by email:%v", err)
+	}
+
+	return users, nil
+}
+
 func (ur *SqlUserRepository) UpdatePassword(uuid uuid.UUID, password string) error {
 
-	_, err := ur.db.Update(goqu.T("users")).Set(goqu.Record{"password": password}).
-	Where(
+	now := time.Now()
+	_, err := ur.db.Update(goqu.T("users")).Set(goqu.Record{
+		"password": password,
+		"password_changed_at": now,
+		"updated_at": now,
+		"version": goqu.L("version + 1"),
+	}).
+	Where(ur.where(
 		goqu.C("uuid").Eq(uuid),
-	).Executor().Exec()
+	)...).Executor().Exec()
 
 	if err != nil {
 		return err
 	}
 
+	return nil
+}
+
+// FindPassword returns the password hash, which no other finder selects.
+func (ur *SqlUserRepository) FindPassword(uuid uuid.UUID) (string, error) {
+	var password string
+	found, err := ur.db.From(goqu.T("users")).Select(goqu.I("password")).Where(ur.where(
+		goqu.C("uuid").Eq(uuid),
+	)...).ScanVal(&password)
+	if err != nil {
+		return "", fmt.Errorf("faile get password:%v", err)
+	}
+
+	if !found {
+		return "", fmt.Errorf("user not found")
+	}
+
+	return password, nil
+}
+
+// TouchLastLogin records a completed sign-in. It leaves updated_at alone,
+// logging in does not change the account.
+func (ur *SqlUserRepository) TouchLastLogin(uuid uuid.UUID) error {
+	_, err := ur.db.Update(goqu.T("users")).Set(goqu.Record{"last_login_at": time.Now()}).
+	Where(ur.where(
+		goqu.C("uuid").Eq(uuid),
+	)...).Executor().Exec()
+	if err != nil {
+		return fmt.Errorf("faile update last login:%v", err)
+	}
+
 	return nil
 }
\ No newline at end of file
@@ -12,7 +12,18 @@ type Route interface {
 	Register(r *gin.RouterGroup)
 }
 
+// PublicRoute is implemented by routes that also serve endpoints outside /api/v1.
+type PublicRoute interface {
+	RegisterPublic(r *gin.RouterGroup)
+}
+
 func RegisterRoute(r *gin.Engine, authService auth.TokenService, cacheService cache.RedisCacheService , routes ...Route) {
+	// Protocol endpoints (discovery, OAuth) live at the root and are not behind the API key.
+	public := r.Group("")
+	public.Use(
+		middleware.RateLimiterMiddleware(),
+	)
+
 	v1api := r.Group("/api/v1")
 
 	v1api.Use(	
@@ -27,10 +38,15 @@ func RegisterRoute(r *gin.Engine, authService auth.TokenService, cacheService ca
 	)
 
 	for _, route := range routes {
+		if publicRoute, ok := route.(PublicRoute); ok {
+			publicRoute.RegisterPublic(public)
+		}
 
 		switch route.(type) {
-		case *v1routes.AuthRoutes:
+		case *v1routes.AuthRoutes, *v1routes.InvitationRoutes, *v1routes.AccountRoutes:
 			route.Register(v1api)
+		case *v1routes.WellKnownRoutes:
+			route.Register(public)
 		default:
 			route.Register(protected)
 		}
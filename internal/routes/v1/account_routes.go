@@ -0,0 +1,33 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountRoutes struct {
+	handler *v1handler.AccountHandler
+}
+
+func NewAccountRoutes(handler *v1handler.AccountHandler) *AccountRoutes {
+	return &AccountRoutes{
+		handler: handler,
+	}
+}
+
+// Register mounts on the unauthenticated API group, the revert link is followed
+// from the old inbox by someone who may no longer be able to sign in.
+func (ar *AccountRoutes) Register(r *gin.RouterGroup) {
+	r.POST("/me/email/revert", ar.handler.RevertEmail)
+
+	me := r.Group("/me", middleware.AuthMiddleware(), middleware.RequireScope("account"))
+	{
+		me.GET("", ar.handler.GetAccount)
+		me.PATCH("", ar.handler.PatchAccount)
+		me.DELETE("", ar.handler.CloseAccount)
+		me.POST("/password", ar.handler.ChangePassword)
+		me.POST("/email", ar.handler.ChangeEmail)
+		me.POST("/email/confirm", ar.handler.ConfirmEmail)
+	}
+}
@@ -2,6 +2,7 @@ package v1routes
 
 import (
 	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,5 +26,24 @@ func (ar *AuthRoutes) Register(r *gin.RouterGroup) {
 		auth.POST("/reset-password", ar.handler.RequestResetPassword)
 		auth.POST("/register", ar.handler.Register)
 		auth.POST("/confirm-otp", ar.handler.RegisterOTP)
+		auth.POST("/2fa/verify", ar.handler.VerifyTwoFactor)
+		auth.POST("/passkeys/login/begin", ar.handler.BeginPasskeyLogin)
+		auth.POST("/passkeys/login/finish", ar.handler.FinishPasskeyLogin)
+	}
+
+	twoFactor := auth.Group("/2fa", middleware.AuthMiddleware(), middleware.RequireScope("account"))
+	{
+		twoFactor.POST("/setup", ar.handler.SetupTwoFactor)
+		twoFactor.POST("/confirm", ar.handler.ConfirmTwoFactor)
+		twoFactor.POST("/disable", ar.handler.DisableTwoFactor)
+		twoFactor.POST("/recovery-codes", ar.handler.RegenerateRecoveryCodes)
+	}
+
+	passkeys := auth.Group("/passkeys", middleware.AuthMiddleware(), middleware.RequireScope("account"))
+	{
+		passkeys.GET("", ar.handler.ListPasskeys)
+		passkeys.POST("/register/begin", ar.handler.BeginPasskeyRegistration)
+		passkeys.POST("/register/finish", ar.handler.FinishPasskeyRegistration)
+		passkeys.DELETE("/:id", ar.handler.DeletePasskey)
 	}
 }
\ No newline at end of file
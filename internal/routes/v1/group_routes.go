@@ -0,0 +1,40 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type GroupRoutes struct {
+	handler *v1handler.GroupHandler
+}
+
+func NewGroupRoutes(handler *v1handler.GroupHandler) *GroupRoutes {
+	return &GroupRoutes{
+		handler: handler,
+	}
+}
+
+func (gr *GroupRoutes) Register(r *gin.RouterGroup) {
+	groups := r.Group("/groups", middleware.RequireScope("users:write"))
+	{
+		groups.GET("", middleware.RequirePermission("groups:read"), gr.handler.ListGroups)
+		groups.POST("", middleware.RequirePermission("groups:write"), gr.handler.CreateGroup)
+		groups.GET("/:id", middleware.RequirePermission("groups:read"), gr.handler.GetGroup)
+		groups.PUT("/:id", middleware.RequirePermission("groups:write"), gr.handler.UpdateGroup)
+		groups.DELETE("/:id", middleware.RequirePermission("groups:write"), gr.handler.DeleteGroup)
+		groups.GET("/:id/members", middleware.RequirePermission("groups:read"), gr.handler.ListMembers)
+		groups.PUT("/:id/users/:uuid", middleware.RequirePermission("groups:write"), gr.handler.AddUser)
+		groups.DELETE("/:id/users/:uuid", middleware.RequirePermission("groups:write"), gr.handler.RemoveUser)
+		groups.PUT("/:id/groups/:child_id", middleware.RequirePermission("groups:write"), gr.handler.AddChildGroup)
+		groups.DELETE("/:id/groups/:child_id", middleware.RequirePermission("groups:write"), gr.handler.RemoveChildGroup)
+		groups.GET("/:id/roles", middleware.RequirePermission("roles:read"), gr.handler.ListGroupRoles)
+		groups.PUT("/:id/roles/:role_id", middleware.RequirePermission("roles:write"), gr.handler.AssignGroupRole)
+		groups.DELETE("/:id/roles/:role_id", middleware.RequirePermission("roles:write"), gr.handler.RemoveGroupRole)
+		groups.GET("/:id/permissions", middleware.RequirePermission("roles:read"), gr.handler.ListGroupPermissions)
+		groups.PUT("/:id/permissions", middleware.RequirePermission("roles:write"), gr.handler.SetGroupPermissions)
+	}
+
+	r.GET("/users/:uuid/groups", middleware.RequireScope("users:read"), middleware.RequirePermission("groups:read"), gr.handler.ListUserGroups)
+}
@@ -0,0 +1,31 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationRoutes struct {
+	handler *v1handler.InvitationHandler
+}
+
+func NewInvitationRoutes(handler *v1handler.InvitationHandler) *InvitationRoutes {
+	return &InvitationRoutes{
+		handler: handler,
+	}
+}
+
+// Register mounts on the unauthenticated API group, invitees accept before they
+// have an account.
+func (ir *InvitationRoutes) Register(r *gin.RouterGroup) {
+	r.POST("/invitations/accept", ir.handler.AcceptInvitation)
+
+	invitations := r.Group("/invitations", middleware.AuthMiddleware(), middleware.RequireScope("users:write"))
+	{
+		invitations.GET("", ir.handler.ListInvitations)
+		invitations.POST("", ir.handler.CreateInvitation)
+		invitations.POST("/:id/resend", ir.handler.ResendInvitation)
+		invitations.DELETE("/:id", ir.handler.RevokeInvitation)
+	}
+}
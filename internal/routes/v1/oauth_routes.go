@@ -0,0 +1,53 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthRoutes struct {
+	handler *v1handler.OAuthHandler
+}
+
+func NewOAuthRoutes(handler *v1handler.OAuthHandler) *OAuthRoutes {
+	return &OAuthRoutes{
+		handler: handler,
+	}
+}
+
+func (or *OAuthRoutes) Register(r *gin.RouterGroup) {
+	// Login tokens carry no scopes, so the permission is what keeps clients to administrators.
+	clients := r.Group("/oauth/clients", middleware.RequireScope("oauth:clients"), middleware.RequirePermission("oauth_clients:write"))
+	{
+		clients.GET("", or.handler.ListClients)
+		clients.POST("", or.handler.CreateClient)
+		clients.GET("/:client_id", or.handler.GetClient)
+		clients.PUT("/:client_id", or.handler.UpdateClient)
+		clients.DELETE("/:client_id", or.handler.DeleteClient)
+	}
+
+	consents := r.Group("/oauth/consents", middleware.RequireScope("account"))
+	{
+		consents.GET("", or.handler.ListConsents)
+		consents.DELETE("/:client_id", or.handler.RevokeConsent)
+	}
+}
+
+// RegisterPublic mounts the protocol endpoints at the root, where OAuth clients
+// expect them, without the API key the rest of the API requires.
+func (or *OAuthRoutes) RegisterPublic(r *gin.RouterGroup) {
+	oauth := r.Group("/oauth")
+	{
+		// Consent is the user's alone, tokens issued to clients cannot reach it.
+		oauth.GET("/authorize", middleware.LoginRedirectMiddleware(), middleware.AuthMiddleware(), middleware.RequireFirstPartyToken(), or.handler.Authorize)
+		oauth.POST("/authorize", middleware.AuthMiddleware(), middleware.RequireFirstPartyToken(), or.handler.DecideAuthorization)
+		oauth.POST("/token", or.handler.Token)
+		oauth.POST("/introspect", or.handler.Introspect)
+		oauth.POST("/revoke", or.handler.Revoke)
+	}
+
+	r.GET("/.well-known/openid-configuration", or.handler.OpenIDConfiguration)
+	r.GET("/userinfo", middleware.AuthMiddleware(), middleware.RequireScope("openid"), or.handler.UserInfo)
+	r.POST("/userinfo", middleware.AuthMiddleware(), middleware.RequireScope("openid"), or.handler.UserInfo)
+}
@@ -0,0 +1,32 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationRoutes struct {
+	handler *v1handler.OrganizationHandler
+}
+
+func NewOrganizationRoutes(handler *v1handler.OrganizationHandler) *OrganizationRoutes {
+	return &OrganizationRoutes{
+		handler: handler,
+	}
+}
+
+func (or *OrganizationRoutes) Register(r *gin.RouterGroup) {
+	orgs := r.Group("/orgs", middleware.RequireScope("account"))
+	{
+		orgs.GET("", or.handler.ListOrganizations)
+		orgs.POST("", or.handler.CreateOrganization)
+		orgs.GET("/:id", or.handler.GetOrganization)
+		orgs.PUT("/:id", or.handler.UpdateOrganization)
+		orgs.DELETE("/:id", or.handler.DeleteOrganization)
+		orgs.POST("/:id/switch", or.handler.SwitchOrganization)
+		orgs.GET("/:id/members", or.handler.ListMembers)
+		orgs.PUT("/:id/members/:uuid", or.handler.SetMemberRole)
+		orgs.DELETE("/:id/members/:uuid", or.handler.RemoveMember)
+	}
+}
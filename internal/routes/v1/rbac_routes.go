@@ -0,0 +1,39 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type RBACRoutes struct {
+	handler *v1handler.RBACHandler
+}
+
+func NewRBACRoutes(handler *v1handler.RBACHandler) *RBACRoutes {
+	return &RBACRoutes{
+		handler: handler,
+	}
+}
+
+func (rr *RBACRoutes) Register(r *gin.RouterGroup) {
+	roles := r.Group("/roles", middleware.RequireScope("users:write"))
+	{
+		roles.GET("", middleware.RequirePermission("roles:read"), rr.handler.ListRoles)
+		roles.POST("", middleware.RequirePermission("roles:write"), rr.handler.CreateRole)
+		roles.GET("/:id", middleware.RequirePermission("roles:read"), rr.handler.GetRole)
+		roles.PUT("/:id", middleware.RequirePermission("roles:write"), rr.handler.UpdateRole)
+		roles.DELETE("/:id", middleware.RequirePermission("roles:write"), rr.handler.DeleteRole)
+		roles.PUT("/:id/permissions", middleware.RequirePermission("roles:write"), rr.handler.SetRolePermissions)
+	}
+
+	r.GET("/permissions", middleware.RequireScope("users:write"), middleware.RequirePermission("roles:read"), rr.handler.ListPermissions)
+	r.GET("/auth/permissions", middleware.RequireScope("account"), rr.handler.ListMyPermissions)
+
+	userRoles := r.Group("/users/:uuid/roles", middleware.RequireScope("users:write"))
+	{
+		userRoles.GET("", middleware.RequirePermission("roles:read"), rr.handler.ListUserRoles)
+		userRoles.PUT("/:role_id", middleware.RequirePermission("roles:write"), rr.handler.AssignUserRole)
+		userRoles.DELETE("/:role_id", middleware.RequirePermission("roles:write"), rr.handler.RemoveUserRole)
+	}
+}
@@ -0,0 +1,32 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceAccountRoutes struct {
+	handler *v1handler.ServiceAccountHandler
+}
+
+func NewServiceAccountRoutes(handler *v1handler.ServiceAccountHandler) *ServiceAccountRoutes {
+	return &ServiceAccountRoutes{
+		handler: handler,
+	}
+}
+
+func (sr *ServiceAccountRoutes) Register(r *gin.RouterGroup) {
+	// A service account acts across every tenant, only administrators may create or change one.
+	accounts := r.Group("/service-accounts", middleware.RequireScope("oauth:clients"), middleware.RequirePermission("service_accounts:write"))
+	{
+		accounts.GET("", sr.handler.ListServiceAccounts)
+		accounts.POST("", sr.handler.CreateServiceAccount)
+		accounts.GET("/:client_id", sr.handler.GetServiceAccount)
+		accounts.PUT("/:client_id", sr.handler.UpdateServiceAccount)
+		accounts.DELETE("/:client_id", sr.handler.DeleteServiceAccount)
+		accounts.POST("/:client_id/enable", sr.handler.EnableServiceAccount)
+		accounts.POST("/:client_id/disable", sr.handler.DisableServiceAccount)
+		accounts.POST("/:client_id/secret", sr.handler.RotateServiceAccountSecret)
+	}
+}
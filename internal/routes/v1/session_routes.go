@@ -0,0 +1,33 @@
+package v1routes
+
+import (
+	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionRoutes struct {
+	handler *v1handler.SessionHandler
+}
+
+func NewSessionRoutes(handler *v1handler.SessionHandler) *SessionRoutes {
+	return &SessionRoutes{
+		handler: handler,
+	}
+}
+
+func (sr *SessionRoutes) Register(r *gin.RouterGroup) {
+	sessions := r.Group("/auth/sessions", middleware.RequireScope("account"))
+	{
+		sessions.GET("", sr.handler.ListMySessions)
+		sessions.DELETE("", sr.handler.RevokeMySessions)
+		sessions.DELETE("/:id", sr.handler.RevokeMySession)
+	}
+
+	userSessions := r.Group("/users/:uuid/sessions", middleware.RequireScope("users:write"), middleware.RequirePermission("users:write"))
+	{
+		userSessions.GET("", sr.handler.ListUserSessions)
+		userSessions.DELETE("", sr.handler.RevokeUserSessions)
+		userSessions.DELETE("/:id", sr.handler.RevokeUserSession)
+	}
+}
@@ -2,6 +2,7 @@ package v1routes
 
 import (
 	v1handler "github.com/dangLuan01/user-manager/internal/handler/v1"
+	"github.com/dangLuan01/user-manager/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,12 +17,30 @@ func NewUserRoutes(handler *v1handler.UserHandler) *UserRoutes {
 }
 
 func (ur *UserRoutes) Register(r *gin.RouterGroup) {
+	// Access is decided by the user policies, which weigh both RBAC permissions
+	// and the caller's role in the active organization.
 	users := r.Group("/users")
 	{
-		users.GET("", ur.handler.GetAllUser)
-		users.GET("/:uuid", ur.handler.GetUserByUUID)
-		users.POST("", ur.handler.CreateUser)
-		users.PUT("/:uuid", ur.handler.UpdateUser)
-		users.DELETE("/:uuid", ur.handler.DeleteUser)
+		users.GET("", middleware.RequireScope("users:read"), ur.handler.GetAllUser)
+		users.GET("/search", middleware.RequireScope("users:read"), ur.handler.SearchUsers)
+		// Rebuilds the index of every tenant, so it takes the global permission.
+		users.POST("/search/reindex", middleware.RequireScope("users:write"), middleware.RequirePermission("users:write"), ur.handler.ReindexUsers)
+		users.POST("/import", middleware.RequireScope("users:write"), ur.handler.ImportUsers)
+		users.GET("/import/:id", middleware.RequireScope("users:write"), ur.handler.GetImportJob)
+		users.GET("/export", middleware.RequireScope("users:read"), ur.handler.ExportUsers)
+		users.GET("/export/:id", middleware.RequireScope("users:read"), ur.handler.GetExportJob)
+		users.GET("/deleted", middleware.RequireScope("users:write"), ur.handler.ListDeletedUsers)
+		users.GET("/:uuid", middleware.RequireScope("users:read"), ur.handler.GetUserByUUID)
+		users.POST("", middleware.RequireScope("users:write"), ur.handler.CreateUser)
+		users.PUT("/:uuid", middleware.RequireScope("users:write"), ur.handler.UpdateUser)
+		users.PATCH("/:uuid", middleware.RequireScope("users:write"), ur.handler.PatchUser)
+		users.DELETE("/:uuid", middleware.RequireScope("users:write"), ur.handler.DeleteUser)
+		users.POST("/:uuid/restore", middleware.RequireScope("users:write"), ur.handler.RestoreUser)
 	}
+}
+
+// RegisterPublic serves export downloads, whose signed links carry no token
+// so they open straight from a browser.
+func (ur *UserRoutes) RegisterPublic(r *gin.RouterGroup) {
+	r.GET("/exports/users/:id", ur.handler.DownloadUserExport)
 }
\ No newline at end of file
@@ -0,0 +1,276 @@
+package v1service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// selfPatchFields are the fields a user may patch on their own account. The
+// email and password have their own endpoints, level and status are never
+// self-service.
+var selfPatchFields = []string{"name", "age"}
+
+type accountService struct {
+	userRepo repository.UserRepository
+	organizationRepo repository.OrganizationRepository
+	tokenService auth.TokenService
+	cache cache.RedisCacheService
+	index search.SearchIndex
+	emailChanger *emailChanger
+}
+
+func NewAccountService(userRepo repository.UserRepository, organizationRepo repository.OrganizationRepository, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService, index search.SearchIndex) AccountService {
+	return &accountService{
+		userRepo: userRepo,
+		organizationRepo: organizationRepo,
+		tokenService: tokenService,
+		cache: cacheService,
+		index: index,
+		emailChanger: newEmailChanger(userRepo, tokenService, cacheService, rabbitmqService, index),
+	}
+}
+
+func (acs *accountService) GetAccount(ctx *gin.Context, actor uuid.UUID) (models.User, error) {
+	return acs.findAccount(actor)
+}
+
+// PatchAccount takes the same patch formats as PATCH /users/:uuid but only
+// for selfPatchFields.
+func (acs *accountService) PatchAccount(ctx *gin.Context, actor uuid.UUID, patch v1dto.UserPatch) (models.User, error) {
+	user, err := acs.findAccount(actor)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	input, err := patchUserInput(user, patch)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return models.User{}, err
+	}
+
+	changes := patchChanges(user, input)
+
+	rejected := make([]string, 0)
+	for field := range changes {
+		if !slices.Contains(selfPatchFields, field) {
+			rejected = append(rejected, field)
+		}
+	}
+	if len(rejected) > 0 {
+		slices.Sort(rejected)
+		return models.User{}, utils.NewError(string(utils.ErrCodeForbidden), "Not allowed to change " + strings.Join(rejected, ", "))
+	}
+
+	if err := checkIfMatch(ctx, user); err != nil {
+		return models.User{}, err
+	}
+
+	if len(changes) == 0 {
+		return user, nil
+	}
+
+	updated, err := acs.userRepo.UpdateFields(actor, user.Version, changes)
+	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile update account", err)
+	}
+
+	if !updated {
+		return models.User{}, errUserModified
+	}
+
+	user = applyUserChanges(user, changes)
+	user.UpdatedAt = time.Now()
+	user.Version++
+	indexUser(acs.index, user)
+
+	return user, nil
+}
+
+// ChangePassword signs the user out everywhere once the new password is set.
+func (acs *accountService) ChangePassword(ctx *gin.Context, actor uuid.UUID, input v1dto.ChangePasswordInput) error {
+	if err := acs.checkPassword(actor, input.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Faile hash password", err)
+	}
+
+	if err := acs.userRepo.UpdatePassword(actor, string(hashPassword)); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable update new password", err)
+	}
+
+	if err := acs.tokenService.RevokeUserRefreshFamilies(actor); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
+
+	return nil
+}
+
+// ChangeEmail parks the new address in pending_email, Email stays in use until
+// ConfirmEmail gets the code mailed to it.
+func (acs *accountService) ChangeEmail(ctx *gin.Context, actor uuid.UUID, input v1dto.ChangeEmailInput) (models.User, error) {
+	user, err := acs.findAccount(actor)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err := acs.checkPassword(actor, input.CurrentPassword); err != nil {
+		return models.User{}, err
+	}
+
+	email := utils.NormailizeString(input.Email)
+	if email == user.Email {
+		return models.User{}, utils.NewError(string(utils.ErrCodeBadRequest), "This is already your email")
+	}
+
+	if err := acs.emailChanger.check(user, email); err != nil {
+		return models.User{}, err
+	}
+
+	if err := checkIfMatch(ctx, user); err != nil {
+		return models.User{}, err
+	}
+
+	updated, err := acs.userRepo.UpdateFields(actor, user.Version, map[string]any{"pending_email": email})
+	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile update account", err)
+	}
+
+	if !updated {
+		return models.User{}, errUserModified
+	}
+
+	user.PendingEmail = &email
+	user.UpdatedAt = time.Now()
+	user.Version++
+
+	if err := acs.emailChanger.send(ctx, user); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (acs *accountService) ConfirmEmail(ctx *gin.Context, actor uuid.UUID, code string) (models.User, error) {
+	user, err := acs.findAccount(actor)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return acs.emailChanger.confirm(user, code)
+}
+
+// RevertEmail is reached through the link mailed to the old address, the
+// token is all it has to go on.
+func (acs *accountService) RevertEmail(ctx *gin.Context, token string) error {
+	return acs.emailChanger.revert(token)
+}
+
+// CloseAccount soft deletes the account like DeleteUser does and signs it
+// out. The last owner of an organization has to hand it over first.
+func (acs *accountService) CloseAccount(ctx *gin.Context, actor uuid.UUID, currentPassword string) error {
+	user, err := acs.findAccount(actor)
+	if err != nil {
+		return err
+	}
+
+	if err := acs.checkPassword(actor, currentPassword); err != nil {
+		return err
+	}
+
+	organizations, err := acs.organizationRepo.FindByUser(actor)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load organizations", err)
+	}
+
+	for _, organization := range organizations {
+		member, found, err := acs.organizationRepo.FindMember(organization.ID, actor)
+		if err != nil {
+			return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load membership", err)
+		}
+		if !found || member.Role != models.OrganizationRoleOwner {
+			continue
+		}
+
+		owners, err := acs.organizationRepo.CountMembersWithRole(organization.ID, models.OrganizationRoleOwner)
+		if err != nil {
+			return utils.WrapError(string(utils.ErrCodeInternal), "Unable to count owners", err)
+		}
+
+		if owners <= 1 {
+			return utils.NewError(string(utils.ErrCodeConflict), fmt.Sprintf("Transfer ownership of %s before closing your account", organization.Name))
+		}
+	}
+
+	if err := checkIfMatch(ctx, user); err != nil {
+		return err
+	}
+
+	deleted, err := acs.userRepo.Delete(actor, user.Version)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Faile close account", err)
+	}
+
+	if !deleted {
+		return errUserModified
+	}
+
+	unindexUser(acs.index, user)
+	acs.cache.Clear(permissionCacheKey(actor))
+
+	if err := acs.tokenService.RevokeUserRefreshFamilies(actor); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
+
+	return nil
+}
+
+func (acs *accountService) findAccount(actor uuid.UUID) (models.User, error) {
+	user, err := acs.userRepo.FindBYUUID(actor)
+	if err != nil || user.Email == "" {
+		return models.User{}, utils.NewError(string(utils.ErrCodeNotFound), "Account not found")
+	}
+
+	return user, nil
+}
+
+func (acs *accountService) checkPassword(actor uuid.UUID, password string) error {
+	hash, err := acs.userRepo.FindPassword(actor)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load account", err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return utils.NewError(string(utils.ErrCodeForbidden), "Current password is incorrect")
+	}
+
+	return nil
+}
+
+func organizationIDOf(user models.User) string {
+	if user.OrganizationID == nil {
+		return ""
+	}
+
+	return *user.OrganizationID
+}
@@ -0,0 +1,259 @@
+package v1service
+
+import (
+	"bytes"
+	"log"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/webauthn"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var PasskeyCeremonyTTL = 5 * time.Minute
+
+type passkeyLoginSession struct {
+	Challenge 	string `json:"challenge"`
+	UserUUID 	uuid.UUID `json:"user_uuid"`
+}
+
+func (as *authService) BeginPasskeyRegistration(ctx *gin.Context, userUUID uuid.UUID) (webauthn.CreationOptions, error) {
+	user, err := as.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return webauthn.CreationOptions{}, utils.NewError(string(utils.ErrCodeNotFound), "User not found")
+	}
+
+	credentials, err := as.passkeyRepo.FindByUser(userUUID)
+	if err != nil {
+		return webauthn.CreationOptions{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load passkeys", err)
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return webauthn.CreationOptions{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate challenge")
+	}
+
+	if err := as.cache.Set("webauthn:register:" + userUUID.String(), challenge, PasskeyCeremonyTTL); err != nil {
+		return webauthn.CreationOptions{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to store challenge")
+	}
+
+	userEntity := webauthn.UserEntity{
+		ID: webauthn.EncodeBase64(userUUID[:]),
+		Name: user.Email,
+		DisplayName: user.Name,
+	}
+
+	return as.webauthn.CreationOptions(challenge, userEntity, credentialDescriptors(credentials)), nil
+}
+
+func (as *authService) FinishPasskeyRegistration(ctx *gin.Context, userUUID uuid.UUID, input v1dto.PasskeyRegisterInput) (models.PasskeyCredential, error) {
+	challengeKey := "webauthn:register:" + userUUID.String()
+
+	var challenge string
+	if err := as.cache.Get(challengeKey, &challenge); err != nil || challenge == "" {
+		return models.PasskeyCredential{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Registration expired. Please try again")
+	}
+
+	as.cache.Clear(challengeKey)
+
+	clientDataJSON, err := webauthn.DecodeBase64(input.Response.ClientDataJSON)
+	if err != nil {
+		return models.PasskeyCredential{}, utils.NewError(string(utils.ErrCodeBadRequest), "Invalid client data")
+	}
+
+	attestationObject, err := webauthn.DecodeBase64(input.Response.AttestationObject)
+	if err != nil {
+		return models.PasskeyCredential{}, utils.NewError(string(utils.ErrCodeBadRequest), "Invalid attestation object")
+	}
+
+	credential, err := as.webauthn.VerifyRegistration(clientDataJSON, attestationObject, challenge)
+	if err != nil {
+		return models.PasskeyCredential{}, utils.WrapError(string(utils.ErrCodeUnauthorized), "Passkey registration failed", err)
+	}
+
+	credentialID := webauthn.EncodeBase64(credential.ID)
+	if _, exists, err := as.passkeyRepo.FindByCredentialID(credentialID); err != nil || exists {
+		return models.PasskeyCredential{}, utils.NewError(string(utils.ErrCodeConflict), "Passkey already registered")
+	}
+
+	name := input.Name
+	if name == "" {
+		name = "Passkey"
+	}
+
+	record := models.PasskeyCredential{
+		UserUUID: userUUID,
+		CredentialID: credentialID,
+		PublicKey: credential.PublicKey,
+		SignCount: int64(credential.SignCount),
+		AAGUID: credential.AAGUID,
+		Name: name,
+		BackupEligible: credential.BackupEligible,
+		CreatedAt: time.Now(),
+	}
+
+	if err := as.passkeyRepo.Create(record); err != nil {
+		return models.PasskeyCredential{}, utils.WrapError(string(utils.ErrCodeInternal), "Failed to store passkey", err)
+	}
+
+	return record, nil
+}
+
+func (as *authService) BeginPasskeyLogin(ctx *gin.Context, email string) (v1dto.PasskeyLoginBeginResponse, error) {
+	if err := as.CheckLoginAttempt(as.getClientIP(ctx)); err != nil {
+		return v1dto.PasskeyLoginBeginResponse{}, err
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return v1dto.PasskeyLoginBeginResponse{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate challenge")
+	}
+
+	session := passkeyLoginSession{
+		Challenge: challenge,
+	}
+
+	// Without an email the browser offers discoverable credentials, with one we
+	// narrow the prompt to that account. Unknown emails get the same response
+	// shape so the endpoint cannot be used to probe for accounts.
+	allow := []webauthn.CredentialDescriptor{}
+	if email != "" {
+		user, err := emailLookup(as.userRepo, ctx.GetHeader(OrganizationHeader)).FindByEmail(utils.NormailizeString(email))
+		if err == nil && user.Email != "" {
+			credentials, err := as.passkeyRepo.FindByUser(user.UUID)
+			if err != nil {
+				return v1dto.PasskeyLoginBeginResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load passkeys", err)
+			}
+
+			session.UserUUID = user.UUID
+			allow = credentialDescriptors(credentials)
+		}
+	}
+
+	sessionID, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return v1dto.PasskeyLoginBeginResponse{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate session")
+	}
+
+	if err := as.cache.Set("webauthn:login:" + sessionID, session, PasskeyCeremonyTTL); err != nil {
+		return v1dto.PasskeyLoginBeginResponse{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to store challenge")
+	}
+
+	return v1dto.PasskeyLoginBeginResponse{
+		SessionID: sessionID,
+		Options: as.webauthn.RequestOptions(challenge, allow),
+	}, nil
+}
+
+func (as *authService) FinishPasskeyLogin(ctx *gin.Context, input v1dto.PasskeyLoginInput) (v1dto.LoginResponse, error) {
+	ip := as.getClientIP(ctx)
+	sessionKey := "webauthn:login:" + input.SessionID
+
+	var session passkeyLoginSession
+	if err := as.cache.Get(sessionKey, &session); err != nil || session.Challenge == "" {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Login session expired. Please try again")
+	}
+
+	as.cache.Clear(sessionKey)
+
+	rawID, err := webauthn.DecodeBase64(input.ID)
+	if err != nil {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid passkey")
+	}
+
+	credential, found, err := as.passkeyRepo.FindByCredentialID(webauthn.EncodeBase64(rawID))
+	if err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load passkey", err)
+	}
+
+	if !found || (session.UserUUID != uuid.Nil && session.UserUUID != credential.UserUUID) {
+		as.getLoginAttempt(ip)
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid passkey")
+	}
+
+	if input.Response.UserHandle != "" {
+		userHandle, err := webauthn.DecodeBase64(input.Response.UserHandle)
+		if err != nil || !bytes.Equal(userHandle, credential.UserUUID[:]) {
+			return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid passkey")
+		}
+	}
+
+	clientDataJSON, errClient := webauthn.DecodeBase64(input.Response.ClientDataJSON)
+	authData, errAuth := webauthn.DecodeBase64(input.Response.AuthenticatorData)
+	signature, errSig := webauthn.DecodeBase64(input.Response.Signature)
+	if errClient != nil || errAuth != nil || errSig != nil {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid passkey response")
+	}
+
+	signCount, err := as.webauthn.VerifyAssertion(clientDataJSON, authData, signature, session.Challenge, credential.PublicKey)
+	if err != nil {
+		as.getLoginAttempt(ip)
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeUnauthorized), "Passkey verification failed", err)
+	}
+
+	// Authenticators that keep a counter must always move it forward. A counter
+	// that does not is the signal that the private key was cloned.
+	if (signCount != 0 || credential.SignCount != 0) && int64(signCount) <= credential.SignCount {
+		log.Printf("Passkey clone suspected: user=%s credential=%d stored=%d received=%d ip=%s", credential.UserUUID, credential.ID, credential.SignCount, signCount, ip)
+		if err := as.passkeyRepo.MarkCloneWarning(credential.ID); err != nil {
+			log.Printf("Failed to flag passkey %d:%s", credential.ID, err)
+		}
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Passkey rejected. Please contact support")
+	}
+
+	if err := as.passkeyRepo.UpdateSignCount(credential.ID, int64(signCount)); err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to update passkey", err)
+	}
+
+	user, err := as.userRepo.FindBYUUID(credential.UserUUID)
+	if err != nil || user.Email == "" {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "User not found.")
+	}
+
+	response, err := as.issuer.issueTokens(ctx, user, auth.TokenGrant{})
+	if err != nil {
+		return v1dto.LoginResponse{}, err
+	}
+	as.issuer.recordLogin(user)
+
+	as.CleanupClients(ip)
+
+	return response, nil
+}
+
+func (as *authService) ListPasskeys(ctx *gin.Context, userUUID uuid.UUID) ([]models.PasskeyCredential, error) {
+	credentials, err := as.passkeyRepo.FindByUser(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load passkeys", err)
+	}
+
+	return credentials, nil
+}
+
+func (as *authService) DeletePasskey(ctx *gin.Context, userUUID uuid.UUID, id int64) error {
+	deleted, err := as.passkeyRepo.Delete(userUUID, id)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete passkey", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Passkey not found")
+	}
+
+	return nil
+}
+
+func credentialDescriptors(credentials []models.PasskeyCredential) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		descriptors = append(descriptors, webauthn.CredentialDescriptor{
+			Type: "public-key",
+			ID: credential.CredentialID,
+		})
+	}
+	return descriptors
+}
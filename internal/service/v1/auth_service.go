@@ -14,6 +14,8 @@ import (
 	"github.com/dangLuan01/user-manager/pkg/cache"
 	"github.com/dangLuan01/user-manager/pkg/mail"
 	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/dangLuan01/user-manager/pkg/webauthn"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -35,28 +37,44 @@ type LoginAttempt struct {
 
 type authService struct {
 	userRepo repository.UserRepository
+	twoFactorRepo repository.TwoFactorRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+	passkeyRepo repository.PasskeyRepository
 	tokenService auth.TokenService
 	cache cache.RedisCacheService
 	mailService mail.EmailProviderService
 	rabbitmqService rabbitmq.RabbitMQService
+	twoFactorKey []byte
+	twoFactorIssuer string
+	webauthn *webauthn.WebAuthn
+	issuer *tokenIssuer
+	index search.SearchIndex
 }
 
-func NewAuthService(repo repository.UserRepository, tokenService auth.TokenService, cacheService cache.RedisCacheService, mailService mail.EmailProviderService, rabbitmqService rabbitmq.RabbitMQService) *authService {
+func NewAuthService(repo repository.UserRepository, twoFactorRepo repository.TwoFactorRepository, recoveryCodeRepo repository.RecoveryCodeRepository, passkeyRepo repository.PasskeyRepository, tokenService auth.TokenService, cacheService cache.RedisCacheService, mailService mail.EmailProviderService, rabbitmqService rabbitmq.RabbitMQService, index search.SearchIndex, twoFactorKey []byte) *authService {
 	return &authService{
 		userRepo: repo,
+		twoFactorRepo: twoFactorRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		passkeyRepo: passkeyRepo,
 		tokenService: tokenService,
 		cache: cacheService,
 		mailService: mailService,
 		rabbitmqService: rabbitmqService,
+		index: index,
+		twoFactorKey: twoFactorKey,
+		twoFactorIssuer: utils.GetEnv("TWO_FACTOR_ISSUER", "User Manager"),
+		webauthn: webauthn.New(webauthn.Config{
+			RPID: utils.GetEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName: utils.GetEnv("WEBAUTHN_RP_NAME", "User Manager"),
+			Origins: strings.Split(utils.GetEnv("WEBAUTHN_ORIGINS", "http://localhost:3000"), ","),
+		}),
+		issuer: newTokenIssuer(repo, tokenService, rabbitmqService),
 	}
 }
 
 func (as *authService) getClientIP(ctx *gin.Context) string {
-	ip := ctx.ClientIP()
-	if ip == "" {
-		ip = ctx.Request.RemoteAddr
-	}
-	return ip
+	return getClientIP(ctx)
 }
 
 func (as *authService) getLoginAttempt(ip string) *rate.Limiter {
@@ -98,44 +116,55 @@ func (as *authService) CleanupClients(ip string) {
 	delete(clients, ip)
 }
 
-func (as *authService) Login(ctx *gin.Context, email, password string) (string, string, int, error) {
+func (as *authService) Login(ctx *gin.Context, email, password string) (v1dto.LoginResponse, error) {
 	ip := as.getClientIP(ctx)
 
 	if err := as.CheckLoginAttempt(ip); err != nil {
-		return "", "", 0, err
+		return v1dto.LoginResponse{}, err
 	}
 
 	email = utils.NormailizeString(email)
-	user, err := as.userRepo.FindByEmail(email)
+	user, err := emailLookup(as.userRepo, ctx.GetHeader(OrganizationHeader)).FindByEmail(email)
 
 	if err != nil {
 		as.getLoginAttempt(ip)
-		return "", "", 0, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid email or password")
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid email or password")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		as.getLoginAttempt(ip)
-		return "", "", 0, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid email or password")
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid email or password")
 	}
 
-	accessToken, err := as.tokenService.GenerateAccessToken(user)
+	twoFactor, found, err := as.twoFactorRepo.FindByUserUUID(user.UUID)
 	if err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create access token", err)
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to check two-factor status", err)
 	}
 
+	if found && twoFactor.Enabled {
+		challengeToken, err := as.createMFAChallenge(user)
+		if err != nil {
+			return v1dto.LoginResponse{}, err
+		}
 
-	refreshToken, err := as.tokenService.GenerateRefreshToken(user)
-	if err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create refresh token", err)
+		as.CleanupClients(ip)
+
+		return v1dto.LoginResponse{
+			MFARequired: true,
+			ChallengeToken: challengeToken,
+			ExpiresIn: int(MFAChallengeTTL.Seconds()),
+		}, nil
 	}
 
-	if err := as.tokenService.StoreRefreshToken(refreshToken); err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot save refresh token", err)
+	response, err := as.issuer.issueTokens(ctx, user, auth.TokenGrant{})
+	if err != nil {
+		return v1dto.LoginResponse{}, err
 	}
+	as.issuer.recordLogin(user)
 
 	as.CleanupClients(ip) 
 	
-	return  accessToken, refreshToken.Token, int(auth.AccessTokenTTL.Seconds()), nil
+	return response, nil
 }
 
 func (as *authService) Logout(ctx *gin.Context, refreshTokenString string) error {
@@ -153,58 +182,24 @@ func (as *authService) Logout(ctx *gin.Context, refreshTokenString string) error
 		return utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid access token")
 	}
 
-	if jti, ok := claims["jti"].(string); ok {
-		expUnix := claims["exp"].(float64)
-		exp := time.Unix(int64(expUnix), 0)
-		key := "blacklist:" + jti
-		ttl := time.Until(exp)
-		as.cache.Set(key,"revoked", ttl)
-	}
+	as.issuer.revokeAccessToken(claims)
 
 	token, err := as.tokenService.ValidaRefreshToken(refreshTokenString)
 	if err != nil {
 		return utils.NewError(string(utils.ErrCodeUnauthorized),"Refresh token is invalid or revoked.")
 	}
 
-	if err := as.tokenService.RevokeRefreshToken(token.Token); err != nil {
-		return utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot to revoke refresh token", err)
-	}
-
-	return  nil
+	return as.issuer.revokeRefreshToken(token)
 	
 }
 
 func (as *authService) RefreshToken(ctx *gin.Context, refreshTokenString string) (string, string, int, error) {
-
-	token, err := as.tokenService.ValidaRefreshToken(refreshTokenString)
-	if err != nil {
-		return "","", 0, utils.NewError(string(utils.ErrCodeUnauthorized),"Refresh token is invalid or revoked.")
-	}
-
-	user, err := as.userRepo.FindBYUUID(token.UserUUID)
-	if err != nil {
-		return "","", 0, utils.NewError(string(utils.ErrCodeUnauthorized),"User not found.")
-	}
-
-	accessToken, err := as.tokenService.GenerateAccessToken(user)
-	if err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create access token", err)
-	}
-
-	refreshToken, err := as.tokenService.GenerateRefreshToken(user)
+	response, _, err := as.issuer.rotate(ctx, refreshTokenString, "")
 	if err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create refresh token", err)
-	}
-
-	if err := as.tokenService.RevokeRefreshToken(refreshTokenString); err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot to revoke refresh token", err)
-	}
-
-	if err := as.tokenService.StoreRefreshToken(refreshToken); err != nil {
-		return "", "", 0, utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot save refresh token", err)
+		return "", "", 0, err
 	}
 
-	return  accessToken, refreshToken.Token, int(auth.AccessTokenTTL.Seconds()), nil
+	return  response.AccessToken, response.RefreshToken, response.ExpiresIn, nil
 }
 
 func (as *authService) RequestForgotPassword(ctx *gin.Context, email string) (string, error) {
@@ -216,7 +211,7 @@ func (as *authService) RequestForgotPassword(ctx *gin.Context, email string) (st
 	}
 
 	email = utils.NormailizeString(email)
-	user, err := as.userRepo.FindByEmail(email)
+	user, err := emailLookup(as.userRepo, ctx.GetHeader(OrganizationHeader)).FindByEmail(email)
 
 	if err != nil || user.Email == "" {
 		return "", utils.NewError(string(utils.ErrCodeNotFound), "Email not found")
@@ -237,7 +232,7 @@ func (as *authService) RequestForgotPassword(ctx *gin.Context, email string) (st
 		return "", utils.NewError(string(utils.ErrCodeInternal), "Failed to store rate limit reset password")
 	}
 
-	resetLink := fmt.Sprintf("https://yourdomain.com/reset-password?token=%s", token)
+	resetLink := fmt.Sprintf("%s?token=%s", utils.GetEnv("PASSWORD_RESET_URL", "https://yourdomain.com/reset-password"), token)
 	mailContent := &mail.Email{
 		To: []mail.Address{
 			{Email: email},
@@ -294,6 +289,10 @@ func (as *authService)RequestResetPassword(ctx *gin.Context, token, password str
 		return utils.NewError(string(utils.ErrCodeInternal), "Unable update new password")
 	}
 
+	if err := as.tokenService.RevokeUserRefreshFamilies(userUUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
+
 	if err := as.cache.Clear("reset:" + token); err != nil {
 		return utils.NewError(string(utils.ErrCodeInternal), "Failed to revoked token")
 	}
@@ -309,8 +308,9 @@ func (as *authService) Register(ctx *gin.Context, input v1dto.RegisterInput) err
 		return utils.NewError(string(utils.ErrCodeTooManyRequest), "Wait before requesting anorther code")
 	}
 
+	// Self-registered accounts belong to no organization.
 	email := utils.NormailizeString(input.Email)
-	user, err := as.userRepo.FindByEmail(email)
+	user, err := emailLookup(as.userRepo, "").FindByEmail(email)
 	if err != nil || user.Email != "" {
 		return utils.NewError(string(utils.ErrCodeConflict), "Email existsing!")
 	}	
@@ -365,9 +365,13 @@ func (as *authService) RegisterOTP(ctx *gin.Context, code string) error {
 
 	uuidUser := uuid.New()
 	userModel := v1dto.RegisterDTOToModel(uuidUser, user)
+	// The code only reached the user through their inbox.
+	verifiedAt := time.Now()
+	userModel.EmailVerifiedAt = &verifiedAt
 	if err := as.userRepo.Create(userModel); err != nil {
 		return utils.WrapError(string(utils.ErrCodeInternal), "Failed to store user.", err)
 	}
+	indexUser(as.index, userModel)
 
 	if err := as.cache.Clear(codeKey); err != nil {
 		return utils.NewError(string(utils.ErrCodeInternal), "Unable error clear otp.")
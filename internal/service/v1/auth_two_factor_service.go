@@ -0,0 +1,350 @@
+package v1service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/mail"
+	"github.com/dangLuan01/user-manager/pkg/totp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	MFAChallengeTTL = 5 * time.Minute
+	MaxMFAAttempt = 5
+	RecoveryCodeCount = 10
+)
+
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// TwoFactorKey returns the AES-256 key TOTP secrets are encrypted with. There
+// is no default, a published key would expose every secret.
+func TwoFactorKey() ([]byte, error) {
+	key := utils.GetEnv("TWO_FACTOR_ENCRYPT_KEY", "")
+	if len(key) != 32 {
+		return nil, fmt.Errorf("TWO_FACTOR_ENCRYPT_KEY must be 32 bytes, got %d", len(key))
+	}
+
+	return []byte(key), nil
+}
+
+type mfaChallenge struct {
+	UserUUID 	uuid.UUID `json:"user_uuid"`
+	Attempts 	int `json:"attempts"`
+	ExpiresAt 	time.Time `json:"expires_at"`
+}
+
+func (as *authService) createMFAChallenge(user models.User) (string, error) {
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", utils.NewError(string(utils.ErrCodeInternal), "Failed to generate challenge token")
+	}
+
+	challenge := mfaChallenge{
+		UserUUID: user.UUID,
+		ExpiresAt: time.Now().Add(MFAChallengeTTL),
+	}
+
+	if err := as.cache.Set("mfa:challenge:" + token, challenge, MFAChallengeTTL); err != nil {
+		return "", utils.NewError(string(utils.ErrCodeInternal), "Failed to store challenge token")
+	}
+
+	return token, nil
+}
+
+func (as *authService) SetupTwoFactor(ctx *gin.Context, userUUID uuid.UUID) (v1dto.TwoFactorSetupResponse, error) {
+	user, err := as.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return v1dto.TwoFactorSetupResponse{}, utils.NewError(string(utils.ErrCodeNotFound), "User not found")
+	}
+
+	twoFactor, found, err := as.twoFactorRepo.FindByUserUUID(userUUID)
+	if err != nil {
+		return v1dto.TwoFactorSetupResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to check two-factor status", err)
+	}
+
+	if found && twoFactor.Enabled {
+		return v1dto.TwoFactorSetupResponse{}, utils.NewError(string(utils.ErrCodeConflict), "Two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return v1dto.TwoFactorSetupResponse{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate two-factor secret")
+	}
+
+	encrypted, err := utils.EncrytAES([]byte(secret), as.twoFactorKey)
+	if err != nil {
+		return v1dto.TwoFactorSetupResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Failed to encrypt two-factor secret", err)
+	}
+
+	if err := as.twoFactorRepo.Save(models.TwoFactor{
+		UserUUID: userUUID,
+		Secret: encrypted,
+		Enabled: false,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return v1dto.TwoFactorSetupResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Failed to store two-factor secret", err)
+	}
+
+	return v1dto.TwoFactorSetupResponse{
+		Secret: secret,
+		URI: totp.KeyURI(as.twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+func (as *authService) ConfirmTwoFactor(ctx *gin.Context, userUUID uuid.UUID, code string) ([]string, error) {
+	twoFactor, found, err := as.twoFactorRepo.FindByUserUUID(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to check two-factor status", err)
+	}
+
+	if !found {
+		return nil, utils.NewError(string(utils.ErrCodeNotFound), "Two-factor setup not started")
+	}
+
+	if twoFactor.Enabled {
+		return nil, utils.NewError(string(utils.ErrCodeConflict), "Two-factor authentication is already enabled")
+	}
+
+	if err := as.checkTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	// Store the recovery codes first, two-factor must never be on without them.
+	codes, err := as.generateRecoveryCodes(userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := as.twoFactorRepo.Enable(userUUID); err != nil {
+		if err := as.recoveryCodeRepo.DeleteByUser(userUUID); err != nil {
+			log.Printf("Failed to delete recovery codes for user %s:%s", userUUID, err)
+		}
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Failed to enable two-factor authentication", err)
+	}
+
+	return codes, nil
+}
+
+func (as *authService) RegenerateRecoveryCodes(ctx *gin.Context, userUUID uuid.UUID, code string) ([]string, error) {
+	twoFactor, found, err := as.twoFactorRepo.FindByUserUUID(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to check two-factor status", err)
+	}
+
+	if !found || !twoFactor.Enabled {
+		return nil, utils.NewError(string(utils.ErrCodeNotFound), "Two-factor authentication is not enabled")
+	}
+
+	if err := as.checkTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	return as.generateRecoveryCodes(userUUID)
+}
+
+func (as *authService) generateRecoveryCodes(userUUID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, RecoveryCodeCount)
+	now := time.Now()
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate recovery codes")
+		}
+
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{
+			UserUUID: userUUID,
+			CodeHash: hashRecoveryCode(code),
+			CreatedAt: now,
+		})
+	}
+
+	if err := as.recoveryCodeRepo.ReplaceForUser(userUUID, records); err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Failed to store recovery codes", err)
+	}
+
+	return codes, nil
+}
+
+func (as *authService) DisableTwoFactor(ctx *gin.Context, userUUID uuid.UUID, code string) error {
+	twoFactor, found, err := as.twoFactorRepo.FindByUserUUID(userUUID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to check two-factor status", err)
+	}
+
+	if !found || !twoFactor.Enabled {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Two-factor authentication is not enabled")
+	}
+
+	if err := as.checkTOTP(twoFactor, code); err != nil {
+		return err
+	}
+
+	if err := as.twoFactorRepo.Delete(userUUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Failed to disable two-factor authentication", err)
+	}
+
+	if err := as.recoveryCodeRepo.DeleteByUser(userUUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Failed to delete recovery codes", err)
+	}
+
+	return nil
+}
+
+func (as *authService) VerifyTwoFactor(ctx *gin.Context, challengeToken, code, recoveryCode string) (v1dto.LoginResponse, error) {
+	challengeKey := "mfa:challenge:" + challengeToken
+
+	var challenge mfaChallenge
+	if err := as.cache.Get(challengeKey, &challenge); err != nil || challenge.UserUUID == uuid.Nil {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Challenge token is invalid or expired.")
+	}
+
+	twoFactor, found, err := as.twoFactorRepo.FindByUserUUID(challenge.UserUUID)
+	if err != nil || !found || !twoFactor.Enabled {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Two-factor authentication is not enabled")
+	}
+
+	var checkErr error
+	if recoveryCode != "" {
+		checkErr = as.useRecoveryCode(ctx, challenge.UserUUID, recoveryCode)
+	} else {
+		checkErr = as.checkTOTP(twoFactor, code)
+	}
+
+	if checkErr != nil {
+		challenge.Attempts++
+		if challenge.Attempts >= MaxMFAAttempt {
+			as.cache.Clear(challengeKey)
+			return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeTooManyRequest), "Too many invalid codes. Please login again")
+		}
+
+		as.cache.Set(challengeKey, challenge, time.Until(challenge.ExpiresAt))
+		return v1dto.LoginResponse{}, checkErr
+	}
+
+	user, err := as.userRepo.FindBYUUID(challenge.UserUUID)
+	if err != nil || user.Email == "" {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "User not found.")
+	}
+
+	if err := as.cache.Clear(challengeKey); err != nil {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to revoke challenge token")
+	}
+
+	response, err := as.issuer.issueTokens(ctx, user, auth.TokenGrant{})
+	if err != nil {
+		return v1dto.LoginResponse{}, err
+	}
+	as.issuer.recordLogin(user)
+
+	return response, nil
+}
+
+func (as *authService) checkTOTP(twoFactor models.TwoFactor, code string) error {
+	secret, err := utils.DecrytAES(twoFactor.Secret, as.twoFactorKey)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Cannot decode two-factor secret", err)
+	}
+
+	step, ok := totp.ValidateStep(code, string(secret), time.Now())
+	if !ok {
+		return utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid two-factor code")
+	}
+
+	// A code stays valid for the whole skew window, so claim its step to block
+	// replays. The claim is one SETNX, two requests racing with the same code
+	// cannot both get it.
+	usedKey := fmt.Sprintf("mfa:used:%s:%d", twoFactor.UserUUID, step)
+	claimed, err := as.cache.SetNX(usedKey, "1", time.Duration(2 * totp.Skew + 1) * totp.Period)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to check two-factor code", err)
+	}
+
+	if !claimed {
+		return utils.NewError(string(utils.ErrCodeUnauthorized), "Two-factor code already used")
+	}
+
+	return nil
+}
+
+func (as *authService) useRecoveryCode(ctx *gin.Context, userUUID uuid.UUID, code string) error {
+	record, found, err := as.recoveryCodeRepo.FindUnusedByHash(userUUID, hashRecoveryCode(code))
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to check recovery code", err)
+	}
+
+	if !found {
+		return utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid recovery code")
+	}
+
+	ip := as.getClientIP(ctx)
+	used, err := as.recoveryCodeRepo.MarkUsed(record.ID, ip)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to use recovery code", err)
+	}
+
+	if !used {
+		return utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid recovery code")
+	}
+
+	remaining, err := as.recoveryCodeRepo.CountUnused(userUUID)
+	if err != nil {
+		log.Printf("Failed to count recovery codes for user %s:%s", userUUID, err)
+	}
+
+	log.Printf("Recovery code used: user=%s ip=%s remaining=%d", userUUID, ip, remaining)
+
+	user, err := as.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return nil
+	}
+
+	mailContent := &mail.Email{
+		To: []mail.Address{
+			{Email: user.Email},
+		},
+		Subject: "A recovery code was used to sign in",
+		Text: fmt.Sprintf("Hi %s,\n\nA recovery code was used to sign in to your account from IP %s at %s.\nYou have %d recovery codes left.\n\nIf this was not you, reset your password and regenerate your recovery codes immediately.", user.Name, ip, time.Now().Format(time.RFC1123), remaining),
+	}
+
+	if err := as.rabbitmqService.Publish(ctx, "auth_email_queue", mailContent); err != nil {
+		log.Printf("Failed to send recovery code notification for user %s:%s", userUUID, err)
+	}
+
+	return nil
+}
+
+func generateRecoveryCode() (string, error) {
+	code := make([]byte, 10)
+	for i := range code {
+		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = recoveryCodeAlphabet[num.Int64()]
+	}
+
+	return fmt.Sprintf("%s-%s", code[:5], code[5:]), nil
+}
+
+// Recovery codes are high-entropy random values, so a plain SHA-256 digest is enough
+// and lets us look a code up by hash instead of comparing against every row.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ReplaceAll(utils.NormailizeString(code), "-", "")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,279 @@
+package v1service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/mail"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	EmailChangeCodeTTL = 10 * time.Minute
+	EmailChangeRevertTTL = 7 * 24 * time.Hour
+	MaxEmailChangeAttempt = 5
+)
+
+type emailChangeCode struct {
+	Email 		string `json:"email"`
+	Code 		string `json:"code"`
+	Attempts 	int `json:"attempts"`
+}
+
+type emailChangeRevert struct {
+	UserUUID 	uuid.UUID `json:"user_uuid"`
+	OldEmail 	string `json:"old_email"`
+	NewEmail 	string `json:"new_email"`
+}
+
+// emailChanger moves a user to a new address in two steps. The new address is
+// parked in pending_email and only replaces the current one once the code sent
+// to it comes back, while the old address gets a link to undo the change.
+type emailChanger struct {
+	userRepo repository.UserRepository
+	tokenService auth.TokenService
+	cache cache.RedisCacheService
+	rabbitmqService rabbitmq.RabbitMQService
+	index search.SearchIndex
+}
+
+func newEmailChanger(userRepo repository.UserRepository, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService, index search.SearchIndex) *emailChanger {
+	return &emailChanger{
+		userRepo: userRepo,
+		tokenService: tokenService,
+		cache: cacheService,
+		rabbitmqService: rabbitmqService,
+		index: index,
+	}
+}
+
+func emailChangeCodeKey(userUUID uuid.UUID) string {
+	return "email:change:" + userUUID.String()
+}
+
+func emailChangeRateLimitKey(userUUID uuid.UUID) string {
+	return "email:change:ratelimit:" + userUUID.String()
+}
+
+func emailChangeRevertKey(token string) string {
+	return "email:revert:" + token
+}
+
+// check runs before pending_email is written so a request that cannot send its
+// codes leaves the user untouched.
+func (ec *emailChanger) check(user models.User, email string) error {
+	if exists, err := ec.cache.Exits(emailChangeRateLimitKey(user.UUID)); exists && err == nil {
+		return utils.NewError(string(utils.ErrCodeTooManyRequest), "Wait before requesting anorther code")
+	}
+
+	return ec.checkAvailable(user, email)
+}
+
+func (ec *emailChanger) checkAvailable(user models.User, email string) error {
+	if u, err := emailLookup(ec.userRepo, organizationIDOf(user)).FindByEmail(email); err != nil || (u.Email != "" && u.UUID != user.UUID) {
+		return utils.NewError(
+			string(utils.ErrCodeConflict),
+			fmt.Sprintf("Email: %v already existed.", email),
+		)
+	}
+
+	return nil
+}
+
+// send mails the confirmation code to user.PendingEmail and the revert link to
+// user.Email. A new request replaces the code of the previous one.
+func (ec *emailChanger) send(ctx *gin.Context, user models.User) error {
+	if user.PendingEmail == nil {
+		return nil
+	}
+	email := *user.PendingEmail
+
+	code, err := utils.GenerateRandomInt(6)
+	if err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Unable error generate code.")
+	}
+
+	if err := ec.cache.Set(emailChangeCodeKey(user.UUID), emailChangeCode{
+		Email: email,
+		Code: code,
+	}, EmailChangeCodeTTL); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Unable error store code")
+	}
+
+	if err := ec.cache.Set(emailChangeRateLimitKey(user.UUID), "1", 2 * time.Minute); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to store rate limit code")
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Unable error generate token.")
+	}
+
+	if err := ec.cache.Set(emailChangeRevertKey(token), emailChangeRevert{
+		UserUUID: user.UUID,
+		OldEmail: user.Email,
+		NewEmail: email,
+	}, EmailChangeRevertTTL); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Unable error store token")
+	}
+
+	codeMail := &mail.Email{
+		To: []mail.Address{
+			{Email: email},
+		},
+		Subject: "Confirm your new email",
+		Text: fmt.Sprintf("Hi %s,\n\nUse this code to confirm your new email address:\n%s\n\nThe code expires in %s.", user.Name, code, EmailChangeCodeTTL),
+	}
+
+	if err := ec.rabbitmqService.Publish(ctx, "auth_email_queue", codeMail); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to send email code confirm.")
+	}
+
+	revertLink := fmt.Sprintf("%s?token=%s", utils.GetEnv("EMAIL_REVERT_URL", "https://yourdomain.com/revert-email"), token)
+	revertMail := &mail.Email{
+		To: []mail.Address{
+			{Email: user.Email},
+		},
+		Subject: "Your email is being changed",
+		Text: fmt.Sprintf("Hi %s,\n\nA change of your account email to %s was requested. If this was not you, click the link below to keep this address and sign out every session:\n%s\n\nThe link will expire on %s.", user.Name, email, revertLink, time.Now().Add(EmailChangeRevertTTL).Format(time.RFC1123)),
+	}
+
+	if err := ec.rabbitmqService.Publish(ctx, "auth_email_queue", revertMail); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to send email revert link.")
+	}
+
+	return nil
+}
+
+// confirm switches user to the pending address once code matches and signs
+// the user out everywhere.
+func (ec *emailChanger) confirm(user models.User, code string) (models.User, error) {
+	invalid := utils.NewError(string(utils.ErrCodeBadRequest), "Code invalid or expried.")
+
+	var pending emailChangeCode
+	if err := ec.cache.Get(emailChangeCodeKey(user.UUID), &pending); err != nil || pending.Code == "" {
+		return models.User{}, invalid
+	}
+
+	// A code only confirms the address it was sent to.
+	if user.PendingEmail == nil || *user.PendingEmail != pending.Email {
+		ec.cache.Clear(emailChangeCodeKey(user.UUID))
+		return models.User{}, invalid
+	}
+
+	if pending.Code != code {
+		pending.Attempts++
+		if pending.Attempts >= MaxEmailChangeAttempt {
+			ec.cache.Clear(emailChangeCodeKey(user.UUID))
+			return models.User{}, utils.NewError(string(utils.ErrCodeTooManyRequest), "Too many invalid codes. Please request a new one")
+		}
+
+		ec.cache.Set(emailChangeCodeKey(user.UUID), pending, EmailChangeCodeTTL)
+		return models.User{}, invalid
+	}
+
+	// The address may have been taken while the code was on its way.
+	if err := ec.checkAvailable(user, pending.Email); err != nil {
+		return models.User{}, err
+	}
+
+	now := time.Now()
+	updated, err := ec.userRepo.UpdateFields(user.UUID, user.Version, map[string]any{
+		"email": pending.Email,
+		"pending_email": nil,
+		"email_verified_at": now,
+	})
+	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile change email", err)
+	}
+
+	if !updated {
+		return models.User{}, errUserModified
+	}
+
+	if err := ec.cache.Clear(emailChangeCodeKey(user.UUID)); err != nil {
+		log.Printf("Failed to clear email change code for user %s:%s", user.UUID, err)
+	}
+
+	user.Email = pending.Email
+	user.PendingEmail = nil
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	user.Version++
+	indexUser(ec.index, user)
+
+	if err := ec.tokenService.RevokeUserRefreshFamilies(user.UUID); err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
+
+	return user, nil
+}
+
+// revert undoes the change the token was mailed about. A change still waiting
+// for its code is cancelled, a completed one is rolled back to the old
+// address. Either way every session ends, whoever asked for the change may
+// hold one.
+func (ec *emailChanger) revert(token string) error {
+	invalid := utils.NewError(string(utils.ErrCodeBadRequest), "Link invalid or expried.")
+
+	var revert emailChangeRevert
+	if err := ec.cache.Get(emailChangeRevertKey(token), &revert); err != nil || revert.OldEmail == "" {
+		return invalid
+	}
+
+	user, err := ec.userRepo.FindBYUUID(revert.UserUUID)
+	if err != nil || user.Email == "" {
+		return invalid
+	}
+
+	now := time.Now()
+	fields := map[string]any{
+		"pending_email": nil,
+	}
+
+	switch {
+	case user.Email == revert.OldEmail && user.PendingEmail != nil:
+	case user.Email == revert.NewEmail:
+		if err := ec.checkAvailable(user, revert.OldEmail); err != nil {
+			return err
+		}
+		// Following the link proves the old address is still the user's.
+		fields["email"] = revert.OldEmail
+		fields["email_verified_at"] = now
+	default:
+		ec.cache.Clear(emailChangeRevertKey(token))
+		return invalid
+	}
+
+	updated, err := ec.userRepo.UpdateFields(user.UUID, user.Version, fields)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Faile revert email", err)
+	}
+
+	if !updated {
+		return errUserModified
+	}
+
+	ec.cache.Clear(emailChangeCodeKey(user.UUID))
+	ec.cache.Clear(emailChangeRevertKey(token))
+
+	if email, ok := fields["email"].(string); ok {
+		user.Email = email
+		indexUser(ec.index, user)
+	}
+
+	if err := ec.tokenService.RevokeUserRefreshFamilies(user.UUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
+
+	return nil
+}
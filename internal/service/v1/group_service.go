@@ -0,0 +1,526 @@
+package v1service
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type groupService struct {
+	repo repository.GroupRepository
+	userRepo repository.UserRepository
+	rbacRepo repository.RBACRepository
+	rbac RBACService
+	cache cache.RedisCacheService
+}
+
+func NewGroupService(repo repository.GroupRepository, userRepo repository.UserRepository, rbacRepo repository.RBACRepository, rbac RBACService, cacheService cache.RedisCacheService) GroupService {
+	return &groupService{
+		repo: repo,
+		userRepo: userRepo,
+		rbacRepo: rbacRepo,
+		rbac: rbac,
+		cache: cacheService,
+	}
+}
+
+func (gs *groupService) ListGroups(ctx *gin.Context) ([]models.Group, error) {
+	groups, err := gs.repo.FindAll()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load groups", err)
+	}
+
+	return groups, nil
+}
+
+func (gs *groupService) GetGroup(ctx *gin.Context, id int64) (models.Group, error) {
+	return gs.findGroup(id)
+}
+
+func (gs *groupService) CreateGroup(ctx *gin.Context, group models.Group) (models.Group, error) {
+	if _, found, err := gs.repo.FindByName(group.Name); err != nil || found {
+		return models.Group{}, utils.NewError(string(utils.ErrCodeConflict), "Group name already exists")
+	}
+
+	group.CreatedAt = time.Now()
+	group.UpdatedAt = time.Now()
+
+	group, err := gs.repo.Create(group)
+	if err != nil {
+		return models.Group{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to create group", err)
+	}
+
+	return group, nil
+}
+
+func (gs *groupService) UpdateGroup(ctx *gin.Context, id int64, group models.Group) (models.Group, error) {
+	if existing, found, err := gs.repo.FindByName(group.Name); err != nil || (found && existing.ID != id) {
+		return models.Group{}, utils.NewError(string(utils.ErrCodeConflict), "Group name already exists")
+	}
+
+	group.ID = id
+	updated, err := gs.repo.Update(group)
+	if err != nil {
+		return models.Group{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to update group", err)
+	}
+
+	if !updated {
+		return models.Group{}, utils.NewError(string(utils.ErrCodeNotFound), "Group not found")
+	}
+
+	return gs.findGroup(id)
+}
+
+func (gs *groupService) DeleteGroup(ctx *gin.Context, id int64) error {
+	// Collected first, the memberships are gone once the group is.
+	members, err := gs.EffectiveMembers(id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := gs.repo.Delete(id)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete group", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Group not found")
+	}
+
+	gs.invalidatePermissions(members...)
+
+	return nil
+}
+
+// ListMembers returns the users and groups placed directly in the group, or
+// with effective set every user reached through nested groups.
+func (gs *groupService) ListMembers(ctx *gin.Context, id int64, effective bool) ([]uuid.UUID, []models.Group, error) {
+	if _, err := gs.findGroup(id); err != nil {
+		return nil, nil, err
+	}
+
+	if effective {
+		users, err := gs.EffectiveMembers(id)
+		return users, nil, err
+	}
+
+	users, err := gs.repo.FindUserUUIDs([]int64{id})
+	if err != nil {
+		return nil, nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group users", err)
+	}
+
+	edges, err := gs.repo.FindEdges()
+	if err != nil {
+		return nil, nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group children", err)
+	}
+
+	childIDs := make([]int64, 0)
+	for _, edge := range edges {
+		if edge.GroupID == id {
+			childIDs = append(childIDs, edge.ChildGroupID)
+		}
+	}
+
+	children, err := gs.repo.FindByIDs(childIDs)
+	if err != nil {
+		return nil, nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group children", err)
+	}
+
+	return users, children, nil
+}
+
+func (gs *groupService) AddUser(ctx *gin.Context, id int64, userUUID uuid.UUID) error {
+	if _, err := gs.findGroup(id); err != nil {
+		return err
+	}
+
+	if err := gs.checkGrant(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := gs.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return utils.NewError(string(utils.ErrCodeNotFound), "User not found")
+	}
+
+	if err := gs.repo.AddUser(id, userUUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to add user to group", err)
+	}
+
+	gs.invalidatePermissions(userUUID)
+
+	return nil
+}
+
+func (gs *groupService) RemoveUser(ctx *gin.Context, id int64, userUUID uuid.UUID) error {
+	removed, err := gs.repo.RemoveUser(id, userUUID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to remove user from group", err)
+	}
+
+	if !removed {
+		return utils.NewError(string(utils.ErrCodeNotFound), "User is not in group")
+	}
+
+	gs.invalidatePermissions(userUUID)
+
+	return nil
+}
+
+// AddChildGroup nests childID inside id. A group may not end up containing
+// itself, however deep the path.
+func (gs *groupService) AddChildGroup(ctx *gin.Context, id, childID int64) error {
+	if _, err := gs.findGroup(id); err != nil {
+		return err
+	}
+
+	if _, err := gs.findGroup(childID); err != nil {
+		return err
+	}
+
+	edges, err := gs.repo.FindEdges()
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group children", err)
+	}
+
+	if id == childID || slices.Contains(descendantGroupIDs(edges, childID), id) {
+		return utils.NewError(string(utils.ErrCodeConflict), "Nesting the group would create a cycle")
+	}
+
+	if err := gs.checkGrant(ctx, id); err != nil {
+		return err
+	}
+
+	if err := gs.repo.AddChild(id, childID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to nest group", err)
+	}
+
+	members, err := gs.EffectiveMembers(childID)
+	if err != nil {
+		return err
+	}
+
+	gs.invalidatePermissions(members...)
+
+	return nil
+}
+
+func (gs *groupService) RemoveChildGroup(ctx *gin.Context, id, childID int64) error {
+	removed, err := gs.repo.RemoveChild(id, childID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to remove nested group", err)
+	}
+
+	if !removed {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Group is not nested in group")
+	}
+
+	members, err := gs.EffectiveMembers(childID)
+	if err != nil {
+		return err
+	}
+
+	gs.invalidatePermissions(members...)
+
+	return nil
+}
+
+func (gs *groupService) ListGroupRoles(ctx *gin.Context, id int64) ([]models.Role, error) {
+	if _, err := gs.findGroup(id); err != nil {
+		return nil, err
+	}
+
+	roles, err := gs.repo.FindRoles(id)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group roles", err)
+	}
+
+	return roles, nil
+}
+
+func (gs *groupService) AssignGroupRole(ctx *gin.Context, id, roleID int64) error {
+	if _, err := gs.findGroup(id); err != nil {
+		return err
+	}
+
+	if _, found, err := gs.rbacRepo.FindRoleByID(roleID); err != nil || !found {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Role not found")
+	}
+
+	if err := gs.repo.AssignRole(id, roleID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to assign role", err)
+	}
+
+	return gs.invalidateGroup(id)
+}
+
+func (gs *groupService) RemoveGroupRole(ctx *gin.Context, id, roleID int64) error {
+	removed, err := gs.repo.RemoveRole(id, roleID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to remove role", err)
+	}
+
+	if !removed {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Role is not assigned to group")
+	}
+
+	return gs.invalidateGroup(id)
+}
+
+func (gs *groupService) ListGroupPermissions(ctx *gin.Context, id int64) ([]models.Permission, error) {
+	if _, err := gs.findGroup(id); err != nil {
+		return nil, err
+	}
+
+	permissions, err := gs.repo.FindPermissions(id)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group permissions", err)
+	}
+
+	return permissions, nil
+}
+
+func (gs *groupService) SetGroupPermissions(ctx *gin.Context, id int64, names []string) error {
+	if _, err := gs.findGroup(id); err != nil {
+		return err
+	}
+
+	permissions, err := gs.rbacRepo.FindPermissionsByNames(names)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+	}
+
+	ids := make([]int64, 0, len(permissions))
+	for _, permission := range permissions {
+		ids = append(ids, permission.ID)
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(permissions, func(permission models.Permission) bool { return permission.Name == name }) {
+			return utils.NewError(string(utils.ErrCodeBadRequest), "Unknown permission " + name)
+		}
+	}
+
+	if err := gs.repo.ReplacePermissions(id, ids); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to store group permissions", err)
+	}
+
+	return gs.invalidateGroup(id)
+}
+
+// UserGroups lists the groups the user was placed in, with effective set also
+// every group above them.
+func (gs *groupService) UserGroups(ctx *gin.Context, userUUID uuid.UUID, effective bool) ([]models.Group, error) {
+	groupIDs, err := gs.repo.FindUserGroupIDs(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load user groups", err)
+	}
+
+	if effective {
+		groupIDs, err = gs.EffectiveGroupIDs(userUUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groups, err := gs.repo.FindByIDs(groupIDs)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load groups", err)
+	}
+
+	return groups, nil
+}
+
+func (gs *groupService) EffectiveGroupIDs(userUUID uuid.UUID) ([]int64, error) {
+	groupIDs, err := effectiveGroupIDs(gs.repo, userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to resolve user groups", err)
+	}
+
+	return groupIDs, nil
+}
+
+func (gs *groupService) EffectiveMembers(id int64) ([]uuid.UUID, error) {
+	edges, err := gs.repo.FindEdges()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group children", err)
+	}
+
+	users, err := gs.repo.FindUserUUIDs(append(descendantGroupIDs(edges, id), id))
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group users", err)
+	}
+
+	return users, nil
+}
+
+// IsMember reports whether the user belongs to the named group, directly or
+// through a nested group. Unknown groups have no members.
+func (gs *groupService) IsMember(userUUID uuid.UUID, name string) (bool, error) {
+	group, found, err := gs.repo.FindByName(name)
+	if err != nil {
+		return false, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group", err)
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	groupIDs, err := gs.EffectiveGroupIDs(userUUID)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(groupIDs, group.ID), nil
+}
+
+func (gs *groupService) findGroup(id int64) (models.Group, error) {
+	group, found, err := gs.repo.FindByID(id)
+	if err != nil {
+		return models.Group{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group", err)
+	}
+
+	if !found {
+		return models.Group{}, utils.NewError(string(utils.ErrCodeNotFound), "Group not found")
+	}
+
+	return group, nil
+}
+
+// invalidateGroup drops the cached permissions of everyone the group's grants reach.
+func (gs *groupService) invalidateGroup(id int64) error {
+	members, err := gs.EffectiveMembers(id)
+	if err != nil {
+		return err
+	}
+
+	gs.invalidatePermissions(members...)
+
+	return nil
+}
+
+func (gs *groupService) invalidatePermissions(userUUIDs ...uuid.UUID) {
+	for _, userUUID := range userUUIDs {
+		gs.cache.Clear(permissionCacheKey(userUUID))
+	}
+}
+
+// checkGrant keeps groups:write from raising anyone above the caller. A new
+// member of id, or of a group nested in it, inherits the permissions of id and
+// every group above it, so the caller must hold roles:write or all of those.
+func (gs *groupService) checkGrant(ctx *gin.Context, id int64) error {
+	var held []string
+	if _, ok := auth.GetServicePayload(ctx); ok {
+		held, _ = auth.GetScopes(ctx)
+	} else {
+		payload, ok := auth.GetPayload(ctx)
+		if !ok {
+			return utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized")
+		}
+
+		permissions, err := gs.rbac.UserPermissions(payload.UserUUID)
+		if err != nil {
+			return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+		}
+		held = permissions
+	}
+
+	if slices.Contains(held, "roles:write") {
+		return nil
+	}
+
+	edges, err := gs.repo.FindEdges()
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group children", err)
+	}
+
+	parents := make(map[int64][]int64)
+	for _, edge := range edges {
+		parents[edge.ChildGroupID] = append(parents[edge.ChildGroupID], edge.GroupID)
+	}
+
+	granted, err := gs.repo.FindPermissionNames(walkGroups(parents, id))
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group permissions", err)
+	}
+
+	missing := make([]string, 0)
+	for _, permission := range granted {
+		if !slices.Contains(held, permission) && !slices.Contains(missing, permission) {
+			missing = append(missing, permission)
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return utils.NewError(string(utils.ErrCodeForbidden), "Not allowed to grant " + strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// effectiveGroupIDs returns the groups the user is in directly together with
+// every group that contains one of them.
+func effectiveGroupIDs(repo repository.GroupRepository, userUUID uuid.UUID) ([]int64, error) {
+	direct, err := repo.FindUserGroupIDs(userUUID)
+	if err != nil || len(direct) == 0 {
+		return direct, err
+	}
+
+	edges, err := repo.FindEdges()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[int64][]int64)
+	for _, edge := range edges {
+		parents[edge.ChildGroupID] = append(parents[edge.ChildGroupID], edge.GroupID)
+	}
+
+	return walkGroups(parents, direct...), nil
+}
+
+// descendantGroupIDs returns every group nested below id, not id itself.
+func descendantGroupIDs(edges []models.GroupChild, id int64) []int64 {
+	children := make(map[int64][]int64)
+	for _, edge := range edges {
+		children[edge.GroupID] = append(children[edge.GroupID], edge.ChildGroupID)
+	}
+
+	return slices.DeleteFunc(walkGroups(children, children[id]...), func(groupID int64) bool {
+		return groupID == id
+	})
+}
+
+// walkGroups collects start and everything reachable from it through next.
+// Visited groups are skipped, so a cycle that slipped into the table cannot
+// loop forever.
+func walkGroups(next map[int64][]int64, start ...int64) []int64 {
+	seen := make(map[int64]bool)
+	queue := slices.Clone(start)
+	result := make([]int64, 0, len(start))
+
+	for len(queue) > 0 {
+		groupID := queue[0]
+		queue = queue[1:]
+
+		if seen[groupID] {
+			continue
+		}
+
+		seen[groupID] = true
+		result = append(result, groupID)
+		queue = append(queue, next[groupID]...)
+	}
+
+	slices.Sort(result)
+	return result
+}
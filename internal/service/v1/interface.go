@@ -3,24 +3,147 @@ package v1service
 import (
 	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
 	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/webauthn"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type UserService interface {
-	GetAllUser() ([]models.User, error)
-	GetUserByUUID(uuid uuid.UUID) (models.User, error)
-	CreateUser(user models.User) (models.User, error)
-	UpdateUser(uuid uuid.UUID, user models.User) (models.User, error)
-	DeleteUser(uuid uuid.UUID) error
+	GetAllUser(ctx *gin.Context, query v1dto.UserListQuery) ([]models.User, v1dto.PageMeta, error)
+	GetUserByUUID(ctx *gin.Context, uuid uuid.UUID) (models.User, error)
+	CreateUser(ctx *gin.Context, user models.User) (models.User, error)
+	UpdateUser(ctx *gin.Context, uuid uuid.UUID, user models.User) (models.User, error)
+	PatchUser(ctx *gin.Context, uuid uuid.UUID, patch v1dto.UserPatch) (models.User, error)
+	DeleteUser(ctx *gin.Context, uuid uuid.UUID) error
+	ListDeletedUsers(ctx *gin.Context, query v1dto.UserListQuery) ([]models.User, v1dto.PageMeta, error)
+	RestoreUser(ctx *gin.Context, uuid uuid.UUID) (models.User, error)
+	SearchUsers(ctx *gin.Context, query v1dto.UserSearchQuery) ([]v1dto.UserSearchResult, error)
+	ReindexUsers(ctx *gin.Context) (int, error)
+	ImportUsers(ctx *gin.Context, input v1dto.UserImport) (models.UserImportJob, error)
+	GetImportJob(ctx *gin.Context, id string) (models.UserImportJob, error)
+	ExportUsers(ctx *gin.Context, query v1dto.UserExportQuery) (*UserExport, error)
+	GetExportJob(ctx *gin.Context, id string) (models.UserExportJob, string, error)
+	OpenUserExport(id string, expires int64, signature string) (models.UserExportJob, string, error)
+}
+
+type AccountService interface {
+	GetAccount(ctx *gin.Context, actor uuid.UUID) (models.User, error)
+	PatchAccount(ctx *gin.Context, actor uuid.UUID, patch v1dto.UserPatch) (models.User, error)
+	ChangePassword(ctx *gin.Context, actor uuid.UUID, input v1dto.ChangePasswordInput) error
+	ChangeEmail(ctx *gin.Context, actor uuid.UUID, input v1dto.ChangeEmailInput) (models.User, error)
+	ConfirmEmail(ctx *gin.Context, actor uuid.UUID, code string) (models.User, error)
+	RevertEmail(ctx *gin.Context, token string) error
+	CloseAccount(ctx *gin.Context, actor uuid.UUID, currentPassword string) error
 }
 
 type AuthService interface {
-	Login(ctx *gin.Context, email, password string) (string, string, int, error)
+	Login(ctx *gin.Context, email, password string) (v1dto.LoginResponse, error)
 	Logout(ctx *gin.Context, refreshTokenString string) error
 	RefreshToken(ctx *gin.Context, token string) (string, string, int, error)
 	RequestForgotPassword(ctx *gin.Context, email string) (string, error)
 	RequestResetPassword(ctx *gin.Context, token, password string) error
 	Register(ctx *gin.Context, input v1dto.RegisterInput) error
 	RegisterOTP(ctx *gin.Context, otp string) error
-}
\ No newline at end of file
+	SetupTwoFactor(ctx *gin.Context, userUUID uuid.UUID) (v1dto.TwoFactorSetupResponse, error)
+	ConfirmTwoFactor(ctx *gin.Context, userUUID uuid.UUID, code string) ([]string, error)
+	DisableTwoFactor(ctx *gin.Context, userUUID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx *gin.Context, userUUID uuid.UUID, code string) ([]string, error)
+	VerifyTwoFactor(ctx *gin.Context, challengeToken, code, recoveryCode string) (v1dto.LoginResponse, error)
+	BeginPasskeyRegistration(ctx *gin.Context, userUUID uuid.UUID) (webauthn.CreationOptions, error)
+	FinishPasskeyRegistration(ctx *gin.Context, userUUID uuid.UUID, input v1dto.PasskeyRegisterInput) (models.PasskeyCredential, error)
+	BeginPasskeyLogin(ctx *gin.Context, email string) (v1dto.PasskeyLoginBeginResponse, error)
+	FinishPasskeyLogin(ctx *gin.Context, input v1dto.PasskeyLoginInput) (v1dto.LoginResponse, error)
+	ListPasskeys(ctx *gin.Context, userUUID uuid.UUID) ([]models.PasskeyCredential, error)
+	DeletePasskey(ctx *gin.Context, userUUID uuid.UUID, id int64) error
+}
+
+type SessionService interface {
+	ListSessions(ctx *gin.Context, userUUID uuid.UUID) ([]auth.RefreshFamily, error)
+	RevokeSession(ctx *gin.Context, userUUID uuid.UUID, sessionID string) error
+	RevokeAllSessions(ctx *gin.Context, userUUID uuid.UUID) error
+}
+
+type OAuthService interface {
+	Authorize(ctx *gin.Context, userUUID uuid.UUID, input v1dto.AuthorizeInput) (v1dto.AuthorizeResponse, error)
+	DecideAuthorization(ctx *gin.Context, userUUID uuid.UUID, input v1dto.AuthorizeDecisionInput) (v1dto.AuthorizeResponse, error)
+	Token(ctx *gin.Context, input v1dto.TokenInput) (v1dto.TokenResponse, error)
+	Introspect(ctx *gin.Context, input v1dto.TokenLookupInput) (v1dto.IntrospectionResponse, error)
+	Revoke(ctx *gin.Context, input v1dto.TokenLookupInput) error
+	CreateClient(ctx *gin.Context, client models.OAuthClient) (models.OAuthClient, string, error)
+	ListClients(ctx *gin.Context) ([]models.OAuthClient, error)
+	GetClient(ctx *gin.Context, clientID string) (models.OAuthClient, error)
+	UpdateClient(ctx *gin.Context, clientID string, client models.OAuthClient) (models.OAuthClient, error)
+	DeleteClient(ctx *gin.Context, clientID string) error
+	ListConsents(ctx *gin.Context, userUUID uuid.UUID) ([]models.OAuthConsent, error)
+	RevokeConsent(ctx *gin.Context, userUUID uuid.UUID, clientID string) error
+	UserInfo(ctx *gin.Context, userUUID uuid.UUID, scopes []string) (map[string]any, error)
+	OpenIDConfiguration(ctx *gin.Context) v1dto.OpenIDConfiguration
+}
+
+type ServiceAccountService interface {
+	CreateServiceAccount(ctx *gin.Context, account models.ServiceAccount) (models.ServiceAccount, string, error)
+	ListServiceAccounts(ctx *gin.Context) ([]models.ServiceAccount, error)
+	GetServiceAccount(ctx *gin.Context, clientID string) (models.ServiceAccount, error)
+	UpdateServiceAccount(ctx *gin.Context, clientID string, account models.ServiceAccount) (models.ServiceAccount, error)
+	SetServiceAccountEnabled(ctx *gin.Context, clientID string, enabled bool) error
+	RotateServiceAccountSecret(ctx *gin.Context, clientID string) (string, error)
+	DeleteServiceAccount(ctx *gin.Context, clientID string) error
+}
+
+type RBACService interface {
+	ListRoles(ctx *gin.Context) ([]models.Role, error)
+	GetRole(ctx *gin.Context, id int64) (models.Role, []models.Permission, error)
+	CreateRole(ctx *gin.Context, role models.Role, permissions []string) (models.Role, error)
+	UpdateRole(ctx *gin.Context, id int64, role models.Role) (models.Role, error)
+	DeleteRole(ctx *gin.Context, id int64) error
+	SetRolePermissions(ctx *gin.Context, id int64, permissions []string) error
+	ListPermissions(ctx *gin.Context) ([]models.Permission, error)
+	ListUserRoles(ctx *gin.Context, userUUID uuid.UUID) ([]models.Role, error)
+	AssignUserRole(ctx *gin.Context, userUUID uuid.UUID, roleID int64) error
+	RemoveUserRole(ctx *gin.Context, userUUID uuid.UUID, roleID int64) error
+	UserPermissions(userUUID uuid.UUID) ([]string, error)
+	HasPermission(userUUID uuid.UUID, permission string) (bool, error)
+}
+
+type OrganizationService interface {
+	CreateOrganization(ctx *gin.Context, actor uuid.UUID, organization models.Organization) (models.Organization, error)
+	ListOrganizations(ctx *gin.Context, actor uuid.UUID) ([]models.Organization, error)
+	GetOrganization(ctx *gin.Context, actor uuid.UUID, id string) (models.Organization, error)
+	UpdateOrganization(ctx *gin.Context, actor uuid.UUID, id string, organization models.Organization) (models.Organization, error)
+	DeleteOrganization(ctx *gin.Context, actor uuid.UUID, id string) error
+	ListMembers(ctx *gin.Context, actor uuid.UUID, id string) ([]models.OrganizationMember, error)
+	SetMemberRole(ctx *gin.Context, actor uuid.UUID, id string, userUUID uuid.UUID, role string) (models.OrganizationMember, error)
+	RemoveMember(ctx *gin.Context, actor uuid.UUID, id string, userUUID uuid.UUID) error
+	SwitchOrganization(ctx *gin.Context, actor uuid.UUID, id string) (v1dto.LoginResponse, error)
+}
+
+type InvitationService interface {
+	CreateInvitation(ctx *gin.Context, actor uuid.UUID, invitation models.Invitation) (models.Invitation, error)
+	ListInvitations(ctx *gin.Context, actor uuid.UUID, organizationID string) ([]models.Invitation, error)
+	ResendInvitation(ctx *gin.Context, actor uuid.UUID, id string) (models.Invitation, error)
+	RevokeInvitation(ctx *gin.Context, actor uuid.UUID, id string) error
+	AcceptInvitation(ctx *gin.Context, token string, user models.User) (models.User, error)
+}
+
+type GroupService interface {
+	ListGroups(ctx *gin.Context) ([]models.Group, error)
+	GetGroup(ctx *gin.Context, id int64) (models.Group, error)
+	CreateGroup(ctx *gin.Context, group models.Group) (models.Group, error)
+	UpdateGroup(ctx *gin.Context, id int64, group models.Group) (models.Group, error)
+	DeleteGroup(ctx *gin.Context, id int64) error
+	ListMembers(ctx *gin.Context, id int64, effective bool) ([]uuid.UUID, []models.Group, error)
+	AddUser(ctx *gin.Context, id int64, userUUID uuid.UUID) error
+	RemoveUser(ctx *gin.Context, id int64, userUUID uuid.UUID) error
+	AddChildGroup(ctx *gin.Context, id, childID int64) error
+	RemoveChildGroup(ctx *gin.Context, id, childID int64) error
+	ListGroupRoles(ctx *gin.Context, id int64) ([]models.Role, error)
+	AssignGroupRole(ctx *gin.Context, id, roleID int64) error
+	RemoveGroupRole(ctx *gin.Context, id, roleID int64) error
+	ListGroupPermissions(ctx *gin.Context, id int64) ([]models.Permission, error)
+	SetGroupPermissions(ctx *gin.Context, id int64, permissions []string) error
+	UserGroups(ctx *gin.Context, userUUID uuid.UUID, effective bool) ([]models.Group, error)
+	EffectiveGroupIDs(userUUID uuid.UUID) ([]int64, error)
+	EffectiveMembers(id int64) ([]uuid.UUID, error)
+	IsMember(userUUID uuid.UUID, name string) (bool, error)
+}
@@ -0,0 +1,310 @@
+package v1service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/mail"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var InvitationTTL = 7 * 24 * time.Hour
+
+type invitationService struct {
+	repo repository.InvitationRepository
+	userRepo repository.UserRepository
+	organizationRepo repository.OrganizationRepository
+	rbacRepo repository.RBACRepository
+	rbac RBACService
+	rabbitmqService rabbitmq.RabbitMQService
+	index search.SearchIndex
+}
+
+func NewInvitationService(repo repository.InvitationRepository, userRepo repository.UserRepository, organizationRepo repository.OrganizationRepository, rbacRepo repository.RBACRepository, rbac RBACService, rabbitmqService rabbitmq.RabbitMQService, index search.SearchIndex) InvitationService {
+	return &invitationService{
+		repo: repo,
+		userRepo: userRepo,
+		organizationRepo: organizationRepo,
+		rbacRepo: rbacRepo,
+		rbac: rbac,
+		rabbitmqService: rabbitmqService,
+		index: index,
+	}
+}
+
+func (is *invitationService) CreateInvitation(ctx *gin.Context, actor uuid.UUID, invitation models.Invitation) (models.Invitation, error) {
+	invitation.Email = utils.NormailizeString(invitation.Email)
+	if invitation.OrganizationID != nil && invitation.OrganizationRole == "" {
+		invitation.OrganizationRole = models.OrganizationRoleMember
+	}
+	if invitation.OrganizationID == nil {
+		invitation.OrganizationRole = ""
+	}
+
+	if err := is.authorize(actor, invitation); err != nil {
+		return models.Invitation{}, err
+	}
+
+	if invitation.RoleID != nil {
+		if _, found, err := is.rbacRepo.FindRoleByID(*invitation.RoleID); err != nil || !found {
+			return models.Invitation{}, utils.NewError(string(utils.ErrCodeBadRequest), "Role not found")
+		}
+	}
+
+	organizationID := ""
+	if invitation.OrganizationID != nil {
+		organizationID = *invitation.OrganizationID
+	}
+
+	if existing, err := emailLookup(is.userRepo, organizationID).FindByEmail(invitation.Email); err != nil || existing.Email != "" {
+		return models.Invitation{}, utils.NewError(string(utils.ErrCodeConflict), fmt.Sprintf("Email: %v already existed.", invitation.Email))
+	}
+
+	if _, found, err := is.repo.FindPending(invitation.Email, invitation.OrganizationID); err != nil || found {
+		return models.Invitation{}, utils.NewError(string(utils.ErrCodeConflict), "An invitation is already pending for this email")
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return models.Invitation{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate invitation token")
+	}
+
+	invitation.ID = uuid.NewString()
+	invitation.TokenHash = hashInvitationToken(token)
+	invitation.InvitedBy = actor
+	invitation.ExpiresAt = time.Now().Add(InvitationTTL)
+	invitation.CreatedAt = time.Now()
+	invitation.UpdatedAt = time.Now()
+
+	if err := is.repo.Create(invitation); err != nil {
+		return models.Invitation{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to create invitation", err)
+	}
+
+	if err := is.send(ctx, invitation, token); err != nil {
+		return models.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// ListInvitations lists the invitations of one organization for its owners and
+// admins, or every invitation for holders of users:write.
+func (is *invitationService) ListInvitations(ctx *gin.Context, actor uuid.UUID, organizationID string) ([]models.Invitation, error) {
+	filter := models.Invitation{}
+	if organizationID != "" {
+		filter.OrganizationID = &organizationID
+	}
+
+	if err := is.authorize(actor, filter); err != nil {
+		return nil, err
+	}
+
+	invitations, err := is.repo.FindAll(organizationID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load invitations", err)
+	}
+
+	return invitations, nil
+}
+
+// ResendInvitation mails a fresh link, the old one stops working and the
+// expiry starts over.
+func (is *invitationService) ResendInvitation(ctx *gin.Context, actor uuid.UUID, id string) (models.Invitation, error) {
+	invitation, err := is.findInvitation(actor, id)
+	if err != nil {
+		return models.Invitation{}, err
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return models.Invitation{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to generate invitation token")
+	}
+
+	invitation.TokenHash = hashInvitationToken(token)
+	invitation.ExpiresAt = time.Now().Add(InvitationTTL)
+	invitation.UpdatedAt = time.Now()
+
+	renewed, err := is.repo.Renew(invitation.ID, invitation.TokenHash, invitation.ExpiresAt)
+	if err != nil {
+		return models.Invitation{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to renew invitation", err)
+	}
+
+	if !renewed {
+		return models.Invitation{}, utils.NewError(string(utils.ErrCodeConflict), "Invitation was already accepted or revoked")
+	}
+
+	if err := is.send(ctx, invitation, token); err != nil {
+		return models.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+func (is *invitationService) RevokeInvitation(ctx *gin.Context, actor uuid.UUID, id string) error {
+	invitation, err := is.findInvitation(actor, id)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := is.repo.Revoke(invitation.ID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke invitation", err)
+	}
+
+	if !revoked {
+		return utils.NewError(string(utils.ErrCodeConflict), "Invitation was already accepted or revoked")
+	}
+
+	return nil
+}
+
+// AcceptInvitation creates the invited user with the name and password they
+// chose. The invitation link proves the email, so no OTP is needed.
+func (is *invitationService) AcceptInvitation(ctx *gin.Context, token string, user models.User) (models.User, error) {
+	invalid := utils.NewError(string(utils.ErrCodeBadRequest), "Invitation is invalid or expired.")
+
+	invitation, found, err := is.repo.FindByTokenHash(hashInvitationToken(token))
+	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load invitation", err)
+	}
+
+	if !found || invitation.Status() != models.InvitationPending {
+		return models.User{}, invalid
+	}
+
+	organizationID := ""
+	if invitation.OrganizationID != nil {
+		organizationID = *invitation.OrganizationID
+	}
+
+	if existing, err := emailLookup(is.userRepo, organizationID).FindByEmail(invitation.Email); err != nil || existing.Email != "" {
+		return models.User{}, utils.NewError(string(utils.ErrCodeConflict), fmt.Sprintf("Email: %v already existed.", invitation.Email))
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile hash password", err)
+	}
+
+	user.UUID = uuid.New()
+	user.Email = invitation.Email
+	user.Password = string(hashPassword)
+	user.Level = models.LevelCustomer
+	user.Status = models.StatusActive
+	user.OrganizationID = invitation.OrganizationID
+	if user.Age == 0 {
+		user.Age = 1
+	}
+
+	accepted, err := is.repo.Accept(invitation, user)
+	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to accept invitation", err)
+	}
+
+	if !accepted {
+		return models.User{}, invalid
+	}
+
+	user.Password = ""
+	indexUser(is.index, user)
+	return user, nil
+}
+
+func (is *invitationService) findInvitation(actor uuid.UUID, id string) (models.Invitation, error) {
+	invitation, found, err := is.repo.FindByID(id)
+	if err != nil {
+		return models.Invitation{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load invitation", err)
+	}
+
+	if !found {
+		return models.Invitation{}, utils.NewError(string(utils.ErrCodeNotFound), "Invitation not found")
+	}
+
+	if err := is.authorize(actor, invitation); err != nil {
+		return models.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// authorize checks the actor may manage invitation. Holders of users:write
+// manage every invitation, organization owners and admins those into their
+// organization. Granting a role needs roles:write, ownership an owner.
+func (is *invitationService) authorize(actor uuid.UUID, invitation models.Invitation) error {
+	denied := utils.NewError(string(utils.ErrCodeForbidden), "Access denied")
+
+	canWrite, err := is.rbac.HasPermission(actor, "users:write")
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+	}
+
+	if invitation.RoleID != nil {
+		canGrant, err := is.rbac.HasPermission(actor, "roles:write")
+		if err != nil {
+			return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+		}
+
+		if !canGrant {
+			return denied
+		}
+	}
+
+	if invitation.OrganizationID == nil {
+		if !canWrite {
+			return denied
+		}
+
+		return nil
+	}
+
+	member, found, err := is.organizationRepo.FindMember(*invitation.OrganizationID, actor)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load member", err)
+	}
+
+	if invitation.OrganizationRole == models.OrganizationRoleOwner && (!found || member.Role != models.OrganizationRoleOwner) {
+		return utils.NewError(string(utils.ErrCodeForbidden), "Only an owner can manage owners")
+	}
+
+	if canWrite {
+		return nil
+	}
+
+	if !found || (member.Role != models.OrganizationRoleOwner && member.Role != models.OrganizationRoleAdmin) {
+		return denied
+	}
+
+	return nil
+}
+
+func (is *invitationService) send(ctx *gin.Context, invitation models.Invitation, token string) error {
+	acceptLink := fmt.Sprintf("%s?token=%s", utils.GetEnv("INVITATION_URL", "https://yourdomain.com/accept-invitation"), token)
+
+	mailContent := &mail.Email{
+		To: []mail.Address{
+			{Email: invitation.Email},
+		},
+		Subject: "You have been invited",
+		Text: fmt.Sprintf("Hi,\n\nYou have been invited to create an account. Click the link below to choose your name and password:\n%s\n\nThe link will expire on %s.", acceptLink, invitation.ExpiresAt.Format(time.RFC1123)),
+	}
+
+	if err := is.rabbitmqService.Publish(ctx, "auth_email_queue", mailContent); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to send invitation email.")
+	}
+
+	return nil
+}
+
+func hashInvitationToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
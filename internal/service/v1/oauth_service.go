@@ -0,0 +1,827 @@
+package v1service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	AuthorizationCodeTTL = time.Minute
+	// How long a redeemed code is remembered so a second redemption can be caught.
+	AuthorizationCodeReuseWindow = 10 * time.Minute
+	// Scopes a client may be registered for.
+	OAuthScopes = []string{"openid", "profile", "email", "users:read", "users:write", "account", "oauth:clients"}
+)
+
+const SecurityEventAuthorizationCodeReuse = "authorization_code_reuse"
+
+// OAuthError carries an RFC 6749 error code, handlers render it in the
+// standard {"error", "error_description"} shape instead of the API envelope.
+type OAuthError struct {
+	Code 		string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(code, description string) error {
+	return &OAuthError{
+		Code: code,
+		Description: description,
+	}
+}
+
+type authorizationCode struct {
+	ClientID 		string `json:"client_id"`
+	UserUUID 		uuid.UUID `json:"user_uuid"`
+	RedirectURI 	string `json:"redirect_uri"`
+	Scopes 			[]string `json:"scopes"`
+	CodeChallenge 	string `json:"code_challenge"`
+	Nonce 			string `json:"nonce,omitempty"`
+}
+
+type oauthService struct {
+	userRepo repository.UserRepository
+	clientRepo repository.OAuthClientRepository
+	serviceAccountRepo repository.ServiceAccountRepository
+	consentRepo repository.OAuthConsentRepository
+	tokenService auth.TokenService
+	cache cache.RedisCacheService
+	rabbitmqService rabbitmq.RabbitMQService
+	issuer *tokenIssuer
+}
+
+func NewOAuthService(userRepo repository.UserRepository, clientRepo repository.OAuthClientRepository, serviceAccountRepo repository.ServiceAccountRepository, consentRepo repository.OAuthConsentRepository, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService) OAuthService {
+	return &oauthService{
+		userRepo: userRepo,
+		clientRepo: clientRepo,
+		serviceAccountRepo: serviceAccountRepo,
+		consentRepo: consentRepo,
+		tokenService: tokenService,
+		cache: cacheService,
+		rabbitmqService: rabbitmqService,
+		issuer: newTokenIssuer(userRepo, tokenService, rabbitmqService),
+	}
+}
+
+func (oa *oauthService) Authorize(ctx *gin.Context, userUUID uuid.UUID, input v1dto.AuthorizeInput) (v1dto.AuthorizeResponse, error) {
+	client, scopes, redirectErr, err := oa.validateAuthorizeRequest(input)
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, err
+	}
+
+	response := v1dto.AuthorizeResponse{
+		Client: v1dto.OAuthClientSummaryDTO{
+			ClientID: client.ClientID,
+			Name: client.Name,
+		},
+		Scopes: scopes,
+	}
+
+	if redirectErr != nil {
+		response.RedirectTo = authorizeRedirect(input.RedirectURI, input.State, url.Values{
+			"error": {redirectErr.Code},
+			"error_description": {redirectErr.Description},
+		})
+		return response, nil
+	}
+
+	consent, found, err := oa.consentRepo.Find(userUUID, client.ClientID)
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load consent", err)
+	}
+
+	if !found || !containsAll(strings.Fields(consent.Scopes), scopes) {
+		response.ConsentRequired = true
+		return response, nil
+	}
+
+	redirectTo, err := oa.issueAuthorizationCode(userUUID, client, scopes, input)
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, err
+	}
+
+	response.RedirectTo = redirectTo
+
+	return response, nil
+}
+
+func (oa *oauthService) DecideAuthorization(ctx *gin.Context, userUUID uuid.UUID, input v1dto.AuthorizeDecisionInput) (v1dto.AuthorizeResponse, error) {
+	client, scopes, redirectErr, err := oa.validateAuthorizeRequest(input.AuthorizeInput)
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, err
+	}
+
+	response := v1dto.AuthorizeResponse{
+		Client: v1dto.OAuthClientSummaryDTO{
+			ClientID: client.ClientID,
+			Name: client.Name,
+		},
+		Scopes: scopes,
+	}
+
+	if redirectErr == nil && !input.Approve {
+		redirectErr = &OAuthError{Code: "access_denied", Description: "The user denied the request"}
+	}
+
+	if redirectErr != nil {
+		response.RedirectTo = authorizeRedirect(input.RedirectURI, input.State, url.Values{
+			"error": {redirectErr.Code},
+			"error_description": {redirectErr.Description},
+		})
+		return response, nil
+	}
+
+	// Consent accumulates, approving a narrower request later does not take
+	// away scopes the user already granted.
+	consent, found, err := oa.consentRepo.Find(userUUID, client.ClientID)
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load consent", err)
+	}
+
+	granted := scopes
+	if found {
+		granted = mergeScopes(strings.Fields(consent.Scopes), scopes)
+	}
+
+	err = oa.consentRepo.Save(models.OAuthConsent{
+		UserUUID: userUUID,
+		ClientID: client.ClientID,
+		Scopes: strings.Join(granted, " "),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to save consent", err)
+	}
+
+	redirectTo, err := oa.issueAuthorizationCode(userUUID, client, scopes, input.AuthorizeInput)
+	if err != nil {
+		return v1dto.AuthorizeResponse{}, err
+	}
+
+	response.RedirectTo = redirectTo
+
+	return response, nil
+}
+
+// validateAuthorizeRequest returns an error when the client or redirect URI cannot
+// be trusted, in which case nothing may be sent to the redirect URI. Every other
+// problem comes back as redirectErr and is reported to the client by redirect.
+func (oa *oauthService) validateAuthorizeRequest(input v1dto.AuthorizeInput) (models.OAuthClient, []string, *OAuthError, error) {
+	client, found, err := oa.clientRepo.FindByClientID(input.ClientID)
+	if err != nil {
+		return models.OAuthClient{}, nil, nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load client", err)
+	}
+
+	if !found {
+		return models.OAuthClient{}, nil, nil, newOAuthError("invalid_request", "Unknown client")
+	}
+
+	if !slices.Contains(strings.Fields(client.RedirectURIs), input.RedirectURI) {
+		return models.OAuthClient{}, nil, nil, newOAuthError("invalid_request", "Redirect URI is not registered for this client")
+	}
+
+	if input.ResponseType != "code" {
+		return client, nil, &OAuthError{Code: "unsupported_response_type", Description: "Only the code response type is supported"}, nil
+	}
+
+	if input.CodeChallenge == "" || input.CodeChallengeMethod != "S256" {
+		return client, nil, &OAuthError{Code: "invalid_request", Description: "PKCE with the S256 method is required"}, nil
+	}
+
+	allowed := strings.Fields(client.Scopes)
+	scopes := strings.Fields(input.Scope)
+	if len(scopes) == 0 {
+		scopes = allowed
+	}
+
+	if !containsAll(allowed, scopes) {
+		return client, nil, &OAuthError{Code: "invalid_scope", Description: "Requested scope is not allowed for this client"}, nil
+	}
+
+	return client, scopes, nil, nil
+}
+
+func (oa *oauthService) issueAuthorizationCode(userUUID uuid.UUID, client models.OAuthClient, scopes []string, input v1dto.AuthorizeInput) (string, error) {
+	code, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", utils.NewError(string(utils.ErrCodeInternal), "Failed to generate authorization code")
+	}
+
+	record := authorizationCode{
+		ClientID: client.ClientID,
+		UserUUID: userUUID,
+		RedirectURI: input.RedirectURI,
+		Scopes: scopes,
+		CodeChallenge: input.CodeChallenge,
+		Nonce: input.Nonce,
+	}
+
+	if err := oa.cache.Set("oauth:code:" + code, record, AuthorizationCodeTTL); err != nil {
+		return "", utils.NewError(string(utils.ErrCodeInternal), "Failed to store authorization code")
+	}
+
+	return authorizeRedirect(input.RedirectURI, input.State, url.Values{
+		"code": {code},
+	}), nil
+}
+
+func authorizeRedirect(redirectURI, state string, params url.Values) string {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+
+	if state != "" {
+		query.Set("state", state)
+	}
+
+	target.RawQuery = query.Encode()
+
+	return target.String()
+}
+
+func (oa *oauthService) Token(ctx *gin.Context, input v1dto.TokenInput) (v1dto.TokenResponse, error) {
+	// Service accounts are not OAuth clients and only ever use this grant.
+	if input.GrantType == "client_credentials" {
+		return oa.clientCredentials(ctx, input)
+	}
+
+	client, err := oa.authenticateClient(ctx, input.ClientAuthInput)
+	if err != nil {
+		return v1dto.TokenResponse{}, err
+	}
+
+	var (
+		response v1dto.LoginResponse
+		userUUID uuid.UUID
+		scopes []string
+		nonce string
+	)
+
+	switch input.GrantType {
+	case "authorization_code":
+		var record authorizationCode
+		response, record, err = oa.exchangeAuthorizationCode(ctx, client, input)
+		userUUID, scopes, nonce = record.UserUUID, record.Scopes, record.Nonce
+	case "refresh_token":
+		var refreshToken auth.RefreshToken
+		response, refreshToken, err = oa.issuer.rotate(ctx, input.RefreshToken, client.ClientID)
+		if err != nil {
+			err = newOAuthError("invalid_grant", "Refresh token is invalid or revoked")
+		}
+		userUUID, scopes = refreshToken.UserUUID, refreshToken.Scopes
+	default:
+		err = newOAuthError("unsupported_grant_type", "Grant type is not supported")
+	}
+
+	if err != nil {
+		return v1dto.TokenResponse{}, err
+	}
+
+	tokenResponse := v1dto.TokenResponse{
+		AccessToken: response.AccessToken,
+		TokenType: "Bearer",
+		ExpiresIn: response.ExpiresIn,
+		RefreshToken: response.RefreshToken,
+		Scope: strings.Join(scopes, " "),
+	}
+
+	if slices.Contains(scopes, "openid") {
+		user, err := oa.userRepo.FindBYUUID(userUUID)
+		if err != nil || user.Email == "" {
+			return v1dto.TokenResponse{}, newOAuthError("invalid_grant", "User not found")
+		}
+
+		tokenResponse.IDToken, err = oa.tokenService.GenerateIDToken(user, client.ClientID, nonce, scopes)
+		if err != nil {
+			return v1dto.TokenResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to create ID token", err)
+		}
+	}
+
+	return tokenResponse, nil
+}
+
+// authenticateClient accepts credentials through HTTP Basic or the request body.
+// Public clients only identify themselves, PKCE is what protects their codes.
+func (oa *oauthService) authenticateClient(ctx *gin.Context, input v1dto.ClientAuthInput) (models.OAuthClient, error) {
+	clientID, clientSecret := clientCredentials(ctx, input)
+
+	invalid := newOAuthError("invalid_client", "Client authentication failed")
+	if clientID == "" {
+		return models.OAuthClient{}, invalid
+	}
+
+	client, found, err := oa.clientRepo.FindByClientID(clientID)
+	if err != nil {
+		return models.OAuthClient{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load client", err)
+	}
+
+	if !found {
+		return models.OAuthClient{}, invalid
+	}
+
+	if client.Public {
+		if clientSecret != "" {
+			return models.OAuthClient{}, invalid
+		}
+		return client, nil
+	}
+
+	if client.ClientSecretHash == nil || subtle.ConstantTimeCompare([]byte(hashClientSecret(clientSecret)), []byte(*client.ClientSecretHash)) != 1 {
+		return models.OAuthClient{}, invalid
+	}
+
+	return client, nil
+}
+
+func clientCredentials(ctx *gin.Context, input v1dto.ClientAuthInput) (string, string) {
+	if username, password, ok := ctx.Request.BasicAuth(); ok {
+		clientID, _ := url.QueryUnescape(username)
+		clientSecret, _ := url.QueryUnescape(password)
+		return clientID, clientSecret
+	}
+
+	return input.ClientID, input.ClientSecret
+}
+
+func (oa *oauthService) authenticateServiceAccount(ctx *gin.Context, input v1dto.ClientAuthInput) (models.ServiceAccount, error) {
+	clientID, clientSecret := clientCredentials(ctx, input)
+
+	invalid := newOAuthError("invalid_client", "Client authentication failed")
+	if clientID == "" || clientSecret == "" {
+		return models.ServiceAccount{}, invalid
+	}
+
+	account, found, err := oa.serviceAccountRepo.FindByClientID(clientID)
+	if err != nil {
+		return models.ServiceAccount{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load service account", err)
+	}
+
+	if !found || !account.Enabled || !verifyServiceAccountSecret(account, clientSecret) {
+		return models.ServiceAccount{}, invalid
+	}
+
+	return account, nil
+}
+
+// authenticateCaller identifies whoever calls the introspection and revocation
+// endpoints, an OAuth client or a service account. Public clients cannot prove
+// who they are, which is reported through confidential.
+func (oa *oauthService) authenticateCaller(ctx *gin.Context, input v1dto.ClientAuthInput) (string, bool, error) {
+	client, err := oa.authenticateClient(ctx, input)
+	if err == nil {
+		return client.ClientID, !client.Public, nil
+	}
+
+	var oauthErr *OAuthError
+	if !errors.As(err, &oauthErr) {
+		return "", false, err
+	}
+
+	account, err := oa.authenticateServiceAccount(ctx, input)
+	if err != nil {
+		return "", false, err
+	}
+
+	return account.ClientID, true, nil
+}
+
+func (oa *oauthService) clientCredentials(ctx *gin.Context, input v1dto.TokenInput) (v1dto.TokenResponse, error) {
+	account, err := oa.authenticateServiceAccount(ctx, input.ClientAuthInput)
+	if err != nil {
+		return v1dto.TokenResponse{}, err
+	}
+
+	allowed := strings.Fields(account.Scopes)
+	scopes := strings.Fields(input.Scope)
+	if len(scopes) == 0 {
+		scopes = allowed
+	}
+
+	if !containsAll(allowed, scopes) {
+		return v1dto.TokenResponse{}, newOAuthError("invalid_scope", "Requested scope is not allowed for this service account")
+	}
+
+	accessToken, err := oa.tokenService.GenerateServiceAccessToken(account, scopes)
+	if err != nil {
+		return v1dto.TokenResponse{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to create access token", err)
+	}
+
+	if err := oa.serviceAccountRepo.TouchLastUsed(account.ClientID); err != nil {
+		log.Printf("Failed to record service account use %s:%s", account.ClientID, err)
+	}
+
+	return v1dto.TokenResponse{
+		AccessToken: accessToken,
+		TokenType: "Bearer",
+		ExpiresIn: int(auth.AccessTokenTTL.Seconds()),
+		Scope: strings.Join(scopes, " "),
+	}, nil
+}
+
+// Introspect reports whether a token is still usable. Only confidential clients
+// and service accounts may introspect.
+func (oa *oauthService) Introspect(ctx *gin.Context, input v1dto.TokenLookupInput) (v1dto.IntrospectionResponse, error) {
+	_, confidential, err := oa.authenticateCaller(ctx, input.ClientAuthInput)
+	if err != nil {
+		return v1dto.IntrospectionResponse{}, err
+	}
+
+	if !confidential {
+		return v1dto.IntrospectionResponse{}, newOAuthError("invalid_client", "Public clients cannot introspect tokens")
+	}
+
+	lookups := []func(string) (v1dto.IntrospectionResponse, bool){oa.introspectAccessToken, oa.introspectRefreshToken}
+	if input.TokenTypeHint == "refresh_token" {
+		slices.Reverse(lookups)
+	}
+
+	for _, lookup := range lookups {
+		if response, ok := lookup(input.Token); ok {
+			return response, nil
+		}
+	}
+
+	return v1dto.IntrospectionResponse{Active: false}, nil
+}
+
+func (oa *oauthService) introspectAccessToken(token string) (v1dto.IntrospectionResponse, bool) {
+	_, claims, err := oa.tokenService.ParseToken(token)
+	if err != nil {
+		return v1dto.IntrospectionResponse{}, false
+	}
+
+	jti, exp := accessTokenID(claims)
+	if revoked, err := oa.tokenService.IsAccessTokenBlacklisted(jti); err != nil || revoked {
+		return v1dto.IntrospectionResponse{}, false
+	}
+
+	// ID tokens are signed with the same keys but carry no payload.
+	payload, err := oa.tokenService.DecryptAccessTokenPayload(token)
+	if err != nil {
+		return v1dto.IntrospectionResponse{}, false
+	}
+
+	sub := payload.UserUUID.String()
+	if payload.PrincipalType == auth.PrincipalService {
+		if disabled, err := oa.tokenService.IsServicePrincipalDisabled(payload.ClientID); err != nil || disabled {
+			return v1dto.IntrospectionResponse{}, false
+		}
+		sub = payload.ClientID
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	scope, _ := claims["scope"].(string)
+	iat, _ := claims["iat"].(float64)
+
+	return v1dto.IntrospectionResponse{
+		Active: true,
+		Scope: scope,
+		ClientID: clientID,
+		Sub: sub,
+		Exp: exp.Unix(),
+		Iat: int64(iat),
+		Jti: jti,
+		TokenType: "access_token",
+	}, true
+}
+
+func (oa *oauthService) introspectRefreshToken(token string) (v1dto.IntrospectionResponse, bool) {
+	refreshToken, err := oa.tokenService.ValidaRefreshToken(token)
+	if err != nil {
+		return v1dto.IntrospectionResponse{}, false
+	}
+
+	return v1dto.IntrospectionResponse{
+		Active: true,
+		Scope: strings.Join(refreshToken.Scopes, " "),
+		ClientID: refreshToken.ClientID,
+		Sub: refreshToken.UserUUID.String(),
+		Exp: refreshToken.ExpiresAt.Unix(),
+		TokenType: "refresh_token",
+	}, true
+}
+
+// Revoke implements RFC 7009. A client may only revoke tokens issued to it,
+// unknown or already invalid tokens are not an error.
+func (oa *oauthService) Revoke(ctx *gin.Context, input v1dto.TokenLookupInput) error {
+	callerID, _, err := oa.authenticateCaller(ctx, input.ClientAuthInput)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken, err := oa.tokenService.ValidaRefreshToken(input.Token); err == nil {
+		if refreshToken.ClientID != callerID {
+			return newOAuthError("unauthorized_client", "Token was not issued to this client")
+		}
+
+		return oa.issuer.revokeRefreshToken(refreshToken)
+	}
+
+	_, claims, err := oa.tokenService.ParseToken(input.Token)
+	if err != nil {
+		return nil
+	}
+
+	if clientID, _ := claims["client_id"].(string); clientID != callerID {
+		return newOAuthError("unauthorized_client", "Token was not issued to this client")
+	}
+
+	return oa.issuer.revokeAccessToken(claims)
+}
+
+func (oa *oauthService) exchangeAuthorizationCode(ctx *gin.Context, client models.OAuthClient, input v1dto.TokenInput) (v1dto.LoginResponse, authorizationCode, error) {
+	invalid := newOAuthError("invalid_grant", "Authorization code is invalid or expired")
+	if input.Code == "" {
+		return v1dto.LoginResponse{}, authorizationCode{}, invalid
+	}
+
+	codeKey := "oauth:code:" + input.Code
+	usedKey := "oauth:code:used:" + input.Code
+
+	var record authorizationCode
+	if err := oa.cache.Get(codeKey, &record); err != nil || record.ClientID == "" {
+		oa.handleAuthorizationCodeReuse(ctx, usedKey)
+		return v1dto.LoginResponse{}, authorizationCode{}, invalid
+	}
+
+	// Claimed before anything is issued, so of two concurrent exchanges of one
+	// code only the first gets tokens and the other counts as reuse.
+	claimed, err := oa.cache.SetNX(usedKey, "", AuthorizationCodeReuseWindow)
+	if err != nil {
+		return v1dto.LoginResponse{}, authorizationCode{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to claim authorization code", err)
+	}
+
+	if !claimed {
+		oa.handleAuthorizationCodeReuse(ctx, usedKey)
+		return v1dto.LoginResponse{}, authorizationCode{}, invalid
+	}
+
+	oa.cache.Clear(codeKey)
+
+	if record.ClientID != client.ClientID || record.RedirectURI != input.RedirectURI {
+		return v1dto.LoginResponse{}, authorizationCode{}, invalid
+	}
+
+	if !verifyCodeChallenge(input.CodeVerifier, record.CodeChallenge) {
+		return v1dto.LoginResponse{}, authorizationCode{}, newOAuthError("invalid_grant", "PKCE verification failed")
+	}
+
+	user, err := oa.userRepo.FindBYUUID(record.UserUUID)
+	if err != nil || user.Email == "" {
+		return v1dto.LoginResponse{}, authorizationCode{}, invalid
+	}
+
+	response, err := oa.issuer.issueTokens(ctx, user, auth.TokenGrant{
+		ClientID: client.ClientID,
+		Scopes: record.Scopes,
+	})
+	if err != nil {
+		return v1dto.LoginResponse{}, authorizationCode{}, err
+	}
+
+	if refreshToken, err := oa.tokenService.GetRefreshToken(response.RefreshToken); err == nil {
+		oa.cache.Set(usedKey, refreshToken.FamilyID, AuthorizationCodeReuseWindow)
+	}
+
+	return response, record, nil
+}
+
+// handleAuthorizationCodeReuse revokes the tokens issued for a code that is
+// presented a second time, as RFC 6749 section 4.1.2 recommends.
+func (oa *oauthService) handleAuthorizationCodeReuse(ctx *gin.Context, usedKey string) {
+	var familyID string
+	if err := oa.cache.Get(usedKey, &familyID); err != nil || familyID == "" {
+		return
+	}
+
+	family, err := oa.tokenService.RevokeRefreshFamily(familyID)
+	if err != nil {
+		log.Printf("Failed to revoke refresh token family %s:%s", familyID, err)
+		return
+	}
+
+	emitSecurityEvent(ctx, oa.rabbitmqService, SecurityEventAuthorizationCodeReuse, family.UserUUID, map[string]string{
+		"family_id": family.ID,
+		"client_id": family.ClientID,
+	})
+}
+
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	digest := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(digest[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func (oa *oauthService) CreateClient(ctx *gin.Context, client models.OAuthClient) (models.OAuthClient, string, error) {
+	if !containsAll(OAuthScopes, strings.Fields(client.Scopes)) {
+		return models.OAuthClient{}, "", utils.NewError(string(utils.ErrCodeBadRequest), "Unsupported scope")
+	}
+
+	client.ClientID = uuid.NewString()
+	client.CreatedAt = time.Now()
+	client.UpdatedAt = time.Now()
+
+	secret := ""
+	if !client.Public {
+		var err error
+		secret, err = utils.GenerateRandomString(32)
+		if err != nil {
+			return models.OAuthClient{}, "", utils.NewError(string(utils.ErrCodeInternal), "Failed to generate client secret")
+		}
+
+		secretHash := hashClientSecret(secret)
+		client.ClientSecretHash = &secretHash
+	}
+
+	if err := oa.clientRepo.Create(client); err != nil {
+		return models.OAuthClient{}, "", utils.WrapError(string(utils.ErrCodeInternal), "Failed to store client", err)
+	}
+
+	return client, secret, nil
+}
+
+func (oa *oauthService) ListClients(ctx *gin.Context) ([]models.OAuthClient, error) {
+	clients, err := oa.clientRepo.FindAll()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load clients", err)
+	}
+
+	return clients, nil
+}
+
+func (oa *oauthService) GetClient(ctx *gin.Context, clientID string) (models.OAuthClient, error) {
+	client, found, err := oa.clientRepo.FindByClientID(clientID)
+	if err != nil {
+		return models.OAuthClient{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load client", err)
+	}
+
+	if !found {
+		return models.OAuthClient{}, utils.NewError(string(utils.ErrCodeNotFound), "Client not found")
+	}
+
+	return client, nil
+}
+
+func (oa *oauthService) UpdateClient(ctx *gin.Context, clientID string, client models.OAuthClient) (models.OAuthClient, error) {
+	if !containsAll(OAuthScopes, strings.Fields(client.Scopes)) {
+		return models.OAuthClient{}, utils.NewError(string(utils.ErrCodeBadRequest), "Unsupported scope")
+	}
+
+	client.ClientID = clientID
+	updated, err := oa.clientRepo.Update(client)
+	if err != nil {
+		return models.OAuthClient{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to update client", err)
+	}
+
+	if !updated {
+		return models.OAuthClient{}, utils.NewError(string(utils.ErrCodeNotFound), "Client not found")
+	}
+
+	return oa.GetClient(ctx, clientID)
+}
+
+// DeleteClient leaves issued tokens to expire on their own. Their refresh
+// tokens are dead already because the client can no longer authenticate.
+func (oa *oauthService) DeleteClient(ctx *gin.Context, clientID string) error {
+	deleted, err := oa.clientRepo.Delete(clientID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete client", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Client not found")
+	}
+
+	if err := oa.consentRepo.DeleteByClient(clientID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete consents", err)
+	}
+
+	return nil
+}
+
+func (oa *oauthService) ListConsents(ctx *gin.Context, userUUID uuid.UUID) ([]models.OAuthConsent, error) {
+	consents, err := oa.consentRepo.FindByUser(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load consents", err)
+	}
+
+	return consents, nil
+}
+
+// RevokeConsent also signs the client out, otherwise it could keep refreshing
+// tokens the user no longer agrees to.
+func (oa *oauthService) RevokeConsent(ctx *gin.Context, userUUID uuid.UUID, clientID string) error {
+	deleted, err := oa.consentRepo.Delete(userUUID, clientID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke consent", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Consent not found")
+	}
+
+	families, err := oa.tokenService.ListRefreshFamilies(userUUID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load sessions", err)
+	}
+
+	for _, family := range families {
+		if family.ClientID != clientID {
+			continue
+		}
+
+		if _, err := oa.tokenService.RevokeRefreshFamily(family.ID); err != nil {
+			return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke session", err)
+		}
+	}
+
+	return nil
+}
+
+func hashClientSecret(secret string) string {
+	digest := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(digest[:])
+}
+
+func containsAll(set, values []string) bool {
+	for _, value := range values {
+		if !slices.Contains(set, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeScopes(current, added []string) []string {
+	merged := append([]string(nil), current...)
+	for _, scope := range added {
+		if !slices.Contains(merged, scope) {
+			merged = append(merged, scope)
+		}
+	}
+	return merged
+}
+
+func (oa *oauthService) UserInfo(ctx *gin.Context, userUUID uuid.UUID, scopes []string) (map[string]any, error) {
+	user, err := oa.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return nil, utils.NewError(string(utils.ErrCodeUnauthorized), "User not found")
+	}
+
+	return auth.UserClaims(user, scopes), nil
+}
+
+func (oa *oauthService) OpenIDConfiguration(ctx *gin.Context) v1dto.OpenIDConfiguration {
+	issuer := oa.tokenService.Issuer()
+
+	return v1dto.OpenIDConfiguration{
+		Issuer: issuer,
+		AuthorizationEndpoint: issuer + "/oauth/authorize",
+		TokenEndpoint: issuer + "/oauth/token",
+		UserinfoEndpoint: issuer + "/userinfo",
+		IntrospectionEndpoint: issuer + "/oauth/introspect",
+		RevocationEndpoint: issuer + "/oauth/revoke",
+		JWKSURI: issuer + "/.well-known/jwks.json",
+		ScopesSupported: OAuthScopes,
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported: []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported: []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{oa.tokenService.SigningAlgorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported: []string{"S256"},
+		ClaimsSupported: []string{"iss", "sub", "aud", "exp", "iat", "nonce", "email", "email_verified", "name"},
+	}
+}
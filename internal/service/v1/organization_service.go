@@ -0,0 +1,282 @@
+package v1service
+
+import (
+	"slices"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	EmailUniqueGlobal = "global"
+	EmailUniqueOrganization = "organization"
+	// Names the tenant for sign-in flows that run before there is a token.
+	OrganizationHeader = "X-Organization-ID"
+)
+
+// emailLookup returns the repository email lookups for organizationID go
+// through. EMAIL_UNIQUENESS=organization lets each tenant reuse an address,
+// by default an email exists once across every organization.
+func emailLookup(repo repository.UserRepository, organizationID string) repository.UserRepository {
+	if utils.GetEnv("EMAIL_UNIQUENESS", EmailUniqueGlobal) == EmailUniqueOrganization {
+		return repo.ForOrganization(organizationID)
+	}
+
+	return repo
+}
+
+type organizationService struct {
+	repo repository.OrganizationRepository
+	userRepo repository.UserRepository
+	issuer *tokenIssuer
+}
+
+func NewOrganizationService(repo repository.OrganizationRepository, userRepo repository.UserRepository, tokenService auth.TokenService, rabbitmqService rabbitmq.RabbitMQService) OrganizationService {
+	return &organizationService{
+		repo: repo,
+		userRepo: userRepo,
+		issuer: newTokenIssuer(userRepo, tokenService, rabbitmqService),
+	}
+}
+
+func (ors *organizationService) CreateOrganization(ctx *gin.Context, actor uuid.UUID, organization models.Organization) (models.Organization, error) {
+	organization.ID = uuid.NewString()
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = time.Now()
+
+	owner := models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserUUID: actor,
+		Role: models.OrganizationRoleOwner,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := ors.repo.Create(organization, owner); err != nil {
+		return models.Organization{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to create organization", err)
+	}
+
+	return organization, nil
+}
+
+func (ors *organizationService) ListOrganizations(ctx *gin.Context, actor uuid.UUID) ([]models.Organization, error) {
+	organizations, err := ors.repo.FindByUser(actor)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load organizations", err)
+	}
+
+	return organizations, nil
+}
+
+func (ors *organizationService) GetOrganization(ctx *gin.Context, actor uuid.UUID, id string) (models.Organization, error) {
+	if _, err := ors.requireRole(id, actor); err != nil {
+		return models.Organization{}, err
+	}
+
+	return ors.findOrganization(id)
+}
+
+func (ors *organizationService) UpdateOrganization(ctx *gin.Context, actor uuid.UUID, id string, organization models.Organization) (models.Organization, error) {
+	if _, err := ors.requireRole(id, actor, models.OrganizationRoleOwner, models.OrganizationRoleAdmin); err != nil {
+		return models.Organization{}, err
+	}
+
+	organization.ID = id
+	if _, err := ors.repo.Update(organization); err != nil {
+		return models.Organization{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to update organization", err)
+	}
+
+	return ors.findOrganization(id)
+}
+
+func (ors *organizationService) DeleteOrganization(ctx *gin.Context, actor uuid.UUID, id string) error {
+	if _, err := ors.requireRole(id, actor, models.OrganizationRoleOwner); err != nil {
+		return err
+	}
+
+	deleted, err := ors.repo.Delete(id)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete organization", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Organization not found")
+	}
+
+	return nil
+}
+
+func (ors *organizationService) ListMembers(ctx *gin.Context, actor uuid.UUID, id string) ([]models.OrganizationMember, error) {
+	if _, err := ors.requireRole(id, actor); err != nil {
+		return nil, err
+	}
+
+	members, err := ors.repo.FindMembers(id)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load members", err)
+	}
+
+	return members, nil
+}
+
+// SetMemberRole adds a user to the organization or changes their role. Admins
+// manage everyone below owner, ownership is only handed out by an owner.
+func (ors *organizationService) SetMemberRole(ctx *gin.Context, actor uuid.UUID, id string, userUUID uuid.UUID, role string) (models.OrganizationMember, error) {
+	current, err := ors.requireRole(id, actor, models.OrganizationRoleOwner, models.OrganizationRoleAdmin)
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+
+	member, found, err := ors.repo.FindMember(id, userUUID)
+	if err != nil {
+		return models.OrganizationMember{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load member", err)
+	}
+
+	// Only users of this tenant are added directly, anyone else joins by
+	// accepting an invitation.
+	users := ors.userRepo
+	if !found {
+		users = ors.userRepo.ForOrganization(id)
+	}
+
+	user, err := users.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return models.OrganizationMember{}, utils.NewError(string(utils.ErrCodeNotFound), "User not found")
+	}
+
+	if current.Role != models.OrganizationRoleOwner && (role == models.OrganizationRoleOwner || member.Role == models.OrganizationRoleOwner) {
+		return models.OrganizationMember{}, utils.NewError(string(utils.ErrCodeForbidden), "Only an owner can manage owners")
+	}
+
+	if found && member.Role == models.OrganizationRoleOwner && role != models.OrganizationRoleOwner {
+		if err := ors.keepOwner(id); err != nil {
+			return models.OrganizationMember{}, err
+		}
+	}
+
+	if !found {
+		member = models.OrganizationMember{
+			OrganizationID: id,
+			UserUUID: userUUID,
+			CreatedAt: time.Now(),
+		}
+	}
+	member.Role = role
+	member.UpdatedAt = time.Now()
+
+	if err := ors.repo.SaveMember(member); err != nil {
+		return models.OrganizationMember{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to save member", err)
+	}
+
+	return member, nil
+}
+
+// RemoveMember lets owners and admins remove others and anyone leave, as long
+// as the organization keeps an owner.
+func (ors *organizationService) RemoveMember(ctx *gin.Context, actor uuid.UUID, id string, userUUID uuid.UUID) error {
+	current, err := ors.requireRole(id, actor)
+	if err != nil {
+		return err
+	}
+
+	member, found, err := ors.repo.FindMember(id, userUUID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to load member", err)
+	}
+
+	if !found {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Member not found")
+	}
+
+	if actor != userUUID {
+		if current.Role != models.OrganizationRoleOwner && current.Role != models.OrganizationRoleAdmin {
+			return utils.NewError(string(utils.ErrCodeForbidden), "Access denied")
+		}
+
+		if current.Role != models.OrganizationRoleOwner && member.Role == models.OrganizationRoleOwner {
+			return utils.NewError(string(utils.ErrCodeForbidden), "Only an owner can manage owners")
+		}
+	}
+
+	if member.Role == models.OrganizationRoleOwner {
+		if err := ors.keepOwner(id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := ors.repo.RemoveMember(id, userUUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to remove member", err)
+	}
+
+	return nil
+}
+
+// SwitchOrganization issues a first-party token pair that acts in the given
+// organization.
+func (ors *organizationService) SwitchOrganization(ctx *gin.Context, actor uuid.UUID, id string) (v1dto.LoginResponse, error) {
+	if _, err := ors.requireRole(id, actor); err != nil {
+		return v1dto.LoginResponse{}, err
+	}
+
+	user, err := ors.userRepo.FindBYUUID(actor)
+	if err != nil || user.Email == "" {
+		return v1dto.LoginResponse{}, utils.NewError(string(utils.ErrCodeUnauthorized), "User not found.")
+	}
+
+	return ors.issuer.issueTokens(ctx, user, auth.TokenGrant{
+		OrganizationID: id,
+	})
+}
+
+func (ors *organizationService) findOrganization(id string) (models.Organization, error) {
+	organization, found, err := ors.repo.FindByID(id)
+	if err != nil {
+		return models.Organization{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load organization", err)
+	}
+
+	if !found {
+		return models.Organization{}, utils.NewError(string(utils.ErrCodeNotFound), "Organization not found")
+	}
+
+	return organization, nil
+}
+
+// requireRole returns the actor's membership, failing unless they hold one of
+// roles. Without roles any membership will do. Non-members get a 404 so
+// organization ids cannot be probed.
+func (ors *organizationService) requireRole(id string, actor uuid.UUID, roles ...string) (models.OrganizationMember, error) {
+	member, found, err := ors.repo.FindMember(id, actor)
+	if err != nil {
+		return models.OrganizationMember{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load member", err)
+	}
+
+	if !found {
+		return models.OrganizationMember{}, utils.NewError(string(utils.ErrCodeNotFound), "Organization not found")
+	}
+
+	if len(roles) > 0 && !slices.Contains(roles, member.Role) {
+		return models.OrganizationMember{}, utils.NewError(string(utils.ErrCodeForbidden), "Access denied")
+	}
+
+	return member, nil
+}
+
+func (ors *organizationService) keepOwner(id string) error {
+	owners, err := ors.repo.CountMembersWithRole(id, models.OrganizationRoleOwner)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to count owners", err)
+	}
+
+	if owners <= 1 {
+		return utils.NewError(string(utils.ErrCodeConflict), "An organization needs at least one owner")
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package v1service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var PermissionCacheTTL = 10 * time.Minute
+
+// Permissions are the permissions the routes and policies check, seeded at
+// startup so roles can be granted them.
+var Permissions = []models.Permission{
+	{Name: "users:read", Description: "List and view users"},
+	{Name: "users:write", Description: "Create and update users, manage their sessions"},
+	{Name: "users:delete", Description: "Delete and restore users"},
+	{Name: "roles:read", Description: "View roles and permissions"},
+	{Name: "roles:write", Description: "Manage roles and grant them"},
+	{Name: "groups:read", Description: "View groups"},
+	{Name: "groups:write", Description: "Manage groups and their members"},
+	{Name: "oauth_clients:write", Description: "Manage OAuth clients"},
+	{Name: "service_accounts:write", Description: "Manage service accounts"},
+}
+
+func SeedPermissions(repo repository.RBACRepository) error {
+	return repo.SeedPermissions(Permissions)
+}
+
+type rbacService struct {
+	repo repository.RBACRepository
+	userRepo repository.UserRepository
+	groupRepo repository.GroupRepository
+	cache cache.RedisCacheService
+}
+
+func NewRBACService(repo repository.RBACRepository, userRepo repository.UserRepository, groupRepo repository.GroupRepository, cacheService cache.RedisCacheService) RBACService {
+	return &rbacService{
+		repo: repo,
+		userRepo: userRepo,
+		groupRepo: groupRepo,
+		cache: cacheService,
+	}
+}
+
+func (rs *rbacService) ListRoles(ctx *gin.Context) ([]models.Role, error) {
+	roles, err := rs.repo.FindAllRoles()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load roles", err)
+	}
+
+	return roles, nil
+}
+
+func (rs *rbacService) GetRole(ctx *gin.Context, id int64) (models.Role, []models.Permission, error) {
+	role, err := rs.findRole(id)
+	if err != nil {
+		return models.Role{}, nil, err
+	}
+
+	permissions, err := rs.repo.FindRolePermissions(id)
+	if err != nil {
+		return models.Role{}, nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load role permissions", err)
+	}
+
+	return role, permissions, nil
+}
+
+func (rs *rbacService) CreateRole(ctx *gin.Context, role models.Role, permissions []string) (models.Role, error) {
+	permissionIDs, err := rs.resolvePermissions(permissions)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = time.Now()
+
+	role, err = rs.repo.CreateRole(role)
+	if err != nil {
+		return models.Role{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to create role", err)
+	}
+
+	if err := rs.repo.ReplaceRolePermissions(role.ID, permissionIDs); err != nil {
+		return models.Role{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to store role permissions", err)
+	}
+
+	return role, nil
+}
+
+func (rs *rbacService) UpdateRole(ctx *gin.Context, id int64, role models.Role) (models.Role, error) {
+	role.ID = id
+	updated, err := rs.repo.UpdateRole(role)
+	if err != nil {
+		return models.Role{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to update role", err)
+	}
+
+	if !updated {
+		return models.Role{}, utils.NewError(string(utils.ErrCodeNotFound), "Role not found")
+	}
+
+	return rs.findRole(id)
+}
+
+func (rs *rbacService) DeleteRole(ctx *gin.Context, id int64) error {
+	// Collected first, the assignments are gone once the role is.
+	userUUIDs, err := rs.roleHolders(id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := rs.repo.DeleteRole(id)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete role", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Role not found")
+	}
+
+	rs.invalidatePermissions(userUUIDs...)
+
+	return nil
+}
+
+func (rs *rbacService) SetRolePermissions(ctx *gin.Context, id int64, permissions []string) error {
+	if _, err := rs.findRole(id); err != nil {
+		return err
+	}
+
+	permissionIDs, err := rs.resolvePermissions(permissions)
+	if err != nil {
+		return err
+	}
+
+	if err := rs.repo.ReplaceRolePermissions(id, permissionIDs); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to store role permissions", err)
+	}
+
+	userUUIDs, err := rs.roleHolders(id)
+	if err != nil {
+		return err
+	}
+
+	rs.invalidatePermissions(userUUIDs...)
+
+	return nil
+}
+
+func (rs *rbacService) ListPermissions(ctx *gin.Context) ([]models.Permission, error) {
+	permissions, err := rs.repo.FindAllPermissions()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+	}
+
+	return permissions, nil
+}
+
+func (rs *rbacService) ListUserRoles(ctx *gin.Context, userUUID uuid.UUID) ([]models.Role, error) {
+	roles, err := rs.repo.FindUserRoles(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load user roles", err)
+	}
+
+	return roles, nil
+}
+
+func (rs *rbacService) AssignUserRole(ctx *gin.Context, userUUID uuid.UUID, roleID int64) error {
+	user, err := rs.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return utils.NewError(string(utils.ErrCodeNotFound), "User not found")
+	}
+
+	if _, err := rs.findRole(roleID); err != nil {
+		return err
+	}
+
+	if err := rs.repo.AssignUserRole(userUUID, roleID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to assign role", err)
+	}
+
+	rs.invalidatePermissions(userUUID)
+
+	return nil
+}
+
+func (rs *rbacService) RemoveUserRole(ctx *gin.Context, userUUID uuid.UUID, roleID int64) error {
+	removed, err := rs.repo.RemoveUserRole(userUUID, roleID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to remove role", err)
+	}
+
+	if !removed {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Role is not assigned to user")
+	}
+
+	rs.invalidatePermissions(userUUID)
+
+	return nil
+}
+
+// UserPermissions returns the permissions granted through the user's roles
+// and groups. Administrators from the legacy level column hold every permission.
+func (rs *rbacService) UserPermissions(userUUID uuid.UUID) ([]string, error) {
+	cacheKey := permissionCacheKey(userUUID)
+
+	var permissions []string
+	if err := rs.cache.Get(cacheKey, &permissions); err == nil {
+		return permissions, nil
+	}
+
+	user, err := rs.userRepo.FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return []string{}, nil
+	}
+
+	if user.Level == models.LevelAdmin {
+		all, err := rs.repo.FindAllPermissions()
+		if err != nil {
+			return nil, err
+		}
+
+		permissions = make([]string, 0, len(all))
+		for _, permission := range all {
+			permissions = append(permissions, permission.Name)
+		}
+	} else {
+		permissions, err = rs.repo.FindUserPermissionNames(userUUID)
+		if err != nil {
+			return nil, err
+		}
+
+		groupIDs, err := effectiveGroupIDs(rs.groupRepo, userUUID)
+		if err != nil {
+			return nil, err
+		}
+
+		inherited, err := rs.groupRepo.FindPermissionNames(groupIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, permission := range inherited {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	rs.cache.Set(cacheKey, permissions, PermissionCacheTTL)
+
+	return permissions, nil
+}
+
+func (rs *rbacService) HasPermission(userUUID uuid.UUID, permission string) (bool, error) {
+	permissions, err := rs.UserPermissions(userUUID)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(permissions, permission), nil
+}
+
+func (rs *rbacService) findRole(id int64) (models.Role, error) {
+	role, found, err := rs.repo.FindRoleByID(id)
+	if err != nil {
+		return models.Role{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load role", err)
+	}
+
+	if !found {
+		return models.Role{}, utils.NewError(string(utils.ErrCodeNotFound), "Role not found")
+	}
+
+	return role, nil
+}
+
+func (rs *rbacService) resolvePermissions(names []string) ([]int64, error) {
+	permissions, err := rs.repo.FindPermissionsByNames(names)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+	}
+
+	ids := make([]int64, 0, len(permissions))
+	for _, permission := range permissions {
+		ids = append(ids, permission.ID)
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(permissions, func(permission models.Permission) bool { return permission.Name == name }) {
+			return nil, utils.NewError(string(utils.ErrCodeBadRequest), "Unknown permission " + name)
+		}
+	}
+
+	return ids, nil
+}
+
+// roleHolders returns every user the role reaches, directly or through a group.
+func (rs *rbacService) roleHolders(roleID int64) ([]uuid.UUID, error) {
+	userUUIDs, err := rs.repo.FindUserUUIDsByRole(roleID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load role members", err)
+	}
+
+	groupIDs, err := rs.groupRepo.FindGroupIDsByRole(roleID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load role groups", err)
+	}
+
+	if len(groupIDs) == 0 {
+		return userUUIDs, nil
+	}
+
+	edges, err := rs.groupRepo.FindEdges()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group children", err)
+	}
+
+	reached := slices.Clone(groupIDs)
+	for _, groupID := range groupIDs {
+		reached = append(reached, descendantGroupIDs(edges, groupID)...)
+	}
+
+	members, err := rs.groupRepo.FindUserUUIDs(reached)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load group users", err)
+	}
+
+	return append(userUUIDs, members...), nil
+}
+
+func (rs *rbacService) invalidatePermissions(userUUIDs ...uuid.UUID) {
+	for _, userUUID := range userUUIDs {
+		rs.cache.Clear(permissionCacheKey(userUUID))
+	}
+}
+
+// changesPermissions reports whether a write to a user touches the columns
+// admin rights follow, which makes its cached permissions stale.
+func changesPermissions(changes map[string]any) bool {
+	_, level := changes["level"]
+	_, status := changes["status"]
+
+	return level || status
+}
+
+func permissionCacheKey(userUUID uuid.UUID) string {
+	return "rbac:permissions:" + userUUID.String()
+}
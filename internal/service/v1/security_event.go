@@ -0,0 +1,46 @@
+package v1service
+
+import (
+	"log"
+	"time"
+
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const securityEventQueue = "security_event_queue"
+
+const (
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+)
+
+type SecurityEvent struct {
+	Type 		string `json:"type"`
+	UserUUID 	uuid.UUID `json:"user_uuid"`
+	IP 			string `json:"ip"`
+	UserAgent 	string `json:"user_agent"`
+	Detail 		map[string]string `json:"detail,omitempty"`
+	OccurredAt 	time.Time `json:"occurred_at"`
+}
+
+func emitSecurityEvent(ctx *gin.Context, publisher rabbitmq.RabbitMQService, eventType string, userUUID uuid.UUID, detail map[string]string) {
+	event := SecurityEvent{
+		Type: eventType,
+		UserUUID: userUUID,
+		IP: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Detail: detail,
+		OccurredAt: time.Now(),
+	}
+
+	log.Printf("Security event %s: user=%s ip=%s detail=%v", event.Type, event.UserUUID, event.IP, event.Detail)
+
+	if publisher == nil {
+		return
+	}
+
+	if err := publisher.Publish(ctx, securityEventQueue, event); err != nil {
+		log.Printf("Failed to publish security event %s:%s", event.Type, err)
+	}
+}
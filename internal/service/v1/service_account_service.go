@@ -0,0 +1,173 @@
+package v1service
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	// Scopes a service account may be granted.
+	ServiceAccountScopes = []string{"users:read", "users:write"}
+	ServiceAccountSecretGracePeriod = 24 * time.Hour
+)
+
+type serviceAccountService struct {
+	repo repository.ServiceAccountRepository
+	tokenService auth.TokenService
+}
+
+func NewServiceAccountService(repo repository.ServiceAccountRepository, tokenService auth.TokenService) ServiceAccountService {
+	return &serviceAccountService{
+		repo: repo,
+		tokenService: tokenService,
+	}
+}
+
+func (ss *serviceAccountService) CreateServiceAccount(ctx *gin.Context, account models.ServiceAccount) (models.ServiceAccount, string, error) {
+	if !containsAll(ServiceAccountScopes, strings.Fields(account.Scopes)) {
+		return models.ServiceAccount{}, "", utils.NewError(string(utils.ErrCodeBadRequest), "Unsupported scope")
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return models.ServiceAccount{}, "", utils.NewError(string(utils.ErrCodeInternal), "Failed to generate client secret")
+	}
+
+	account.ClientID = uuid.NewString()
+	account.ClientSecretHash = hashClientSecret(secret)
+	account.Enabled = true
+	account.CreatedAt = time.Now()
+	account.UpdatedAt = time.Now()
+
+	if err := ss.repo.Create(account); err != nil {
+		return models.ServiceAccount{}, "", utils.WrapError(string(utils.ErrCodeInternal), "Failed to store service account", err)
+	}
+
+	return account, secret, nil
+}
+
+func (ss *serviceAccountService) ListServiceAccounts(ctx *gin.Context) ([]models.ServiceAccount, error) {
+	accounts, err := ss.repo.FindAll()
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load service accounts", err)
+	}
+
+	return accounts, nil
+}
+
+func (ss *serviceAccountService) GetServiceAccount(ctx *gin.Context, clientID string) (models.ServiceAccount, error) {
+	account, found, err := ss.repo.FindByClientID(clientID)
+	if err != nil {
+		return models.ServiceAccount{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load service account", err)
+	}
+
+	if !found {
+		return models.ServiceAccount{}, utils.NewError(string(utils.ErrCodeNotFound), "Service account not found")
+	}
+
+	return account, nil
+}
+
+func (ss *serviceAccountService) UpdateServiceAccount(ctx *gin.Context, clientID string, account models.ServiceAccount) (models.ServiceAccount, error) {
+	if !containsAll(ServiceAccountScopes, strings.Fields(account.Scopes)) {
+		return models.ServiceAccount{}, utils.NewError(string(utils.ErrCodeBadRequest), "Unsupported scope")
+	}
+
+	account.ClientID = clientID
+	updated, err := ss.repo.Update(account)
+	if err != nil {
+		return models.ServiceAccount{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to update service account", err)
+	}
+
+	if !updated {
+		return models.ServiceAccount{}, utils.NewError(string(utils.ErrCodeNotFound), "Service account not found")
+	}
+
+	return ss.GetServiceAccount(ctx, clientID)
+}
+
+func (ss *serviceAccountService) SetServiceAccountEnabled(ctx *gin.Context, clientID string, enabled bool) error {
+	updated, err := ss.repo.SetEnabled(clientID, enabled)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to update service account", err)
+	}
+
+	if !updated {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Service account not found")
+	}
+
+	if enabled {
+		err = ss.tokenService.EnableServicePrincipal(clientID)
+	} else {
+		err = ss.tokenService.DisableServicePrincipal(clientID)
+	}
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to update service account tokens", err)
+	}
+
+	return nil
+}
+
+// RotateServiceAccountSecret issues a new secret and keeps the current one
+// valid for ServiceAccountSecretGracePeriod.
+func (ss *serviceAccountService) RotateServiceAccountSecret(ctx *gin.Context, clientID string) (string, error) {
+	account, err := ss.GetServiceAccount(ctx, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", utils.NewError(string(utils.ErrCodeInternal), "Failed to generate client secret")
+	}
+
+	previousExpiresAt := time.Now().Add(ServiceAccountSecretGracePeriod)
+	updated, err := ss.repo.UpdateSecret(clientID, hashClientSecret(secret), &account.ClientSecretHash, &previousExpiresAt)
+	if err != nil {
+		return "", utils.WrapError(string(utils.ErrCodeInternal), "Unable to rotate secret", err)
+	}
+
+	if !updated {
+		return "", utils.NewError(string(utils.ErrCodeNotFound), "Service account not found")
+	}
+
+	return secret, nil
+}
+
+func (ss *serviceAccountService) DeleteServiceAccount(ctx *gin.Context, clientID string) error {
+	deleted, err := ss.repo.Delete(clientID)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to delete service account", err)
+	}
+
+	if !deleted {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Service account not found")
+	}
+
+	if err := ss.tokenService.DisableServicePrincipal(clientID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke service account tokens", err)
+	}
+
+	return nil
+}
+
+func verifyServiceAccountSecret(account models.ServiceAccount, secret string) bool {
+	secretHash := []byte(hashClientSecret(secret))
+	if subtle.ConstantTimeCompare(secretHash, []byte(account.ClientSecretHash)) == 1 {
+		return true
+	}
+
+	if account.PreviousSecretHash == nil || account.PreviousSecretExpiresAt == nil || account.PreviousSecretExpiresAt.Before(time.Now()) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(secretHash, []byte(*account.PreviousSecretHash)) == 1
+}
@@ -0,0 +1,109 @@
+package v1service
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type sessionService struct {
+	tokenService auth.TokenService
+	userRepo repository.UserRepository
+	rbac RBACService
+}
+
+func NewSessionService(tokenService auth.TokenService, userRepo repository.UserRepository, rbac RBACService) SessionService {
+	return &sessionService{
+		tokenService: tokenService,
+		userRepo: userRepo,
+		rbac: rbac,
+	}
+}
+
+// authorize lets callers manage their own sessions. Anyone else's take the
+// users:write permission and a user of the caller's organization, whatever
+// the route in front of the service checks.
+func (ss *sessionService) authorize(ctx *gin.Context, userUUID uuid.UUID) error {
+	if _, ok := auth.GetServicePayload(ctx); ok {
+		if scopes, _ := auth.GetScopes(ctx); !slices.Contains(scopes, "users:write") {
+			return utils.NewError(string(utils.ErrCodeForbidden), "Access denied")
+		}
+		return nil
+	}
+
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		return utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized")
+	}
+
+	if payload.UserUUID == userUUID {
+		return nil
+	}
+
+	granted, err := ss.rbac.HasPermission(payload.UserUUID, "users:write")
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to check permissions", err)
+	}
+
+	if !granted {
+		return utils.NewError(string(utils.ErrCodeForbidden), "Access denied")
+	}
+
+	user, err := ss.userRepo.ForOrganization(auth.GetOrganizationID(ctx)).FindBYUUID(userUUID)
+	if err != nil || user.Email == "" {
+		return utils.NewError(string(utils.ErrCodeNotFound), "User not found")
+	}
+
+	return nil
+}
+
+func (ss *sessionService) ListSessions(ctx *gin.Context, userUUID uuid.UUID) ([]auth.RefreshFamily, error) {
+	if err := ss.authorize(ctx, userUUID); err != nil {
+		return nil, err
+	}
+
+	families, err := ss.tokenService.ListRefreshFamilies(userUUID)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load sessions", err)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].LastRefreshedAt.After(families[j].LastRefreshedAt)
+	})
+
+	return families, nil
+}
+
+func (ss *sessionService) RevokeSession(ctx *gin.Context, userUUID uuid.UUID, sessionID string) error {
+	if err := ss.authorize(ctx, userUUID); err != nil {
+		return err
+	}
+
+	family, err := ss.tokenService.GetRefreshFamily(sessionID)
+	if err != nil || family.UserUUID != userUUID || family.Revoked {
+		return utils.NewError(string(utils.ErrCodeNotFound), "Session not found")
+	}
+
+	if _, err := ss.tokenService.RevokeRefreshFamily(sessionID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke session", err)
+	}
+
+	return nil
+}
+
+func (ss *sessionService) RevokeAllSessions(ctx *gin.Context, userUUID uuid.UUID) error {
+	if err := ss.authorize(ctx, userUUID); err != nil {
+		return err
+	}
+
+	if err := ss.tokenService.RevokeUserRefreshFamilies(userUUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
+
+	return nil
+}
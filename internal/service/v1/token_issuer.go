@@ -0,0 +1,250 @@
+package v1service
+
+import (
+	"log"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenIssuer is shared by every flow that ends in an access/refresh token pair,
+// first-party logins and OAuth grants alike, so rotation and reuse detection
+// behave the same everywhere.
+type tokenIssuer struct {
+	userRepo repository.UserRepository
+	tokenService auth.TokenService
+	rabbitmqService rabbitmq.RabbitMQService
+}
+
+func newTokenIssuer(userRepo repository.UserRepository, tokenService auth.TokenService, rabbitmqService rabbitmq.RabbitMQService) *tokenIssuer {
+	return &tokenIssuer{
+		userRepo: userRepo,
+		tokenService: tokenService,
+		rabbitmqService: rabbitmqService,
+	}
+}
+
+func getClientIP(ctx *gin.Context) string {
+	ip := ctx.ClientIP()
+	if ip == "" {
+		ip = ctx.Request.RemoteAddr
+	}
+	return ip
+}
+
+// issueTokens starts a new family. Without an explicit organization the tokens
+// act in the user's home tenant.
+func (ti *tokenIssuer) issueTokens(ctx *gin.Context, user models.User, grant auth.TokenGrant) (v1dto.LoginResponse, error) {
+	if grant.OrganizationID == "" && user.OrganizationID != nil {
+		grant.OrganizationID = *user.OrganizationID
+	}
+
+	refreshToken, err := ti.tokenService.GenerateRefreshToken(user, grant)
+	if err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create refresh token", err)
+	}
+
+	family := auth.RefreshFamily{
+		ID: refreshToken.FamilyID,
+		UserUUID: user.UUID,
+		ClientID: grant.ClientID,
+		UserAgent: ctx.Request.UserAgent(),
+		IP: getClientIP(ctx),
+		CreatedAt: time.Now(),
+	}
+
+	return ti.issueFamilyTokens(user, refreshToken, family)
+}
+
+// issueFamilyTokens hands out an access token together with refreshToken and
+// records both on the family so the pair can be revoked as one.
+func (ti *tokenIssuer) issueFamilyTokens(user models.User, refreshToken auth.RefreshToken, family auth.RefreshFamily) (v1dto.LoginResponse, error) {
+	grant := auth.TokenGrant{
+		ClientID: refreshToken.ClientID,
+		Scopes: refreshToken.Scopes,
+		OrganizationID: refreshToken.OrganizationID,
+	}
+
+	accessToken, err := ti.tokenService.GenerateAccessToken(user, grant)
+	if err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create access token", err)
+	}
+
+	_, claims, err := ti.tokenService.ParseToken(accessToken)
+	if err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to read access token", err)
+	}
+
+	family.AccessJTI, family.AccessExpiresAt = accessTokenID(claims)
+	family.LastRefreshedAt = time.Now()
+	family.ExpiresAt = refreshToken.ExpiresAt
+
+	if err := ti.tokenService.StoreRefreshFamily(family); err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot save refresh token family", err)
+	}
+
+	if err := ti.tokenService.StoreRefreshToken(refreshToken); err != nil {
+		return v1dto.LoginResponse{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot save refresh token", err)
+	}
+
+	return v1dto.LoginResponse{
+		AccessToken: accessToken,
+		RefreshToken: refreshToken.Token,
+		ExpiresIn: int(auth.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// recordLogin stamps a completed sign-in, failing to do so does not fail the login.
+func (ti *tokenIssuer) recordLogin(user models.User) {
+	if err := ti.userRepo.TouchLastLogin(user.UUID); err != nil {
+		log.Printf("Failed to record login for user %s:%s", user.UUID, err)
+	}
+}
+
+func accessTokenID(claims jwt.MapClaims) (string, time.Time) {
+	jti, _ := claims["jti"].(string)
+	expUnix, _ := claims["exp"].(float64)
+
+	return jti, time.Unix(int64(expUnix), 0)
+}
+
+// revokeAccessToken blacklists the token until it would have expired anyway.
+func (ti *tokenIssuer) revokeAccessToken(claims jwt.MapClaims) error {
+	jti, exp := accessTokenID(claims)
+	if jti == "" {
+		return nil
+	}
+
+	if err := ti.tokenService.BlacklistAccessToken(jti, exp); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Cannot to revoke access token", err)
+	}
+
+	return nil
+}
+
+// revokeRefreshToken revokes the token together with the family it belongs to.
+func (ti *tokenIssuer) revokeRefreshToken(token auth.RefreshToken) error {
+	if err := ti.tokenService.RevokeRefreshToken(token.Token); err != nil {
+		return utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot to revoke refresh token", err)
+	}
+
+	if token.FamilyID != "" {
+		if _, err := ti.tokenService.RevokeRefreshFamily(token.FamilyID); err != nil {
+			return utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot to revoke refresh token family", err)
+		}
+	}
+
+	return nil
+}
+
+// rotate exchanges a refresh token for a new pair in the same family. A token
+// only rotates for the client it was issued to, first-party tokens have none.
+func (ti *tokenIssuer) rotate(ctx *gin.Context, refreshTokenString, clientID string) (v1dto.LoginResponse, auth.RefreshToken, error) {
+	invalid := utils.NewError(string(utils.ErrCodeUnauthorized),"Refresh token is invalid or revoked.")
+
+	token, err := ti.tokenService.GetRefreshToken(refreshTokenString)
+	if err != nil {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, invalid
+	}
+
+	if token.ClientID != clientID {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, invalid
+	}
+
+	if token.Revoked {
+		ti.handleRefreshTokenReuse(ctx, token)
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, invalid
+	}
+
+	if token.ExpiresAt.Before(time.Now()) {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, invalid
+	}
+
+	// A concurrent refresh that lost the claim presented a token that is being
+	// rotated, which is reuse all the same.
+	claimed, err := ti.tokenService.ClaimRefreshToken(token)
+	if err != nil {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, utils.WrapError(string(utils.ErrCodeInternal), "Cannot claim refresh token", err)
+	}
+
+	if !claimed {
+		ti.handleRefreshTokenReuse(ctx, token)
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, invalid
+	}
+
+	family := auth.RefreshFamily{
+		UserUUID: token.UserUUID,
+		ClientID: token.ClientID,
+		UserAgent: ctx.Request.UserAgent(),
+		IP: getClientIP(ctx),
+		CreatedAt: time.Now(),
+	}
+	if token.FamilyID != "" {
+		family, err = ti.tokenService.GetRefreshFamily(token.FamilyID)
+		if err != nil || family.Revoked {
+			return v1dto.LoginResponse{}, auth.RefreshToken{}, invalid
+		}
+	}
+
+	user, err := ti.userRepo.FindBYUUID(token.UserUUID)
+	if err != nil || user.Email == "" {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, utils.NewError(string(utils.ErrCodeUnauthorized),"User not found.")
+	}
+
+	refreshToken, err := ti.tokenService.GenerateRefreshToken(user, auth.TokenGrant{
+		ClientID: token.ClientID,
+		Scopes: token.Scopes,
+		OrganizationID: token.OrganizationID,
+	})
+	if err != nil {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to create refresh token", err)
+	}
+
+	// Tokens issued before families existed start a new family on their first rotation.
+	if token.FamilyID != "" {
+		refreshToken.FamilyID = token.FamilyID
+	}
+	family.ID = refreshToken.FamilyID
+
+	if err := ti.tokenService.RevokeRefreshToken(refreshTokenString); err != nil {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Cannot to revoke refresh token", err)
+	}
+
+	response, err := ti.issueFamilyTokens(user, refreshToken, family)
+	if err != nil {
+		return v1dto.LoginResponse{}, auth.RefreshToken{}, err
+	}
+
+	return response, refreshToken, nil
+}
+
+// handleRefreshTokenReuse runs when an already rotated refresh token comes back.
+// Either the legitimate client or an attacker holds a stolen copy, and we cannot
+// tell which, so the whole family and its live access token are revoked.
+func (ti *tokenIssuer) handleRefreshTokenReuse(ctx *gin.Context, token auth.RefreshToken) {
+	detail := map[string]string{
+		"family_id": token.FamilyID,
+	}
+
+	if token.ClientID != "" {
+		detail["client_id"] = token.ClientID
+	}
+
+	if token.FamilyID != "" {
+		family, err := ti.tokenService.RevokeRefreshFamily(token.FamilyID)
+		if err != nil {
+			log.Printf("Failed to revoke refresh token family %s:%s", token.FamilyID, err)
+		} else {
+			detail["access_jti"] = family.AccessJTI
+		}
+	}
+
+	emitSecurityEvent(ctx, ti.rabbitmqService, SecurityEventRefreshTokenReuse, token.UserUUID, detail)
+}
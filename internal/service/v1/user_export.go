@@ -0,0 +1,638 @@
+package v1service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/policy"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/xlsx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const UserExportQueue = "user_export_queue"
+
+var (
+	// Larger exports are queued even when the caller asked for a stream.
+	MaxUserExportStreamRows int64 = 50000
+	UserExportFileTTL = 24 * time.Hour
+	UserExportLinkTTL = 15 * time.Minute
+)
+
+// userExportColumns are the columns an export can hold, in the order of
+// UserDTO. The password hash and pending email never leave the service.
+var userExportColumns = []string{
+	"uuid",
+	"name",
+	"email",
+	"age",
+	"level",
+	"status",
+	"organization_id",
+	"created_at",
+	"updated_at",
+	"last_login_at",
+	"email_verified_at",
+	"password_changed_at",
+}
+
+var defaultUserExportColumns = []string{"uuid", "name", "email", "age", "level", "status", "created_at"}
+
+var userExportContentTypes = map[string]string{
+	"csv": "text/csv; charset=utf-8",
+	"ndjson": v1dto.MediaTypeNDJSON,
+	"xlsx": xlsx.ContentType,
+}
+
+func UserExportContentType(format string) string {
+	return userExportContentTypes[format]
+}
+
+func userExportKey(id string) string {
+	return "user:export:" + id
+}
+
+func userExportDir() string {
+	return utils.GetEnv("USER_EXPORT_DIR", filepath.Join(os.TempDir(), "user-exports"))
+}
+
+func userExportPath(job models.UserExportJob) string {
+	return filepath.Join(userExportDir(), job.ID + "." + job.Format)
+}
+
+// userExportSigningKey signs download links. It has no fallback, without it
+// exports are never written to files.
+func userExportSigningKey() []byte {
+	return []byte(utils.GetEnv("EXPORT_SIGNING_KEY", ""))
+}
+
+// UserExport is an export ready to be streamed, or the job it was queued as
+// when Job is set.
+type UserExport struct {
+	ContentType string
+	FileName 	string
+	Job 		*models.UserExportJob
+	write 		func(w io.Writer) (int, error)
+}
+
+// Write streams the export to w and returns the number of rows written. Once
+// it fails w holds a truncated file, the status has already been sent.
+func (export *UserExport) Write(w io.Writer) (int, error) {
+	return export.write(w)
+}
+
+type userExportMessage struct {
+	JobID 	string `json:"job_id"`
+	Subject policy.Subject `json:"subject"`
+	IP 		string `json:"ip"`
+	Page 	repository.UserPage `json:"page"`
+	// Service accounts export every tenant, anyone else the one they act in.
+	AllOrganizations 	bool `json:"all_organizations"`
+	OrganizationID 		string `json:"organization_id"`
+}
+
+// ExportUsers exports the users the caller may read, filtered and ordered
+// like a listing. Rows are read from the database as they are written, so an
+// export of any size streams in constant memory. Exports over
+// MaxUserExportStreamRows, or asked for with Async, are written to a file in
+// the background instead, which needs EXPORT_SIGNING_KEY.
+func (us *userService) ExportUsers(ctx *gin.Context, query v1dto.UserExportQuery) (*UserExport, error) {
+	subject, err := us.subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := userFilterPage(query.UserFilterQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := parseUserExportColumns(query.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	format := query.Format
+	if format == "" {
+		format = "csv"
+	}
+
+	repo := us.users(ctx)
+	if !query.Async {
+		count, err := repo.Count(page.Filter)
+		if err != nil {
+			return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile count users.", err)
+		}
+
+		if count <= MaxUserExportStreamRows {
+			iterator, err := repo.Iterate(page)
+			if err != nil {
+				return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile fetch users.", err)
+			}
+
+			return &UserExport{
+				ContentType: userExportContentTypes[format],
+				FileName: userExportFileName(format, time.Now()),
+				write: func(w io.Writer) (int, error) {
+					return writeUserExport(w, iterator, format, columns, func(user models.User) bool {
+						return us.decide(ctx, subject, "users:read", user, nil).Allowed
+					})
+				},
+			}, nil
+		}
+	}
+
+	if len(userExportSigningKey()) == 0 {
+		return nil, utils.NewError(string(utils.ErrCodeInternal), "Async exports are disabled, EXPORT_SIGNING_KEY is not set.")
+	}
+
+	job := models.UserExportJob{
+		ID: uuid.NewString(),
+		Status: models.JobQueued,
+		Format: format,
+		Columns: columns,
+		OrganizationID: newUserOrganizationID(ctx),
+		RequestedBy: userExportRequester(subject),
+		CreatedAt: time.Now(),
+	}
+
+	if err := us.cache.Set(userExportKey(job.ID), job, UserExportFileTTL); err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Unable to store export job", err)
+	}
+
+	_, allOrganizations := auth.GetServicePayload(ctx)
+	if err := us.rabbitmqService.Publish(ctx, UserExportQueue, userExportMessage{
+		JobID: job.ID,
+		Subject: subject,
+		IP: getClientIP(ctx),
+		Page: page,
+		AllOrganizations: allOrganizations,
+		OrganizationID: auth.GetOrganizationID(ctx),
+	}); err != nil {
+		us.cache.Clear(userExportKey(job.ID))
+		return nil, utils.NewError(string(utils.ErrCodeInternal), "Failed to queue export.")
+	}
+
+	return &UserExport{
+		ContentType: userExportContentTypes[format],
+		FileName: job.FileName,
+		Job: &job,
+	}, nil
+}
+
+// GetExportJob returns the job with a fresh download link once its file is
+// written. Only the caller who started it finds it.
+func (us *userService) GetExportJob(ctx *gin.Context, id string) (models.UserExportJob, string, error) {
+	notFound := utils.NewError(string(utils.ErrCodeNotFound), "Export job not found")
+
+	var job models.UserExportJob
+	if err := us.cache.Get(userExportKey(id), &job); err != nil || job.ID == "" {
+		return models.UserExportJob{}, "", notFound
+	}
+
+	subject, err := us.subject(ctx)
+	if err != nil {
+		return models.UserExportJob{}, "", err
+	}
+
+	if job.RequestedBy != userExportRequester(subject) || !jobVisible(ctx, job.OrganizationID) {
+		return models.UserExportJob{}, "", notFound
+	}
+
+	if job.Status != models.JobCompleted || job.ExpiresAt == nil {
+		return job, "", nil
+	}
+
+	expires := time.Now().Add(UserExportLinkTTL)
+	if job.ExpiresAt.Before(expires) {
+		expires = *job.ExpiresAt
+	}
+
+	return job, userExportDownloadURL(job.ID, expires), nil
+}
+
+// OpenUserExport checks a download link and returns the job with the path of
+// its file. The link is all a download needs, it is signed so it cannot be
+// pointed at another job or kept past its expiry.
+func (us *userService) OpenUserExport(id string, expires int64, signature string) (models.UserExportJob, string, error) {
+	invalid := utils.NewError(string(utils.ErrCodeNotFound), "Link invalid or expried.")
+
+	key := userExportSigningKey()
+	if len(key) == 0 || time.Now().Unix() > expires || !utils.VerifySignature(key, signature, id, strconv.FormatInt(expires, 10)) {
+		return models.UserExportJob{}, "", invalid
+	}
+
+	var job models.UserExportJob
+	if err := us.cache.Get(userExportKey(id), &job); err != nil || job.Status != models.JobCompleted {
+		return models.UserExportJob{}, "", invalid
+	}
+
+	path := userExportPath(job)
+	if _, err := os.Stat(path); err != nil {
+		return models.UserExportJob{}, "", invalid
+	}
+
+	return job, path, nil
+}
+
+func userExportDownloadURL(id string, expires time.Time) string {
+	timestamp := strconv.FormatInt(expires.Unix(), 10)
+
+	return fmt.Sprintf("%s/%s?expires=%s&signature=%s",
+		strings.TrimRight(utils.GetEnv("EXPORT_DOWNLOAD_URL", "http://localhost:8080/exports/users"), "/"),
+		id,
+		timestamp,
+		utils.Sign(userExportSigningKey(), id, timestamp),
+	)
+}
+
+func userExportRequester(subject policy.Subject) string {
+	if subject.PrincipalType == auth.PrincipalService {
+		return subject.ClientID
+	}
+
+	return subject.UserUUID.String()
+}
+
+func userExportFileName(format string, at time.Time) string {
+	return "users-" + at.UTC().Format("20060102-150405") + "." + format
+}
+
+func parseUserExportColumns(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return defaultUserExportColumns, nil
+	}
+
+	columns := make([]string, 0)
+	for column := range strings.SplitSeq(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(userExportColumns, column) {
+			return nil, utils.NewError(string(utils.ErrCodeBadRequest), fmt.Sprintf("Unknown column %q, choose from %s", column, strings.Join(userExportColumns, ", ")))
+		}
+		if slices.Contains(columns, column) {
+			return nil, utils.NewError(string(utils.ErrCodeBadRequest), fmt.Sprintf("Duplicate column %q", column))
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// userExportValues reads columns off the user as a listing shows it. Missing
+// values are nil, times are RFC 3339 text.
+func userExportValues(user models.User, columns []string) []any {
+	dto := v1dto.MapUserDTO(user)
+	optional := func(value *string) any {
+		if value == nil {
+			return nil
+		}
+		return *value
+	}
+	timestamp := func(value *time.Time) any {
+		if value == nil {
+			return nil
+		}
+		return value.UTC().Format(time.RFC3339)
+	}
+
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "uuid":
+			values[i] = dto.UUID.String()
+		case "name":
+			values[i] = dto.Name
+		case "email":
+			values[i] = dto.Email
+		case "age":
+			values[i] = dto.Age
+		case "level":
+			values[i] = dto.Level
+		case "status":
+			values[i] = dto.Status
+		case "organization_id":
+			values[i] = optional(dto.OrganizationID)
+		case "created_at":
+			values[i] = timestamp(&dto.CreatedAt)
+		case "updated_at":
+			values[i] = timestamp(&dto.UpdatedAt)
+		case "last_login_at":
+			values[i] = timestamp(dto.LastLoginAt)
+		case "email_verified_at":
+			values[i] = timestamp(dto.EmailVerifiedAt)
+		case "password_changed_at":
+			values[i] = timestamp(dto.PasswordChangedAt)
+		}
+	}
+
+	return values
+}
+
+// userExportEncoder writes one format, header takes the column names.
+type userExportEncoder interface {
+	header(columns []string) error
+	row(values []any) error
+	close() error
+}
+
+func newUserExportEncoder(w io.Writer, format string) (userExportEncoder, error) {
+	switch format {
+	case "ndjson":
+		return &ndjsonUserExport{writer: bufio.NewWriter(w)}, nil
+	case "xlsx":
+		writer, err := xlsx.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxUserExport{writer: writer}, nil
+	default:
+		return &csvUserExport{writer: csv.NewWriter(w)}, nil
+	}
+}
+
+// writeUserExport drains iterator into w, skipping the users visible rejects,
+// and closes the iterator.
+func writeUserExport(w io.Writer, iterator repository.UserIterator, format string, columns []string, visible func(models.User) bool) (int, error) {
+	defer iterator.Close()
+
+	encoder, err := newUserExportEncoder(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := encoder.header(columns); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	for iterator.Next() {
+		user, err := iterator.User()
+		if err != nil {
+			return rows, err
+		}
+
+		if !visible(user) {
+			continue
+		}
+
+		if err := encoder.row(userExportValues(user, columns)); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+
+	if err := iterator.Err(); err != nil {
+		return rows, err
+	}
+
+	return rows, encoder.close()
+}
+
+type csvUserExport struct {
+	writer *csv.Writer
+}
+
+func (e *csvUserExport) header(columns []string) error {
+	return e.writer.Write(columns)
+}
+
+func (e *csvUserExport) row(values []any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch value := value.(type) {
+		case nil:
+		case string:
+			// Spreadsheets run cells starting with these as formulas.
+			if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+				value = "'" + value
+			}
+			record[i] = value
+		default:
+			record[i] = fmt.Sprint(value)
+		}
+	}
+
+	return e.writer.Write(record)
+}
+
+func (e *csvUserExport) close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// ndjsonUserExport writes each row as an object with its keys in column
+// order, which a map would lose.
+type ndjsonUserExport struct {
+	writer 	*bufio.Writer
+	keys 	[][]byte
+}
+
+func (e *ndjsonUserExport) header(columns []string) error {
+	e.keys = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = key
+	}
+
+	return nil
+}
+
+func (e *ndjsonUserExport) row(values []any) error {
+	e.writer.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			e.writer.WriteByte(',')
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		e.writer.Write(e.keys[i])
+		e.writer.WriteByte(':')
+		e.writer.Write(encoded)
+	}
+
+	_, err := e.writer.WriteString("}\n")
+	return err
+}
+
+func (e *ndjsonUserExport) close() error {
+	return e.writer.Flush()
+}
+
+type xlsxUserExport struct {
+	writer *xlsx.Writer
+}
+
+func (e *xlsxUserExport) header(columns []string) error {
+	cells := make([]any, len(columns))
+	for i, column := range columns {
+		cells[i] = column
+	}
+
+	return e.writer.WriteRow(cells)
+}
+
+func (e *xlsxUserExport) row(values []any) error {
+	return e.writer.WriteRow(values)
+}
+
+func (e *xlsxUserExport) close() error {
+	return e.writer.Close()
+}
+
+// UserExportWorker writes queued exports to files under USER_EXPORT_DIR, which
+// the API has to share when it runs apart from the worker.
+type UserExportWorker struct {
+	repo repository.UserRepository
+	policy *policy.Engine
+	cache cache.RedisCacheService
+}
+
+func NewUserExportWorker(repo repository.UserRepository, policyEngine *policy.Engine, cacheService cache.RedisCacheService) *UserExportWorker {
+	return &UserExportWorker{
+		repo: repo,
+		policy: policyEngine,
+		cache: cacheService,
+	}
+}
+
+// Handle consumes UserExportQueue. Rows are checked against the subject as it
+// stood when the export was asked for.
+func (w *UserExportWorker) Handle(body []byte) error {
+	var message userExportMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		log.Printf("Failed to unmarshal export message:%s", err)
+		return err
+	}
+
+	var job models.UserExportJob
+	if err := w.cache.Get(userExportKey(message.JobID), &job); err != nil || job.ID == "" {
+		log.Printf("Failed to load export job %s:%v", message.JobID, err)
+		return fmt.Errorf("export job %s not found", message.JobID)
+	}
+
+	if job.Status == models.JobCompleted || job.Status == models.JobFailed {
+		return nil
+	}
+
+	w.sweep()
+
+	startedAt := time.Now()
+	job.Status = models.JobRunning
+	job.StartedAt = &startedAt
+	w.save(job)
+
+	rows, err := w.write(job, message)
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		log.Printf("Failed to export users for job %s:%s", job.ID, err)
+		job.Status = models.JobFailed
+		job.Error = errorMessage(err)
+		w.save(job)
+
+		return nil
+	}
+
+	expiresAt := finishedAt.Add(UserExportFileTTL)
+	job.Status = models.JobCompleted
+	job.Rows = rows
+	job.FileName = userExportFileName(job.Format, finishedAt)
+	job.ExpiresAt = &expiresAt
+	w.save(job)
+
+	return nil
+}
+
+// write fills a temporary file and moves it in place, a download never sees
+// half an export.
+func (w *UserExportWorker) write(job models.UserExportJob, message userExportMessage) (int, error) {
+	repo := w.repo
+	if !message.AllOrganizations {
+		repo = repo.ForOrganization(message.OrganizationID)
+	}
+
+	if err := os.MkdirAll(userExportDir(), 0o700); err != nil {
+		return 0, err
+	}
+
+	file, err := os.CreateTemp(userExportDir(), job.ID + "-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	iterator, err := repo.Iterate(message.Page)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := writeUserExport(file, iterator, job.Format, job.Columns, func(user models.User) bool {
+		return w.policy.Evaluate(policy.Request{
+			Subject: message.Subject,
+			Action: "users:read",
+			Resource: user,
+			Environment: policy.Environment{
+				IP: message.IP,
+				Time: time.Now(),
+			},
+		}).Allowed
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	if err := file.Close(); err != nil {
+		return rows, err
+	}
+
+	return rows, os.Rename(file.Name(), userExportPath(job))
+}
+
+// sweep removes the files whose jobs have expired.
+func (w *UserExportWorker) sweep() {
+	entries, err := os.ReadDir(userExportDir())
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < UserExportFileTTL {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(userExportDir(), entry.Name())); err != nil {
+			log.Printf("Failed to remove export %s:%s", entry.Name(), err)
+		}
+	}
+}
+
+func (w *UserExportWorker) save(job models.UserExportJob) {
+	if err := w.cache.Set(userExportKey(job.ID), job, UserExportFileTTL); err != nil {
+		log.Printf("Failed to save export job %s:%s", job.ID, err)
+	}
+}
@@ -0,0 +1,585 @@
+package v1service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/policy"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/internal/validation"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/mail"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+const UserImportQueue = "user_import_queue"
+
+var (
+	MaxUserImportBytes int64 = 10 << 20
+	MaxUserImportRows = 10000
+	// A job keeps the first errors only, its counters stay exact.
+	MaxUserImportErrors = 1000
+	UserImportJobTTL = 7 * 24 * time.Hour
+	UserImportInviteTTL = 7 * 24 * time.Hour
+	userImportBatchSize = 500
+	// How long a consumer may hold a job while it adds the counts of a batch.
+	userImportLockTTL = 10 * time.Second
+	userImportLockWait = 50 * time.Millisecond
+)
+
+func userImportKey(id string) string {
+	return "user:import:" + id
+}
+
+// userImportTask is a row that passed validation and the policies. UUID and
+// Version are set when it updates the user that already has its email, Fields
+// then lists the columns it writes. The password is only ever held by the
+// queued message, the worker hashes it as it stores the user.
+type userImportTask struct {
+	Line 		int `json:"line"`
+	Name 		string `json:"name"`
+	Email 		string `json:"email"`
+	Password 	string `json:"password,omitempty"`
+	Age 		int16 `json:"age"`
+	Status 		int8 `json:"status"`
+	Level 		int8 `json:"level"`
+	UUID 		*uuid.UUID `json:"uuid,omitempty"`
+	Version 	int64 `json:"version,omitempty"`
+	Fields 		[]string `json:"fields,omitempty"`
+}
+
+type userImportMessage struct {
+	JobID string `json:"job_id"`
+	Tasks []userImportTask `json:"tasks"`
+}
+
+func newUserImportTask(line int, user models.User) userImportTask {
+	return userImportTask{
+		Line: line,
+		Name: user.Name,
+		Email: user.Email,
+		Password: user.Password,
+		Age: user.Age,
+		Status: user.Status,
+		Level: user.Level,
+	}
+}
+
+func (task userImportTask) user(organizationID *string) models.User {
+	return models.User{
+		Name: task.Name,
+		Email: task.Email,
+		Password: task.Password,
+		Age: task.Age,
+		Status: task.Status,
+		Level: task.Level,
+		OrganizationID: organizationID,
+	}
+}
+
+func (task userImportTask) changes() map[string]any {
+	changes := make(map[string]any, len(task.Fields))
+	for _, field := range task.Fields {
+		switch field {
+		case "name":
+			changes[field] = task.Name
+		case "age":
+			changes[field] = task.Age
+		case "status":
+			changes[field] = task.Status
+		case "level":
+			changes[field] = task.Level
+		}
+	}
+
+	return changes
+}
+
+// ImportUsers checks every row of the file the way CreateUser checks its
+// input. A dry run stops there and reports what the import would do, otherwise
+// the rows that passed are queued and the returned job tracks them.
+func (us *userService) ImportUsers(ctx *gin.Context, input v1dto.UserImport) (models.UserImportJob, error) {
+	rows, err := parseUserImport(input.MediaType, input.Body)
+	if err != nil {
+		return models.UserImportJob{}, err
+	}
+
+	subject, err := us.subject(ctx)
+	if err != nil {
+		return models.UserImportJob{}, err
+	}
+
+	job := models.UserImportJob{
+		Status: models.JobQueued,
+		DryRun: input.DryRun,
+		OnDuplicate: input.OnDuplicate,
+		Invite: input.Invite,
+		OrganizationID: newUserOrganizationID(ctx),
+		Total: len(rows),
+		Errors: make([]models.UserImportRowError, 0),
+		CreatedAt: time.Now(),
+	}
+	if job.OnDuplicate == "" {
+		job.OnDuplicate = models.UserImportSkipDuplicates
+	}
+
+	tasks, err := us.planUserImport(ctx, subject, &job, rows)
+	if err != nil {
+		return models.UserImportJob{}, err
+	}
+
+	if job.DryRun {
+		for _, task := range tasks {
+			if task.UUID != nil {
+				job.Updated++
+			} else {
+				job.Created++
+			}
+		}
+		job.Status = models.JobCompleted
+
+		return job, nil
+	}
+
+	job.ID = uuid.NewString()
+	if len(tasks) == 0 {
+		now := time.Now()
+		job.Status = models.JobCompleted
+		job.FinishedAt = &now
+	}
+
+	if err := us.cache.Set(userImportKey(job.ID), job, UserImportJobTTL); err != nil {
+		return models.UserImportJob{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to store import job", err)
+	}
+
+	for start := 0; start < len(tasks); start += userImportBatchSize {
+		if err := us.rabbitmqService.Publish(ctx, UserImportQueue, userImportMessage{
+			JobID: job.ID,
+			Tasks: tasks[start:min(start + userImportBatchSize, len(tasks))],
+		}); err != nil {
+			if start == 0 {
+				us.cache.Clear(userImportKey(job.ID))
+				return models.UserImportJob{}, utils.NewError(string(utils.ErrCodeInternal), "Failed to queue import.")
+			}
+
+			// The batches already queued still run, the rest fail.
+			unqueued := tasks[start:]
+			if err := updateUserImportJob(us.cache, job.ID, func(job *models.UserImportJob) {
+				for _, task := range unqueued {
+					failUserImportRow(job, task.Line, task.Email, map[string]string{"row": "Failed to queue row"})
+				}
+			}); err != nil {
+				log.Printf("Failed to record unqueued rows of import job %s:%s", job.ID, err)
+			}
+			break
+		}
+	}
+
+	return job, nil
+}
+
+// GetImportJob only finds jobs started from the caller's organization.
+func (us *userService) GetImportJob(ctx *gin.Context, id string) (models.UserImportJob, error) {
+	notFound := utils.NewError(string(utils.ErrCodeNotFound), "Import job not found")
+
+	var job models.UserImportJob
+	if err := us.cache.Get(userImportKey(id), &job); err != nil || job.ID == "" {
+		return models.UserImportJob{}, notFound
+	}
+
+	if !jobVisible(ctx, job.OrganizationID) {
+		return models.UserImportJob{}, notFound
+	}
+
+	return job, nil
+}
+
+// jobVisible reports whether a job started in organizationID belongs to the
+// tenant the caller acts in. Service accounts see every tenant.
+func jobVisible(ctx *gin.Context, organizationID *string) bool {
+	if _, ok := auth.GetServicePayload(ctx); ok {
+		return true
+	}
+
+	if organizationID == nil {
+		return auth.GetOrganizationID(ctx) == ""
+	}
+
+	return *organizationID == auth.GetOrganizationID(ctx)
+}
+
+// planUserImport decides what each row does. Rows that fail are recorded on
+// job, as are duplicates that are skipped.
+func (us *userService) planUserImport(ctx *gin.Context, subject policy.Subject, job *models.UserImportJob, rows []userImportRow) ([]userImportTask, error) {
+	valid := make([]userImportRow, 0, len(rows))
+	lines := make(map[string]int, len(rows))
+	for _, row := range rows {
+		row.Input.Email = utils.NormailizeString(row.Input.Email)
+
+		if row.Errors == nil {
+			if err := binding.Validator.ValidateStruct(&row.Input); err != nil {
+				row.Errors = userImportValidationErrors(err)
+			}
+		}
+
+		if row.Errors == nil {
+			if line, ok := lines[row.Input.Email]; ok {
+				row.Errors = map[string]string{"email": fmt.Sprintf("Email already appears on line %d", line)}
+			} else {
+				lines[row.Input.Email] = row.Line
+			}
+		}
+
+		if row.Errors != nil {
+			failUserImportRow(job, row.Line, row.Input.Email, row.Errors)
+			continue
+		}
+
+		valid = append(valid, row)
+	}
+
+	existing, err := us.findImportDuplicates(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Global email uniqueness can match users of other tenants, those are
+	// never updated.
+	updatable := make(map[uuid.UUID]bool)
+	if job.OnDuplicate == models.UserImportUpdateDuplicates && len(existing) > 0 {
+		uuids := make([]uuid.UUID, 0, len(existing))
+		for _, user := range existing {
+			uuids = append(uuids, user.UUID)
+		}
+
+		for start := 0; start < len(uuids); start += userImportBatchSize {
+			users, err := us.users(ctx).FindByUUIDs(uuids[start:min(start + userImportBatchSize, len(uuids))])
+			if err != nil {
+				return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile get users", err)
+			}
+			for _, user := range users {
+				updatable[user.UUID] = true
+			}
+		}
+	}
+
+	tasks := make([]userImportTask, 0, len(valid))
+	for _, row := range valid {
+		user := row.Input.MapCreateInputToModel()
+		user.OrganizationID = job.OrganizationID
+
+		current, duplicate := existing[user.Email]
+		if !duplicate {
+			changes := userChanges(models.User{}, user)
+			decision, err := us.check(ctx, subject, "users:create", user, changes)
+			if err != nil {
+				failUserImportRow(job, row.Line, user.Email, map[string]string{"row": errorMessage(err)})
+				continue
+			}
+
+			user = applyUserChanges(models.User{OrganizationID: job.OrganizationID}, stripChanges(changes, decision.Strip))
+			tasks = append(tasks, newUserImportTask(row.Line, user))
+			continue
+		}
+
+		if job.OnDuplicate == models.UserImportSkipDuplicates {
+			job.Skipped++
+			continue
+		}
+
+		if !updatable[current.UUID] {
+			failUserImportRow(job, row.Line, user.Email, map[string]string{"email": "Email belongs to a user outside this organization"})
+			continue
+		}
+
+		// An import never resets the password of a user who already exists.
+		user.Password = ""
+		changes := userChanges(current, user)
+		decision, err := us.check(ctx, subject, "users:update", current, changes)
+		if err != nil {
+			failUserImportRow(job, row.Line, user.Email, map[string]string{"row": errorMessage(err)})
+			continue
+		}
+
+		changes = stripChanges(changes, decision.Strip)
+		if len(changes) == 0 {
+			job.Skipped++
+			continue
+		}
+
+		task := newUserImportTask(row.Line, applyUserChanges(current, changes))
+		task.Password = ""
+		task.UUID = &current.UUID
+		task.Version = current.Version
+		for field := range changes {
+			task.Fields = append(task.Fields, field)
+		}
+		slices.Sort(task.Fields)
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func (us *userService) findImportDuplicates(ctx *gin.Context, rows []userImportRow) (map[string]models.User, error) {
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, row.Input.Email)
+	}
+
+	lookup := emailLookup(us.repo, auth.GetOrganizationID(ctx))
+	existing := make(map[string]models.User)
+	for start := 0; start < len(emails); start += userImportBatchSize {
+		users, err := lookup.FindByEmails(emails[start:min(start + userImportBatchSize, len(emails))])
+		if err != nil {
+			return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile get users", err)
+		}
+
+		for _, user := range users {
+			existing[user.Email] = user
+		}
+	}
+
+	return existing, nil
+}
+
+func userImportValidationErrors(err error) map[string]string {
+	if rowErrors, ok := validation.HandlerValidationErrors(err)["errors"].(map[string]string); ok {
+		return rowErrors
+	}
+
+	return map[string]string{"row": err.Error()}
+}
+
+func failUserImportRow(job *models.UserImportJob, line int, email string, rowErrors map[string]string) {
+	job.Failed++
+	addUserImportError(job, line, email, rowErrors)
+}
+
+func addUserImportError(job *models.UserImportJob, line int, email string, rowErrors map[string]string) {
+	if len(job.Errors) >= MaxUserImportErrors {
+		return
+	}
+
+	job.Errors = append(job.Errors, models.UserImportRowError{
+		Line: line,
+		Email: email,
+		Errors: rowErrors,
+	})
+}
+
+// errorMessage is the message an AppError shows clients, its Error() is empty.
+func errorMessage(err error) string {
+	if appErr, ok := err.(*utils.AppError); ok {
+		return appErr.Message
+	}
+
+	return err.Error()
+}
+
+// UserImportWorker writes queued imports. It runs in the API process, next to
+// the search index the imported users have to show up in.
+type UserImportWorker struct {
+	repo repository.UserRepository
+	organizationRepo repository.OrganizationRepository
+	cache cache.RedisCacheService
+	rabbitmqService rabbitmq.RabbitMQService
+	index search.SearchIndex
+}
+
+func NewUserImportWorker(repo repository.UserRepository, organizationRepo repository.OrganizationRepository, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService, index search.SearchIndex) *UserImportWorker {
+	return &UserImportWorker{
+		repo: repo,
+		organizationRepo: organizationRepo,
+		cache: cacheService,
+		rabbitmqService: rabbitmqService,
+		index: index,
+	}
+}
+
+// Handle consumes UserImportQueue, one message per batch of rows. A row that
+// fails is recorded on the job and the import moves on.
+func (w *UserImportWorker) Handle(body []byte) error {
+	var message userImportMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		log.Printf("Failed to unmarshal import message:%s", err)
+		return err
+	}
+
+	var job models.UserImportJob
+	if err := w.cache.Get(userImportKey(message.JobID), &job); err != nil || job.ID == "" {
+		log.Printf("Failed to load import job %s:%v", message.JobID, err)
+		return fmt.Errorf("import job %s not found", message.JobID)
+	}
+
+	if job.Status == models.JobCompleted {
+		return nil
+	}
+
+	if err := updateUserImportJob(w.cache, job.ID, func(job *models.UserImportJob) {
+		if job.StartedAt == nil {
+			startedAt := time.Now()
+			job.Status = models.JobRunning
+			job.StartedAt = &startedAt
+		}
+	}); err != nil {
+		log.Printf("Failed to start import job %s:%s", job.ID, err)
+	}
+
+	// The batch counts on its own copy and adds to the stored job at the end.
+	progress := job
+	progress.Created, progress.Updated, progress.Skipped, progress.Failed = 0, 0, 0, 0
+	progress.Errors = nil
+	for _, task := range message.Tasks {
+		if err := w.run(&progress, task); err != nil {
+			failUserImportRow(&progress, task.Line, task.Email, map[string]string{"row": errorMessage(err)})
+		}
+	}
+
+	return updateUserImportJob(w.cache, job.ID, func(job *models.UserImportJob) {
+		job.Created += progress.Created
+		job.Updated += progress.Updated
+		job.Skipped += progress.Skipped
+		job.Failed += progress.Failed
+		for _, rowError := range progress.Errors {
+			addUserImportError(job, rowError.Line, rowError.Email, rowError.Errors)
+		}
+	})
+}
+
+// updateUserImportJob applies change to the stored job and completes it once
+// every row is accounted for. Batches of one import can run on several
+// consumers at once, the lock keeps them from losing each other's counts.
+func updateUserImportJob(cacheService cache.RedisCacheService, id string, change func(job *models.UserImportJob)) error {
+	lockKey := userImportKey(id) + ":lock"
+	for deadline := time.Now().Add(userImportLockTTL); ; {
+		locked, err := cacheService.SetNX(lockKey, true, userImportLockTTL)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("import job %s stayed locked", id)
+		}
+		time.Sleep(userImportLockWait)
+	}
+	defer cacheService.Clear(lockKey)
+
+	var job models.UserImportJob
+	if err := cacheService.Get(userImportKey(id), &job); err != nil || job.ID == "" {
+		return fmt.Errorf("import job %s not found", id)
+	}
+
+	change(&job)
+
+	if job.Status != models.JobCompleted && job.Created + job.Updated + job.Skipped + job.Failed >= job.Total {
+		finishedAt := time.Now()
+		job.Status = models.JobCompleted
+		job.FinishedAt = &finishedAt
+	}
+
+	return cacheService.Set(userImportKey(id), job, UserImportJobTTL)
+}
+
+func (w *UserImportWorker) run(job *models.UserImportJob, task userImportTask) error {
+	if task.UUID != nil {
+		changes := task.changes()
+		updated, err := w.repo.UpdateFields(*task.UUID, task.Version, changes)
+		if err != nil {
+			return utils.WrapError(string(utils.ErrCodeInternal), "Faile update user", err)
+		}
+
+		if !updated {
+			return utils.NewError(string(utils.ErrCodeConflict), "User changed after the import was queued")
+		}
+
+		if changesPermissions(changes) {
+			w.cache.Clear(permissionCacheKey(*task.UUID))
+		}
+
+		if user, err := w.repo.FindBYUUID(*task.UUID); err == nil && user.Email != "" {
+			indexUser(w.index, user)
+		}
+		job.Updated++
+
+		return nil
+	}
+
+	organizationID := ""
+	if job.OrganizationID != nil {
+		organizationID = *job.OrganizationID
+	}
+
+	// The email may have been taken while the job waited in the queue.
+	existing, err := emailLookup(w.repo, organizationID).FindByEmail(task.Email)
+	if err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Faile get user", err)
+	}
+
+	if existing.Email != "" {
+		if job.OnDuplicate == models.UserImportSkipDuplicates {
+			job.Skipped++
+			return nil
+		}
+
+		return utils.NewError(string(utils.ErrCodeConflict), fmt.Sprintf("Email: %v already existed.", task.Email))
+	}
+
+	user, err := insertUser(w.repo, w.organizationRepo, w.index, task.user(job.OrganizationID))
+	if err != nil {
+		return err
+	}
+	job.Created++
+
+	if job.Invite {
+		if err := w.invite(user); err != nil {
+			addUserImportError(job, task.Line, task.Email, map[string]string{"invite": errorMessage(err)})
+		}
+	}
+
+	return nil
+}
+
+// invite mails an imported user a link to choose their own password, the
+// link is redeemed through the password reset flow.
+func (w *UserImportWorker) invite(user models.User) error {
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to generate reset token")
+	}
+
+	if err := w.cache.Set("reset:" + token, user.UUID.String(), UserImportInviteTTL); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to store reset token")
+	}
+
+	resetLink := fmt.Sprintf("%s?token=%s", utils.GetEnv("PASSWORD_RESET_URL", "https://yourdomain.com/reset-password"), token)
+	mailContent := &mail.Email{
+		To: []mail.Address{
+			{Email: user.Email},
+		},
+		Subject: "Your account is ready",
+		Text: fmt.Sprintf("Hi %s,\n\nAn account has been created for you. Click the link below to choose your password:\n%s\n\nThe link will expire on %s.", user.Name, resetLink, time.Now().Add(UserImportInviteTTL).Format(time.RFC1123)),
+	}
+
+	if err := w.rabbitmqService.Publish(context.Background(), "auth_email_queue", mailContent); err != nil {
+		return utils.NewError(string(utils.ErrCodeInternal), "Failed to send invitation email.")
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package v1service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/utils"
+)
+
+// userImportColumns are the CSV columns an import understands, the same
+// fields as CreateUserInput.
+var userImportColumns = []string{"name", "email", "password", "age", "status", "level"}
+
+// userImportRow is one line of an uploaded file. Errors holds what kept the
+// line from decoding, validation runs later.
+type userImportRow struct {
+	Line 	int
+	Input 	v1dto.CreateUserInput
+	Errors 	map[string]string
+}
+
+func parseUserImport(mediaType string, body []byte) ([]userImportRow, error) {
+	switch mediaType {
+	case v1dto.MediaTypeCSV:
+		return parseUserImportCSV(body)
+	case v1dto.MediaTypeNDJSON:
+		return parseUserImportNDJSON(body)
+	default:
+		return nil, utils.NewError(string(utils.ErrCodeUnsupportedMediaType), "Unsupported import format, send text/csv or application/x-ndjson")
+	}
+}
+
+// parseUserImportCSV takes a header line naming the columns in any order.
+func parseUserImportCSV(body []byte) ([]userImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, utils.NewError(string(utils.ErrCodeBadRequest), "The file is empty")
+	}
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to read CSV", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		// Spreadsheet exports often start with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(userImportColumns, name) {
+			return nil, utils.NewError(string(utils.ErrCodeBadRequest), fmt.Sprintf("Unknown column %q", name))
+		}
+		if slices.Contains(columns[:i], name) {
+			return nil, utils.NewError(string(utils.ErrCodeBadRequest), fmt.Sprintf("Duplicate column %q", name))
+		}
+		columns[i] = name
+	}
+
+	rows := make([]userImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to read CSV", err)
+		}
+
+		if len(rows) >= MaxUserImportRows {
+			return nil, tooManyImportRows()
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := userImportRow{Line: line}
+		if len(record) != len(columns) {
+			row.Errors = map[string]string{"row": fmt.Sprintf("Expected %d fields, got %d", len(columns), len(record))}
+			rows = append(rows, row)
+			continue
+		}
+
+		fields := make(map[string]string, len(columns))
+		for i, column := range columns {
+			fields[column] = record[i]
+		}
+		row.Input, row.Errors = userImportCSVInput(fields)
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, utils.NewError(string(utils.ErrCodeBadRequest), "The file is empty")
+	}
+
+	return rows, nil
+}
+
+func userImportCSVInput(fields map[string]string) (v1dto.CreateUserInput, map[string]string) {
+	input := v1dto.CreateUserInput{
+		Name: strings.TrimSpace(fields["name"]),
+		Email: strings.TrimSpace(fields["email"]),
+		Password: fields["password"],
+	}
+
+	rowErrors := make(map[string]string)
+	parse := func(column string, bitSize int) int64 {
+		value := strings.TrimSpace(fields[column])
+		if value == "" {
+			return 0
+		}
+
+		number, err := strconv.ParseInt(value, 10, bitSize)
+		if err != nil {
+			rowErrors[column] = fmt.Sprintf("%s phải là số", column)
+		}
+		return number
+	}
+
+	input.Age = int16(parse("age", 16))
+	input.Status = int8(parse("status", 8))
+	input.Level = int8(parse("level", 8))
+
+	if len(rowErrors) > 0 {
+		return input, rowErrors
+	}
+
+	return input, nil
+}
+
+// parseUserImportNDJSON takes one JSON object per line, blank lines are skipped.
+func parseUserImportNDJSON(body []byte) ([]userImportRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64 * 1024), int(MaxUserImportBytes))
+
+	rows := make([]userImportRow, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		if len(rows) >= MaxUserImportRows {
+			return nil, tooManyImportRows()
+		}
+
+		row := userImportRow{Line: line}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.Input); err != nil {
+			row.Errors = map[string]string{"row": "Invalid JSON: " + err.Error()}
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to read NDJSON", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, utils.NewError(string(utils.ErrCodeBadRequest), "The file is empty")
+	}
+
+	return rows, nil
+}
+
+func tooManyImportRows() error {
+	return utils.NewError(string(utils.ErrCodeBadRequest), fmt.Sprintf("An import takes at most %d rows, split the file", MaxUserImportRows))
+}
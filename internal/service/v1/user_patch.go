@@ -0,0 +1,119 @@
+package v1service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/jsonpatch"
+)
+
+// patchUserInput applies patch to the patchable view of user and decodes the
+// fields whose value changed. Fields the patch leaves alone stay nil.
+func patchUserInput(user models.User, patch v1dto.UserPatch) (v1dto.PatchUserInput, error) {
+	original := v1dto.UserPatchDocument(user)
+	doc, err := json.Marshal(original)
+	if err != nil {
+		return v1dto.PatchUserInput{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to encode user", err)
+	}
+
+	var patched []byte
+	switch patch.MediaType {
+	case v1dto.MediaTypeJSONPatch:
+		operations, err := jsonpatch.DecodePatch(patch.Body)
+		if err != nil {
+			return v1dto.PatchUserInput{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Invalid JSON patch", err)
+		}
+
+		patched, err = operations.Apply(doc)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return v1dto.PatchUserInput{}, utils.WrapError(string(utils.ErrCodeConflict), "Patch test failed", err)
+		}
+		if err != nil {
+			return v1dto.PatchUserInput{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Unable to apply patch", err)
+		}
+	default:
+		patched, err = jsonpatch.MergePatch(doc, patch.Body)
+		if err != nil {
+			return v1dto.PatchUserInput{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Invalid merge patch", err)
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patched, &fields); err != nil || fields == nil {
+		return v1dto.PatchUserInput{}, utils.NewError(string(utils.ErrCodeBadRequest), "A patched user must stay an object")
+	}
+
+	for field := range original {
+		if _, ok := fields[field]; !ok {
+			return v1dto.PatchUserInput{}, utils.NewError(string(utils.ErrCodeBadRequest), field + " cannot be removed")
+		}
+	}
+
+	changed := make(map[string]json.RawMessage)
+	for field, raw := range fields {
+		value, known := original[field]
+		if !known && field != "password" {
+			return v1dto.PatchUserInput{}, utils.NewError(string(utils.ErrCodeBadRequest), "Unknown field " + field)
+		}
+
+		if known && sameJSON(value, raw) {
+			continue
+		}
+		changed[field] = raw
+	}
+
+	data, _ := json.Marshal(changed)
+
+	var input v1dto.PatchUserInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return v1dto.PatchUserInput{}, utils.WrapError(string(utils.ErrCodeBadRequest), "Invalid field value", err)
+	}
+
+	return input, nil
+}
+
+func sameJSON(value any, raw json.RawMessage) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	var left, right any
+	if json.Unmarshal(data, &left) != nil || json.Unmarshal(raw, &right) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+// patchChanges is userChanges for a patch, presence rather than a zero value
+// decides whether a field is set.
+func patchChanges(current models.User, input v1dto.PatchUserInput) map[string]any {
+	changes := make(map[string]any)
+	if input.Name != nil && *input.Name != current.Name {
+		changes["name"] = *input.Name
+	}
+	if input.Email != nil {
+		if email := utils.NormailizeString(*input.Email); email != current.Email {
+			changes["email"] = email
+		}
+	}
+	if input.Password != nil {
+		changes["password"] = *input.Password
+	}
+	if input.Age != nil && *input.Age != current.Age {
+		changes["age"] = *input.Age
+	}
+	if input.Level != nil && *input.Level != current.Level {
+		changes["level"] = *input.Level
+	}
+	if input.Status != nil && *input.Status != current.Status {
+		changes["status"] = *input.Status
+	}
+
+	return changes
+}
@@ -0,0 +1,86 @@
+package v1service
+
+import (
+	"log"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/repository"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/gin-gonic/gin"
+)
+
+// UserSearchWeights ranks a name match above the same match in an email.
+var UserSearchWeights = map[string]float64{
+	"name": 1,
+	"email": 0.9,
+}
+
+func userDocument(user models.User) search.Document {
+	organizationID := ""
+	if user.OrganizationID != nil {
+		organizationID = *user.OrganizationID
+	}
+
+	return search.Document{
+		ID: user.UUID.String(),
+		Fields: map[string]string{
+			"name": user.Name,
+			"email": user.Email,
+		},
+		Attributes: map[string]string{
+			"organization_id": organizationID,
+		},
+	}
+}
+
+// userSearchFilter limits the index to the caller's organization, the same
+// tenant us.users reads from. Service principals see every tenant.
+func userSearchFilter(ctx *gin.Context) search.Filter {
+	if _, ok := auth.GetServicePayload(ctx); ok {
+		return nil
+	}
+
+	return search.Filter{"organization_id": auth.GetOrganizationID(ctx)}
+}
+
+// indexUser keeps the search index in step with a write that already
+// succeeded, a stale entry is fixed by the next reindex.
+func indexUser(index search.SearchIndex, user models.User) {
+	if index == nil {
+		return
+	}
+
+	if err := index.Index(userDocument(user)); err != nil {
+		log.Printf("Failed to index user %s:%s", user.UUID, err)
+	}
+}
+
+func unindexUser(index search.SearchIndex, user models.User) {
+	if index == nil {
+		return
+	}
+
+	if err := index.Remove(user.UUID.String()); err != nil {
+		log.Printf("Failed to remove user %s from index:%s", user.UUID, err)
+	}
+}
+
+// ReindexUsers rebuilds index from every user in the database.
+func ReindexUsers(repo repository.UserRepository, index search.SearchIndex) (int, error) {
+	users, err := repo.FindAll()
+	if err != nil {
+		return 0, err
+	}
+
+	docs := make([]search.Document, 0, len(users))
+	for _, user := range users {
+		docs = append(docs, userDocument(user))
+	}
+
+	if err := index.Reindex(docs); err != nil {
+		return 0, err
+	}
+
+	return len(docs), nil
+}
@@ -2,10 +2,21 @@ package v1service
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	v1dto "github.com/dangLuan01/user-manager/internal/dto/v1"
 	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/dangLuan01/user-manager/internal/policy"
 	"github.com/dangLuan01/user-manager/internal/repository"
 	"github.com/dangLuan01/user-manager/internal/utils"
+	"github.com/dangLuan01/user-manager/pkg/auth"
+	"github.com/dangLuan01/user-manager/pkg/cache"
+	"github.com/dangLuan01/user-manager/pkg/rabbitmq"
+	"github.com/dangLuan01/user-manager/pkg/search"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -13,48 +24,284 @@ import (
 
 type userService struct {
 	repo repository.UserRepository
+	organizationRepo repository.OrganizationRepository
+	rbac RBACService
+	policy *policy.Engine
+	index search.SearchIndex
+	tokenService auth.TokenService
+	cache cache.RedisCacheService
+	rabbitmqService rabbitmq.RabbitMQService
+	emailChanger *emailChanger
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
+func NewUserService(repo repository.UserRepository, organizationRepo repository.OrganizationRepository, rbac RBACService, policyEngine *policy.Engine, index search.SearchIndex, tokenService auth.TokenService, cacheService cache.RedisCacheService, rabbitmqService rabbitmq.RabbitMQService) UserService {
 	return &userService{
 		repo: repo,
+		organizationRepo: organizationRepo,
+		rbac: rbac,
+		policy: policyEngine,
+		index: index,
+		tokenService: tokenService,
+		cache: cacheService,
+		rabbitmqService: rabbitmqService,
+		emailChanger: newEmailChanger(repo, tokenService, cacheService, rabbitmqService, index),
 	}
 }
 
-func (us *userService) GetAllUser()  ([]models.User, error) {
-	users, err := us.repo.FindAll()
+// users is the repository limited to the organization the caller acts in.
+// Service accounts belong to no tenant and work across all of them.
+func (us *userService) users(ctx *gin.Context) repository.UserRepository {
+	if _, ok := auth.GetServicePayload(ctx); ok {
+		return us.repo
+	}
+
+	return us.repo.ForOrganization(auth.GetOrganizationID(ctx))
+}
+
+var DefaultUserPageSize uint = 20
+
+// GetAllUser returns one page of users. The cursor follows the last row read,
+// not the last row shown, so users hidden by policy never stall paging.
+func (us *userService) GetAllUser(ctx *gin.Context, query v1dto.UserListQuery) ([]models.User, v1dto.PageMeta, error) {
+	return us.listUsers(ctx, us.users(ctx), "users:read", query)
+}
+
+// ListDeletedUsers pages through the soft deleted users the caller could
+// delete, only they may see and restore them.
+func (us *userService) ListDeletedUsers(ctx *gin.Context, query v1dto.UserListQuery) ([]models.User, v1dto.PageMeta, error) {
+	return us.listUsers(ctx, us.users(ctx).OnlyDeleted(), "users:delete", query)
+}
+
+func (us *userService) listUsers(ctx *gin.Context, repo repository.UserRepository, action string, query v1dto.UserListQuery) ([]models.User, v1dto.PageMeta, error) {
+	subject, err := us.subject(ctx)
+	if err != nil {
+		return nil, v1dto.PageMeta{}, err
+	}
+
+	page, err := userPage(query)
+	if err != nil {
+		return nil, v1dto.PageMeta{}, err
+	}
+
+	limit := page.Limit
+	page.Limit = limit + 1
+
+	users, err := repo.FindPage(page)
 	if err != nil {
 		
-		return nil, utils.WrapError(
+		return nil, v1dto.PageMeta{}, utils.WrapError(
 			string(utils.ErrCodeInternal), 
 			"Faile fetch users.", 
 			err,
 		)
 	}
 
-	return users, nil
+	meta := v1dto.PageMeta{
+		Limit: limit,
+	}
+	if uint(len(users)) > limit {
+		users = users[:limit]
+		meta.NextCursor = repository.NewUserCursor(page, users[len(users)-1]).Encode()
+	}
+
+	if holdsPermission(subject, "users:read") {
+		total, err := repo.Count(page.Filter)
+		if err != nil {
+			return nil, v1dto.PageMeta{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile count users.", err)
+		}
+		meta.TotalEstimate = &total
+	}
+
+	visible := make([]models.User, 0, len(users))
+	for _, user := range users {
+		if us.decide(ctx, subject, action, user, nil).Allowed {
+			visible = append(visible, user)
+		}
+	}
+
+	return visible, meta, nil
 }
 
-func (us *userService) GetUserByUUID(uuid uuid.UUID) (models.User, error) {
-	
-	user, err := us.repo.FindBYUUID(uuid);
+func userPage(query v1dto.UserListQuery) (repository.UserPage, error) {
+	page, err := userFilterPage(query.UserFilterQuery)
 	if err != nil {
+		return repository.UserPage{}, err
+	}
+
+	page.Limit = query.Limit
+	if page.Limit == 0 {
+		page.Limit = DefaultUserPageSize
+	}
+
+	if query.Cursor != "" {
+		cursor, err := repository.DecodeUserCursor(query.Cursor)
+		if err != nil {
+			return repository.UserPage{}, utils.NewError(string(utils.ErrCodeBadRequest), "Invalid cursor")
+		}
+
+		if cursor.Sort != page.Sort || cursor.Desc != page.Desc {
+			return repository.UserPage{}, utils.NewError(string(utils.ErrCodeBadRequest), "Cursor does not match sort")
+		}
+		page.After = &cursor
+	}
+
+	return page, nil
+}
+
+// userFilterPage is the unbounded page query selects, before any limit or
+// cursor.
+func userFilterPage(query v1dto.UserFilterQuery) (repository.UserPage, error) {
+	page := repository.UserPage{
+		Filter: repository.UserFilter{
+			Status: query.Status,
+			Level: query.Level,
+			EmailPrefix: utils.NormailizeString(query.Email),
+			NamePrefix: query.Name,
+			CreatedFrom: query.CreatedFrom,
+			CreatedTo: query.CreatedTo,
+			UpdatedFrom: query.UpdatedFrom,
+			UpdatedTo: query.UpdatedTo,
+			LastLoginFrom: query.LastLoginFrom,
+			LastLoginTo: query.LastLoginTo,
+			PasswordChangedTo: query.PasswordChangedTo,
+			EmailVerified: query.EmailVerified,
+		},
+		Sort: strings.TrimPrefix(query.Sort, "-"),
+		Desc: strings.HasPrefix(query.Sort, "-"),
+	}
+
+	if page.Sort == "" {
+		page.Sort = "created_at"
+	}
+	if !slices.Contains(repository.UserSortFields, page.Sort) {
+		return repository.UserPage{}, utils.NewError(string(utils.ErrCodeBadRequest), "Unsupported sort " + query.Sort)
+	}
+
+	return page, nil
+}
+
+// At most this many batches of hits are read per search, so hits hidden by
+// policy cannot make one request walk the whole index.
+const maxUserSearchBatches = 5
+
+// SearchUsers ranks users of the caller's organization by how well their name
+// or email matches query.Q. Hits are read in batches until enough of them are
+// visible to the caller.
+func (us *userService) SearchUsers(ctx *gin.Context, query v1dto.UserSearchQuery) ([]v1dto.UserSearchResult, error) {
+	subject, err := us.subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(query.Limit)
+	if limit == 0 {
+		limit = int(DefaultUserPageSize)
+	}
+
+	hits, err := us.index.Search(query.Q, userSearchFilter(ctx), limit * maxUserSearchBatches)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile search users.", err)
+	}
+
+	repo := us.users(ctx)
+	results := make([]v1dto.UserSearchResult, 0, limit)
+	for start := 0; start < len(hits) && len(results) < limit; start += limit {
+		batch := hits[start:min(start + limit, len(hits))]
+
+		uuids := make([]uuid.UUID, 0, len(batch))
+		for _, hit := range batch {
+			if id, err := uuid.Parse(hit.ID); err == nil {
+				uuids = append(uuids, id)
+			}
+		}
+
+		users, err := repo.FindByUUIDs(uuids)
+		if err != nil {
+			return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile fetch users.", err)
+		}
+
+		byUUID := make(map[string]models.User, len(users))
+		for _, user := range users {
+			byUUID[user.UUID.String()] = user
+		}
+
+		for _, hit := range batch {
+			user, ok := byUUID[hit.ID]
+			if !ok || !us.decide(ctx, subject, "users:read", user, nil).Allowed {
+				continue
+			}
+
+			results = append(results, v1dto.MapUserSearchResult(user, hit))
+			if len(results) == limit {
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (us *userService) ReindexUsers(ctx *gin.Context) (int, error) {
+	count, err := ReindexUsers(us.repo, us.index)
+	if err != nil {
+		return 0, utils.WrapError(string(utils.ErrCodeInternal), "Faile reindex users.", err)
+	}
+
+	return count, nil
+}
+
+func (us *userService) GetUserByUUID(ctx *gin.Context, uuid uuid.UUID) (models.User, error) {
+	
+	user, err := us.users(ctx).FindBYUUID(uuid);
+	if err != nil || user.Email == "" {
 
 		return models.User{}, utils.NewError(string(utils.ErrCodeNotFound), "No user")
 	}
+
+	if _, err := us.authorize(ctx, "users:read", user, nil); err != nil {
+		return models.User{}, err
+	}
 	
 	return user, nil
 }
 
-func (us *userService) CreateUser(user models.User) (models.User, error) {
+// newUserOrganizationID is the organization users created by the caller live
+// in, service accounts create users outside any.
+func newUserOrganizationID(ctx *gin.Context) *string {
+	if _, ok := auth.GetServicePayload(ctx); !ok && auth.GetOrganizationID(ctx) != "" {
+		id := auth.GetOrganizationID(ctx)
+		return &id
+	}
+
+	return nil
+}
+
+func (us *userService) CreateUser(ctx *gin.Context, user models.User) (models.User, error) {
 	user.Email = utils.NormailizeString(user.Email)
-	if user, err := us.repo.FindByEmail(user.Email); err != nil {
+
+	organizationID := newUserOrganizationID(ctx)
+	user.OrganizationID = organizationID
+
+	decision, err := us.authorize(ctx, "users:create", user, userChanges(models.User{}, user))
+	if err != nil {
+		return models.User{}, err
+	}
+	user = applyUserChanges(models.User{OrganizationID: organizationID}, stripChanges(userChanges(models.User{}, user), decision.Strip))
+
+	if existing, err := emailLookup(us.repo, auth.GetOrganizationID(ctx)).FindByEmail(user.Email); err != nil || existing.Email != "" {
 		
 		return models.User{}, utils.NewError(
 			string(utils.ErrCodeConflict), 
 			fmt.Sprintf("Email: %v already existed.", user.Email),
 		)
 	}
+
+	return insertUser(us.repo, us.organizationRepo, us.index, user)
+}
+
+// insertUser stores a user that passed every check, hashing its password and
+// adding it to the organization it belongs to.
+func insertUser(repo repository.UserRepository, organizationRepo repository.OrganizationRepository, index search.SearchIndex, user models.User) (models.User, error) {
 	user.UUID = uuid.New()
 	hashPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -66,7 +313,12 @@ func (us *userService) CreateUser(user models.User) (models.User, error) {
 		)
 	}
 	user.Password = string(hashPassword)
-	if err := us.repo.Create(user); err != nil {
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.PasswordChangedAt = &now
+	user.Version = 1
+	if err := repo.Create(user); err != nil {
 
 		return models.User{}, utils.WrapError(
 			string(utils.ErrCodeInternal), 
@@ -74,56 +326,447 @@ func (us *userService) CreateUser(user models.User) (models.User, error) {
 			err,
 		)
 	}
+
+	if user.OrganizationID != nil {
+		if err := organizationRepo.SaveMember(models.OrganizationMember{
+			OrganizationID: *user.OrganizationID,
+			UserUUID: user.UUID,
+			Role: models.OrganizationRoleMember,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile add organization member", err)
+		}
+	}
+
+	indexUser(index, user)
 	
 	return user, nil
 }
-func (us *userService) UpdateUser(uuid uuid.UUID, user models.User) (models.User, error) {
+
+// UpdateUser only writes the fields that change. Fields a policy strips are
+// dropped, fields it rejects fail the whole update.
+func (us *userService) UpdateUser(ctx *gin.Context, uuid uuid.UUID, user models.User) (models.User, error) {
+	repo := us.users(ctx)
+	currencyUser, err := repo.FindBYUUID(uuid)
+	if err != nil || currencyUser.Email == "" {
+		return models.User{}, utils.NewError(string(utils.ErrCodeNotFound), "user not found")
+	}
+
 	user.Email = utils.NormailizeString(user.Email)
-	if u, err := us.repo.FindByEmail(user.Email); err != nil && u.UUID != uuid{
-		
-		return models.User{}, utils.NewError(
-			string(utils.ErrCodeConflict), 
-			fmt.Sprintf("Email: %v already existed.", u.Email),
-		)
+	changes, err := us.prepareChanges(ctx, currencyUser, userChanges(currencyUser, user))
+	if err != nil {
+		return models.User{}, err
 	}
-	currencyUser, err := us.repo.FindBYUUID(uuid)
+
+	updatedUser := applyUserChanges(currencyUser, changes)
+	updatedUser.Password = ""
+	if password, ok := changes["password"].(string); ok {
+		updatedUser.Password = password
+	}
+	
+	updated, err := repo.Update(uuid, updatedUser)
 	if err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile update user", err)
+	}
+
+	if !updated {
+		return models.User{}, errUserModified
+	}
+
+	return us.updated(ctx, updatedUser, changes)
+}
+
+// PatchUser applies a JSON Merge Patch or JSON Patch. Only the fields the
+// patch changes are validated and written.
+func (us *userService) PatchUser(ctx *gin.Context, uuid uuid.UUID, patch v1dto.UserPatch) (models.User, error) {
+	repo := us.users(ctx)
+	currencyUser, err := repo.FindBYUUID(uuid)
+	if err != nil || currencyUser.Email == "" {
 		return models.User{}, utils.NewError(string(utils.ErrCodeNotFound), "user not found")
 	}
-	
-	currencyUser.Name = user.Name
-	currencyUser.Email = user.Email
 
-	if user.Password != "" {
-		hashPassword, err :=bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile hash pass", err)
-		}
-		currencyUser.Password = string(hashPassword)
-		
+	input, err := patchUserInput(currencyUser, patch)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return models.User{}, err
 	}
-	if user.Age != 0 {
-		currencyUser.Age = user.Age	
+
+	changes, err := us.prepareChanges(ctx, currencyUser, patchChanges(currencyUser, input))
+	if err != nil {
+		return models.User{}, err
 	}
-	if user.Level != 0 {
-		currencyUser.Level = user.Level	
+
+	if len(changes) == 0 {
+		return currencyUser, nil
 	}
-	
-	if user.Status != 0 {
-		currencyUser.Status = user.Status	
+
+	fields := make(map[string]any, len(changes) + 1)
+	for field, value := range changes {
+		fields[field] = value
 	}
-	
-	if err := us.repo.Update(uuid, currencyUser); err != nil {
+	if _, ok := changes["password"]; ok {
+		fields["password_changed_at"] = time.Now()
+	}
+
+	updated, err := repo.UpdateFields(uuid, currencyUser.Version, fields)
+	if err != nil {
 		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile update user", err)
 	}
-	return currencyUser, nil
+
+	if !updated {
+		return models.User{}, errUserModified
+	}
+
+	return us.updated(ctx, applyUserChanges(currencyUser, changes), changes)
+}
+
+// prepareChanges runs the checks every update shares: policies, If-Match and
+// email uniqueness. It returns the changes that may be written, the password
+// already hashed and a new email moved to pending_email.
+func (us *userService) prepareChanges(ctx *gin.Context, current models.User, changes map[string]any) (map[string]any, error) {
+	decision, err := us.authorize(ctx, "users:update", current, changes)
+	if err != nil {
+		return nil, err
+	}
+	changes = stripChanges(changes, decision.Strip)
+
+	if err := checkIfMatch(ctx, current); err != nil {
+		return nil, err
+	}
+
+	// The user confirms the new address before it replaces the old one.
+	if email, ok := changes["email"].(string); ok {
+		if err := us.emailChanger.check(current, email); err != nil {
+			return nil, err
+		}
+		delete(changes, "email")
+		changes["pending_email"] = email
+	}
+
+	if password, ok := changes["password"].(string); ok {
+		hashPassword, err :=bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, utils.WrapError(string(utils.ErrCodeInternal), "Faile hash pass", err)
+		}
+		changes["password"] = string(hashPassword)
+	}
+
+	return changes, nil
 }
 
-func (us *userService) DeleteUser(uuid uuid.UUID) error {
-	if err := us.repo.Delete(uuid); err != nil {
+// updated brings user in line with the row a successful write left behind and
+// mails the codes for a pending email.
+func (us *userService) updated(ctx *gin.Context, user models.User, changes map[string]any) (models.User, error) {
+	now := time.Now()
+	user.UpdatedAt = now
+	if _, ok := changes["password"]; ok {
+		user.PasswordChangedAt = &now
+	}
+	user.Password = ""
+	user.Version++
+	indexUser(us.index, user)
+
+	if changesPermissions(changes) {
+		us.cache.Clear(permissionCacheKey(user.UUID))
+	}
+
+	if _, ok := changes["pending_email"]; ok {
+		if err := us.emailChanger.send(ctx, user); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return user, nil
+}
+
+// DeleteUser soft deletes the user, PurgeDeletedUsers removes it for good
+// once the retention window has passed.
+func (us *userService) DeleteUser(ctx *gin.Context, uuid uuid.UUID) error {
+	repo := us.users(ctx)
+	user, err := repo.FindBYUUID(uuid)
+	if err != nil || user.Email == "" {
+		return utils.NewError(string(utils.ErrCodeNotFound), "user not found")
+	}
+
+	if _, err := us.authorize(ctx, "users:delete", user, nil); err != nil {
+		return err
+	}
+
+	if err := checkIfMatch(ctx, user); err != nil {
+		return err
+	}
+
+	deleted, err := repo.Delete(uuid, user.Version)
+	if err != nil {
 		return utils.WrapError(string(utils.ErrCodeInternal), "Faile delete user", err)
 	}
+
+	if !deleted {
+		return errUserModified
+	}
+
+	unindexUser(us.index, user)
+	us.cache.Clear(permissionCacheKey(user.UUID))
+
+	// A deleted user keeps no session while waiting for the purge.
+	if err := us.tokenService.RevokeUserRefreshFamilies(user.UUID); err != nil {
+		return utils.WrapError(string(utils.ErrCodeInternal), "Unable to revoke sessions", err)
+	}
 	
 	return nil
 
-}
\ No newline at end of file
+}
+
+func (us *userService) RestoreUser(ctx *gin.Context, uuid uuid.UUID) (models.User, error) {
+	repo := us.users(ctx)
+	user, err := repo.OnlyDeleted().FindBYUUID(uuid)
+	if err != nil || user.Email == "" {
+		return models.User{}, utils.NewError(string(utils.ErrCodeNotFound), "user not found")
+	}
+
+	if _, err := us.authorize(ctx, "users:delete", user, nil); err != nil {
+		return models.User{}, err
+	}
+
+	// The address may have been taken while the account was deleted.
+	organizationID := ""
+	if user.OrganizationID != nil {
+		organizationID = *user.OrganizationID
+	}
+
+	if existing, err := emailLookup(us.repo, organizationID).FindByEmail(user.Email); err != nil || existing.Email != "" {
+		return models.User{}, utils.NewError(
+			string(utils.ErrCodeConflict), 
+			fmt.Sprintf("Email: %v already existed.", user.Email),
+		)
+	}
+
+	if err := repo.Restore(uuid); err != nil {
+		return models.User{}, utils.WrapError(string(utils.ErrCodeInternal), "Faile restore user", err)
+	}
+
+	user.DeletedAt = nil
+	user.Version++
+	indexUser(us.index, user)
+
+	return user, nil
+}
+
+var UserPurgeBatchSize uint = 100
+
+// PurgeDeletedUsers permanently removes the users deleted longer than
+// retention ago and returns how many it purged.
+func PurgeDeletedUsers(repo repository.UserRepository, retention time.Duration) (int, error) {
+	deletedBefore := time.Now().Add(-retention)
+
+	total := 0
+	for {
+		purged, err := repo.Purge(deletedBefore, UserPurgeBatchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+
+		if uint(purged) < UserPurgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+var errUserModified = utils.NewError(string(utils.ErrCodePreconditionFailed), "User was modified by someone else, reload it and retry")
+
+// RequireIfMatch makes writes without an If-Match header fail instead of
+// overwriting blindly.
+var RequireIfMatch = utils.GetEnv("USER_REQUIRE_IF_MATCH", "false") == "true"
+
+// checkIfMatch compares the If-Match header with the version the caller is
+// about to overwrite. The repository repeats the check atomically.
+func checkIfMatch(ctx *gin.Context, user models.User) error {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		if RequireIfMatch {
+			return utils.NewError(string(utils.ErrCodePreconditionRequired), "If-Match header is required")
+		}
+		return nil
+	}
+
+	if !utils.MatchETag(ifMatch, user.Version) {
+		return errUserModified
+	}
+
+	return nil
+}
+
+func (us *userService) subject(ctx *gin.Context) (policy.Subject, error) {
+	scopes, _ := auth.GetScopes(ctx)
+
+	if payload, ok := auth.GetServicePayload(ctx); ok {
+		return policy.Subject{
+			PrincipalType: auth.PrincipalService,
+			ClientID: payload.ClientID,
+			Scopes: scopes,
+		}, nil
+	}
+
+	payload, ok := auth.GetPayload(ctx)
+	if !ok {
+		return policy.Subject{}, utils.NewError(string(utils.ErrCodeUnauthorized), "Unauthorized")
+	}
+
+	permissions, err := us.rbac.UserPermissions(payload.UserUUID)
+	if err != nil {
+		return policy.Subject{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load permissions", err)
+	}
+
+	subject := policy.Subject{
+		UserUUID: payload.UserUUID,
+		Email: payload.Email,
+		Level: payload.Role,
+		PrincipalType: auth.PrincipalUser,
+		Scopes: scopes,
+		Permissions: permissions,
+	}
+
+	// A token for an organization the user has since left grants nothing there.
+	if organizationID := auth.GetOrganizationID(ctx); organizationID != "" {
+		member, found, err := us.organizationRepo.FindMember(organizationID, payload.UserUUID)
+		if err != nil {
+			return policy.Subject{}, utils.WrapError(string(utils.ErrCodeInternal), "Unable to load membership", err)
+		}
+
+		if !found {
+			return policy.Subject{}, utils.NewError(string(utils.ErrCodeForbidden), "Not a member of this organization")
+		}
+
+		subject.OrganizationID = organizationID
+		subject.OrganizationRole = member.Role
+	}
+
+	return subject, nil
+}
+
+// holdsPermission reads a permission the way RequirePermission does, service
+// accounts hold it as a scope.
+func holdsPermission(subject policy.Subject, permission string) bool {
+	if subject.PrincipalType == auth.PrincipalService {
+		return slices.Contains(subject.Scopes, permission)
+	}
+
+	return slices.Contains(subject.Permissions, permission)
+}
+
+func (us *userService) decide(ctx *gin.Context, subject policy.Subject, action string, resource models.User, changes map[string]any) policy.Decision {
+	request := policy.Request{
+		Subject: subject,
+		Action: action,
+		Resource: resource,
+		Changes: changes,
+		Environment: policy.Environment{
+			IP: getClientIP(ctx),
+			Time: time.Now(),
+		},
+	}
+
+	// Writes need the target's role to keep owners out of the reach of admins,
+	// a failed lookup denies.
+	if subject.OrganizationID != "" && (action == "users:update" || action == "users:delete") {
+		member, found, err := us.organizationRepo.FindMember(subject.OrganizationID, resource.UUID)
+		if err != nil {
+			return policy.Decision{}
+		}
+
+		if found {
+			request.ResourceOrganizationRole = member.Role
+		}
+	}
+
+	return us.policy.Evaluate(request)
+}
+
+func (us *userService) authorize(ctx *gin.Context, action string, resource models.User, changes map[string]any) (policy.Decision, error) {
+	subject, err := us.subject(ctx)
+	if err != nil {
+		return policy.Decision{}, err
+	}
+
+	return us.check(ctx, subject, action, resource, changes)
+}
+
+// check is authorize for a subject already loaded, for callers that decide
+// on many users at once.
+func (us *userService) check(ctx *gin.Context, subject policy.Subject, action string, resource models.User, changes map[string]any) (policy.Decision, error) {
+	decision := us.decide(ctx, subject, action, resource, changes)
+	if !decision.Allowed {
+		return decision, utils.NewError(string(utils.ErrCodeForbidden), "Access denied")
+	}
+
+	if len(decision.Rejected) > 0 {
+		return decision, utils.NewError(string(utils.ErrCodeForbidden), "Not allowed to change " + strings.Join(decision.Rejected, ", "))
+	}
+
+	return decision, nil
+}
+
+// userChanges lists the fields of input that differ from current, zero values
+// mean the field was left out.
+func userChanges(current, input models.User) map[string]any {
+	changes := make(map[string]any)
+	if input.Name != "" && input.Name != current.Name {
+		changes["name"] = input.Name
+	}
+	if input.Email != "" && input.Email != current.Email {
+		changes["email"] = input.Email
+	}
+	if input.Password != "" {
+		changes["password"] = input.Password
+	}
+	if input.Age != 0 && input.Age != current.Age {
+		changes["age"] = input.Age
+	}
+	if input.Level != 0 && input.Level != current.Level {
+		changes["level"] = input.Level
+	}
+	if input.Status != 0 && input.Status != current.Status {
+		changes["status"] = input.Status
+	}
+
+	return changes
+}
+
+func stripChanges(changes map[string]any, fields []string) map[string]any {
+	for _, field := range fields {
+		delete(changes, field)
+	}
+
+	return changes
+}
+
+func applyUserChanges(user models.User, changes map[string]any) models.User {
+	if name, ok := changes["name"].(string); ok {
+		user.Name = name
+	}
+	if email, ok := changes["email"].(string); ok {
+		user.Email = email
+	}
+	if email, ok := changes["pending_email"].(string); ok {
+		user.PendingEmail = &email
+	}
+	if password, ok := changes["password"].(string); ok {
+		user.Password = password
+	}
+	if age, ok := changes["age"].(int16); ok {
+		user.Age = age
+	}
+	if level, ok := changes["level"].(int8); ok {
+		user.Level = level
+	}
+	if status, ok := changes["status"].(int8); ok {
+		user.Status = status
+	}
+
+	return user
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// MatchETag reports whether an If-Match header lists the entity tag of
+// version. "*" matches any version. If-Match uses the strong comparison of
+// RFC 7232, so a weak tag never matches.
+func MatchETag(ifMatch string, version int64) bool {
+	etag := ETag(version)
+
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -14,7 +14,11 @@ const (
 	ErrCodeConflict   		ErrorCode = "CONFLICT"
 	ErrCodeInternal   		ErrorCode = "INTERNAL_ERROR_SERVER"
 	ErrCodeUnauthorized 	ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden 		ErrorCode = "FORBIDDEN"
 	ErrCodeTooManyRequest 	ErrorCode = "TOO_MANY_REQUEST"
+	ErrCodePreconditionFailed 	ErrorCode = "PRECONDITION_FAILED"
+	ErrCodePreconditionRequired ErrorCode = "PRECONDITION_REQUIRED"
+	ErrCodeUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
 )
 
 type AppError struct {
@@ -70,6 +74,15 @@ func ResponseSuccess(ctx *gin.Context, status int, message string, data ...any)
 	})
 }
 
+func ResponsePaginated(ctx *gin.Context, status int, message string, data any, meta any) {
+	ctx.JSON(status, gin.H{
+		"status":"SUCCESS",
+		"message": message,
+		"data": data,
+		"meta": meta,
+	})
+}
+
 func ResponseSatus(ctx *gin.Context, status int)  {
 	ctx.Status(status)
 }
@@ -87,8 +100,16 @@ func httpStatusFromCode(code ErrorCode) int {
 		return http.StatusNotFound
 	case ErrCodeUnauthorized:
 		return http.StatusUnauthorized
+	case ErrCodeForbidden:
+		return http.StatusForbidden
 	case ErrCodeTooManyRequest:
 		return http.StatusTooManyRequests
+	case ErrCodePreconditionFailed:
+		return http.StatusPreconditionFailed
+	case ErrCodePreconditionRequired:
+		return http.StatusPreconditionRequired
+	case ErrCodeUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
 	default :
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Sign returns the hex HMAC-SHA256 of parts, for links that must not be
+// altered on their way through a client.
+func Sign(key []byte, parts ...string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifySignature(key []byte, signature string, parts ...string) bool {
+	return hmac.Equal([]byte(Sign(key, parts...)), []byte(signature))
+}
@@ -48,10 +48,16 @@ func HandlerValidationErrors(err error) gin.H {
 				errors[fieldPath] = fmt.Sprintf("%s phải là một slug hợp lệ", fieldPath)
 			case "required":
 				errors[fieldPath] = fmt.Sprintf("%s là trường bắt buộc", fieldPath)
+			case "required_without":
+				errors[fieldPath] = fmt.Sprintf("%s là trường bắt buộc khi không có %s", fieldPath, utils.CamelToSnakeCase(e.Param()))
 			case "min":
 				errors[fieldPath] = fmt.Sprintf("%s phải có ít nhất %s ký tự", fieldPath, e.Param())
 			case "max":
 				errors[fieldPath] = fmt.Sprintf("%s không được vượt quá %s ký tự", fieldPath, e.Param())
+			case "len":
+				errors[fieldPath] = fmt.Sprintf("%s phải có đúng %s ký tự", fieldPath, e.Param())
+			case "numeric":
+				errors[fieldPath] = fmt.Sprintf("%s phải là số", fieldPath)
 			case "url":
 				errors[fieldPath] = fmt.Sprintf("%s phải là một URL hợp lệ", fieldPath)
 			case "minInt":
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ContextPayloadKey = "data"
+	ContextTokenIDKey = "jti"
+	ContextScopesKey = "scopes"
+	ContextPrincipalTypeKey = "principal_type"
+	ContextOrganizationKey = "organization_id"
+	ContextClientIDKey = "client_id"
+)
+
+// GetPayload returns the signed-in user. Service account tokens are not users
+// and never match, so user-facing handlers reject them on their own.
+func GetPayload(ctx *gin.Context) (*EncryptedPayload, bool) {
+	value, exists := ctx.Get(ContextPayloadKey)
+	if !exists {
+		return nil, false
+	}
+
+	payload, ok := value.(*EncryptedPayload)
+	if !ok || payload == nil || payload.PrincipalType == PrincipalService {
+		return nil, false
+	}
+
+	return payload, true
+}
+
+// GetServicePayload is the counterpart of GetPayload for service account tokens.
+func GetServicePayload(ctx *gin.Context) (*EncryptedPayload, bool) {
+	value, exists := ctx.Get(ContextPayloadKey)
+	if !exists {
+		return nil, false
+	}
+
+	payload, ok := value.(*EncryptedPayload)
+	if !ok || payload == nil || payload.PrincipalType != PrincipalService {
+		return nil, false
+	}
+
+	return payload, true
+}
+
+// ScopesFromClaims reports the scopes of an OAuth access token. First-party
+// tokens carry no scope claim and are not restricted.
+func ScopesFromClaims(claims map[string]any) ([]string, bool) {
+	scope, ok := claims["scope"].(string)
+	if !ok {
+		return nil, false
+	}
+
+	return strings.Fields(scope), true
+}
+
+func GetScopes(ctx *gin.Context) ([]string, bool) {
+	value, exists := ctx.Get(ContextScopesKey)
+	if !exists {
+		return nil, false
+	}
+
+	scopes, ok := value.([]string)
+	return scopes, ok
+}
+
+func GetPrincipalType(ctx *gin.Context) string {
+	return ctx.GetString(ContextPrincipalTypeKey)
+}
+
+// GetClientID returns the OAuth client or service account the access token
+// was issued to, empty for first-party tokens.
+func GetClientID(ctx *gin.Context) string {
+	return ctx.GetString(ContextClientIDKey)
+}
+
+// GetOrganizationID returns the tenant the access token was issued for, empty
+// when it acts outside any organization.
+func GetOrganizationID(ctx *gin.Context) string {
+	return ctx.GetString(ContextOrganizationKey)
+}
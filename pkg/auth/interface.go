@@ -1,16 +1,36 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/dangLuan01/user-manager/internal/models"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type TokenService interface {
-	GenerateAccessToken(user models.User) (string, error)
-	GenerateRefreshToken(user models.User) (RefreshToken, error)
+	GenerateAccessToken(user models.User, grant TokenGrant) (string, error)
+	GenerateRefreshToken(user models.User, grant TokenGrant) (RefreshToken, error)
+	GenerateServiceAccessToken(account models.ServiceAccount, scopes []string) (string, error)
 	ParseToken(tokenString string) (*jwt.Token, jwt.MapClaims, error)
+	JWKS() JSONWebKeySet
+	GenerateIDToken(user models.User, clientID, nonce string, scopes []string) (string, error)
+	Issuer() string
+	SigningAlgorithm() string
 	DecryptAccessTokenPayload(tokenString string) (*EncryptedPayload, error)
 	StoreRefreshToken(token RefreshToken) error
+	GetRefreshToken(token string) (RefreshToken, error)
 	ValidaRefreshToken(token string) (RefreshToken, error)
 	RevokeRefreshToken(token string) error
+	ClaimRefreshToken(token RefreshToken) (bool, error)
+	StoreRefreshFamily(family RefreshFamily) error
+	GetRefreshFamily(familyID string) (RefreshFamily, error)
+	RevokeRefreshFamily(familyID string) (RefreshFamily, error)
+	ListRefreshFamilies(userUUID uuid.UUID) ([]RefreshFamily, error)
+	RevokeUserRefreshFamilies(userUUID uuid.UUID) error
+	BlacklistAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenBlacklisted(jti string) (bool, error)
+	DisableServicePrincipal(clientID string) error
+	EnableServicePrincipal(clientID string) error
+	IsServicePrincipalDisabled(clientID string) (bool, error)
 }
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/dangLuan01/user-manager/internal/models"
@@ -15,6 +16,8 @@ import (
 
 type JWTService struct {
 	cache cache.RedisCacheService
+	keys *KeySet
+	issuer string
 }
 
 type Claim struct {
@@ -22,18 +25,55 @@ type Claim struct {
 }
 
 type EncryptedPayload struct {
-	UserUUID 	uuid.UUID `json:"user_uuid"`
-	Email 		string `json:"email"`
-	Role 		int8 `json:"role"`
+	UserUUID 		uuid.UUID `json:"user_uuid"`
+	Email 			string `json:"email"`
+	Role 			int8 `json:"role"`
+	// Empty on tokens issued before service accounts existed, which are all user tokens.
+	PrincipalType 	string `json:"principal_type,omitempty"`
+	ClientID 		string `json:"client_id,omitempty"`
 }
 
+const (
+	PrincipalUser = "user"
+	PrincipalService = "service"
+)
+
 type RefreshToken struct {
 	Token 		string `json:"token"`
 	UserUUID 	uuid.UUID `json:"user_uuid"`
+	FamilyID 	string `json:"family_id"`
+	ClientID 	string `json:"client_id,omitempty"`
+	Scopes 		[]string `json:"scopes,omitempty"`
+	OrganizationID 	string `json:"organization_id,omitempty"`
 	ExpiresAt 	time.Time `json:"expires_at"`
 	Revoked 	bool `json:"revoked"`
 }
 
+// TokenGrant narrows an access token to an OAuth client and its approved scopes.
+// The zero value is a first-party token without scope restrictions.
+// OrganizationID is the tenant the token acts in, empty for none.
+type TokenGrant struct {
+	ClientID 	string
+	Scopes 		[]string
+	OrganizationID 	string
+}
+
+// RefreshFamily groups every refresh token rotated out of a single login,
+// which makes it the unit we expose to users as a session.
+type RefreshFamily struct {
+	ID 					string `json:"id"`
+	UserUUID 			uuid.UUID `json:"user_uuid"`
+	ClientID 			string `json:"client_id,omitempty"`
+	UserAgent 			string `json:"user_agent"`
+	IP 					string `json:"ip"`
+	AccessJTI 			string `json:"access_jti"`
+	AccessExpiresAt 	time.Time `json:"access_expires_at"`
+	Revoked 			bool `json:"revoked"`
+	CreatedAt 			time.Time `json:"created_at"`
+	LastRefreshedAt 	time.Time `json:"last_refreshed_at"`
+	ExpiresAt 			time.Time `json:"expires_at"`
+}
+
 var (
 	jwtSecret = []byte(utils.GetEnv("JWT_SECRET","12345678901234567890123456789012"))
 	jwtEncryptKey = []byte(utils.GetEnv("JWT_ENCRYPT_KEY","12345678901234567890123456789012"))
@@ -43,46 +83,82 @@ const (
 	RefreshTokenTTL = 2 * 24 * time.Hour
 )
 
-func NewJWTService(cache cache.RedisCacheService) TokenService {
+func NewJWTService(cache cache.RedisCacheService, keys *KeySet) TokenService {
 	return &JWTService{
 		cache: cache,
+		keys: keys,
+		issuer: strings.TrimRight(utils.GetEnv("OIDC_ISSUER", "http://localhost:8080"), "/"),
 	}
 }
 
-func (js *JWTService) GenerateAccessToken(user models.User) (string, error) {
+func (js *JWTService) GenerateAccessToken(user models.User, grant TokenGrant) (string, error) {
 	payload := &EncryptedPayload{
 		UserUUID: 	user.UUID,
 		Email: 		user.Email,
 		Role: 		user.Level,
+		PrincipalType: PrincipalUser,
 	}
 
-	rawData, err := json.Marshal(payload)
+	encrypted, err := encryptPayload(payload)
 	if err != nil {
 		return "", err
 	}
+	
+	claims := jwt.MapClaims{
+		"data": encrypted,
+		"jti": uuid.NewString(),
+		"exp": jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
+		"iat": jwt.NewNumericDate(time.Now()),
+		"iss": user.Email,
+	}
+
+	if grant.ClientID != "" {
+		claims["client_id"] = grant.ClientID
+		claims["scope"] = strings.Join(grant.Scopes, " ")
+	}
+
+	if grant.OrganizationID != "" {
+		claims["org"] = grant.OrganizationID
+	}
 
-	encrypted, err := utils.EncrytAES(rawData, jwtEncryptKey)
+	return js.keys.Sign(claims)
+}
+
+// GenerateServiceAccessToken issues a token for a service account. It is
+// always scoped, even when no scope was granted.
+func (js *JWTService) GenerateServiceAccessToken(account models.ServiceAccount, scopes []string) (string, error) {
+	encrypted, err := encryptPayload(&EncryptedPayload{
+		PrincipalType: PrincipalService,
+		ClientID: account.ClientID,
+	})
 	if err != nil {
 		return "", err
 	}
-	
+
 	claims := jwt.MapClaims{
 		"data": encrypted,
 		"jti": uuid.NewString(),
 		"exp": jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 		"iat": jwt.NewNumericDate(time.Now()),
-		"iss": user.Email,
+		"iss": js.issuer,
+		"client_id": account.ClientID,
+		"scope": strings.Join(scopes, " "),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return js.keys.Sign(claims)
+}
 
-	return token.SignedString(jwtSecret)
+func encryptPayload(payload *EncryptedPayload) (string, error) {
+	rawData, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.EncrytAES(rawData, jwtEncryptKey)
 }
 
 func (js *JWTService) ParseToken(tokenString string) (*jwt.Token, jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.Parse(tokenString, js.keys.VerificationKey, jwt.WithValidMethods(js.keys.Algorithms()))
 
 	if err != nil || !token.Valid {
 		return nil, nil, utils.NewError(string(utils.ErrCodeUnauthorized), "Invalid token")
@@ -96,6 +172,10 @@ func (js *JWTService) ParseToken(tokenString string) (*jwt.Token, jwt.MapClaims,
 	return token, claim, nil
 }
 
+func (js *JWTService) JWKS() JSONWebKeySet {
+	return js.keys.JWKS()
+}
+
 func (js *JWTService) DecryptAccessTokenPayload(tokenString string) (*EncryptedPayload, error) {
 	_,claims, err := js.ParseToken(tokenString)
 	if err != nil {
@@ -121,7 +201,7 @@ func (js *JWTService) DecryptAccessTokenPayload(tokenString string) (*EncryptedP
 	return &payload, nil
 }
 
-func (js *JWTService) GenerateRefreshToken(user models.User) (RefreshToken, error) {
+func (js *JWTService) GenerateRefreshToken(user models.User, grant TokenGrant) (RefreshToken, error) {
 	tokenBytes := make([]byte, 32)
 
 	if _, err := rand.Read(tokenBytes); err != nil {
@@ -133,6 +213,10 @@ func (js *JWTService) GenerateRefreshToken(user models.User) (RefreshToken, erro
 	return RefreshToken{
 		Token: token,
 		UserUUID: user.UUID,
+		FamilyID: uuid.NewString(),
+		ClientID: grant.ClientID,
+		Scopes: grant.Scopes,
+		OrganizationID: grant.OrganizationID,
 		ExpiresAt: time.Now().Add(RefreshTokenTTL),
 		Revoked: false,
 	}, nil
@@ -143,14 +227,30 @@ func (js *JWTService) StoreRefreshToken(token RefreshToken) error {
 	return js.cache.Set(cacheKey, token, RefreshTokenTTL)
 }
 
-func (js *JWTService) ValidaRefreshToken(token string) (RefreshToken, error) {
+func (js *JWTService) GetRefreshToken(token string) (RefreshToken, error) {
 	cacheKey := "refresh_token:" + token
 
 	var refreshToken RefreshToken
-	if err := js.cache.Get(cacheKey, &refreshToken); err != nil || refreshToken.Revoked || refreshToken.ExpiresAt.Before(time.Now()) {
+	if err := js.cache.Get(cacheKey, &refreshToken); err != nil {
+		return RefreshToken{}, utils.WrapError(string(utils.ErrCodeInternal), "Cannot get refresh token", err)
+	}
+
+	return refreshToken, nil
+}
+
+func (js *JWTService) ValidaRefreshToken(token string) (RefreshToken, error) {
+	refreshToken, err := js.GetRefreshToken(token)
+	if err != nil || refreshToken.Revoked || refreshToken.ExpiresAt.Before(time.Now()) {
 		return RefreshToken{}, utils.WrapError(string(utils.ErrCodeInternal), "Cannot get refresh token", err)
 	}
 
+	if refreshToken.FamilyID != "" {
+		family, err := js.GetRefreshFamily(refreshToken.FamilyID)
+		if err != nil || family.Revoked {
+			return RefreshToken{}, utils.WrapError(string(utils.ErrCodeInternal), "Refresh token family revoked", err)
+		}
+	}
+
 	return refreshToken, nil
 }
 
@@ -165,4 +265,136 @@ func (js *JWTService) RevokeRefreshToken(token string) error {
 	refreshToken.Revoked = true
 
 	return js.cache.Set(cacheKey, refreshToken, time.Until(refreshToken.ExpiresAt))
-}
\ No newline at end of file
+}
+
+// ClaimRefreshToken marks token as rotated. Only the first caller gets true,
+// so concurrent refreshes with one token cannot both rotate it.
+func (js *JWTService) ClaimRefreshToken(token RefreshToken) (bool, error) {
+	return js.cache.SetNX("refresh_token:rotated:" + token.Token, true, time.Until(token.ExpiresAt))
+}
+
+func (js *JWTService) StoreRefreshFamily(family RefreshFamily) error {
+	cacheKey := "refresh_family:" + family.ID
+	if err := js.cache.Set(cacheKey, family, time.Until(family.ExpiresAt)); err != nil {
+		return err
+	}
+
+	if family.Revoked {
+		return js.cache.RemoveFromSet(userSessionsKey(family.UserUUID), family.ID)
+	}
+
+	return js.cache.AddToSet(userSessionsKey(family.UserUUID), RefreshTokenTTL, family.ID)
+}
+
+func (js *JWTService) GetRefreshFamily(familyID string) (RefreshFamily, error) {
+	cacheKey := "refresh_family:" + familyID
+
+	var family RefreshFamily
+	if err := js.cache.Get(cacheKey, &family); err != nil {
+		return RefreshFamily{}, utils.WrapError(string(utils.ErrCodeInternal), "Cannot get refresh token family", err)
+	}
+
+	return family, nil
+}
+
+// RevokeRefreshFamily keeps the revoked record until it expires so that any
+// member presented later is still recognised as belonging to a dead family.
+func (js *JWTService) RevokeRefreshFamily(familyID string) (RefreshFamily, error) {
+	family, err := js.GetRefreshFamily(familyID)
+	if err != nil {
+		return RefreshFamily{}, err
+	}
+
+	family.Revoked = true
+
+	if err := js.StoreRefreshFamily(family); err != nil {
+		return RefreshFamily{}, err
+	}
+
+	if family.AccessJTI != "" {
+		if err := js.BlacklistAccessToken(family.AccessJTI, family.AccessExpiresAt); err != nil {
+			return RefreshFamily{}, err
+		}
+	}
+
+	return family, nil
+}
+
+// ListRefreshFamilies returns the live sessions of a user and drops index
+// entries whose family has expired or been revoked.
+func (js *JWTService) ListRefreshFamilies(userUUID uuid.UUID) ([]RefreshFamily, error) {
+	indexKey := userSessionsKey(userUUID)
+
+	familyIDs, err := js.cache.SetMembers(indexKey)
+	if err != nil {
+		return nil, utils.WrapError(string(utils.ErrCodeInternal), "Cannot get sessions", err)
+	}
+
+	families := make([]RefreshFamily, 0, len(familyIDs))
+	stale := make([]string, 0)
+	for _, familyID := range familyIDs {
+		family, err := js.GetRefreshFamily(familyID)
+		if err != nil || family.Revoked || family.ExpiresAt.Before(time.Now()) {
+			stale = append(stale, familyID)
+			continue
+		}
+
+		families = append(families, family)
+	}
+
+	if len(stale) > 0 {
+		js.cache.RemoveFromSet(indexKey, stale...)
+	}
+
+	return families, nil
+}
+
+func (js *JWTService) RevokeUserRefreshFamilies(userUUID uuid.UUID) error {
+	families, err := js.ListRefreshFamilies(userUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, family := range families {
+		if _, err := js.RevokeRefreshFamily(family.ID); err != nil {
+			return err
+		}
+	}
+
+	return js.cache.Clear(userSessionsKey(userUUID))
+}
+
+func userSessionsKey(userUUID uuid.UUID) string {
+	return "user_sessions:" + userUUID.String()
+}
+
+func (js *JWTService) BlacklistAccessToken(jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return js.cache.Set("blacklist:" + jti, "revoked", ttl)
+}
+
+func (js *JWTService) IsAccessTokenBlacklisted(jti string) (bool, error) {
+	return js.cache.Exits("blacklist:" + jti)
+}
+
+// DisableServicePrincipal rejects the account's outstanding tokens until they
+// expire, new tokens are refused by the token endpoint itself.
+func (js *JWTService) DisableServicePrincipal(clientID string) error {
+	return js.cache.Set(servicePrincipalDisabledKey(clientID), "disabled", AccessTokenTTL)
+}
+
+func (js *JWTService) EnableServicePrincipal(clientID string) error {
+	return js.cache.Clear(servicePrincipalDisabledKey(clientID))
+}
+
+func (js *JWTService) IsServicePrincipalDisabled(clientID string) (bool, error) {
+	return js.cache.Exits(servicePrincipalDisabledKey(clientID))
+}
+
+func servicePrincipalDisabledKey(clientID string) string {
+	return "service_account:disabled:" + clientID
+}
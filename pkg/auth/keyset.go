@@ -0,0 +1,350 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type KeyStatus string
+
+const (
+	// Signs new tokens. Exactly one key in a set is active.
+	KeyStatusActive KeyStatus = "active"
+	// No longer signs, but tokens it issued are still accepted and it stays in the JWKS.
+	KeyStatusVerify KeyStatus = "verify"
+	// Kept in the file for bookkeeping only, tokens signed with it are rejected.
+	KeyStatusRetired KeyStatus = "retired"
+)
+
+const legacyKeyID = "legacy-hs256"
+
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	Status     KeyStatus
+	method     jwt.SigningMethod
+	privateKey any
+	publicKey  any
+}
+
+type KeySet struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+	// Verifies tokens issued before kid headers existed, nil when not accepted.
+	legacy *SigningKey
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type keySetFile struct {
+	// Accept HS256 tokens without a kid, signed with JWT_SECRET, while migrating off the shared secret.
+	AcceptLegacyHS256 bool `json:"accept_legacy_hs256"`
+	Keys              []struct {
+		ID             string    `json:"kid"`
+		Algorithm      string    `json:"alg"`
+		Status         KeyStatus `json:"status"`
+		PrivateKeyFile string    `json:"private_key_file"`
+		PublicKeyFile  string    `json:"public_key_file"`
+	} `json:"keys"`
+}
+
+// LoadKeySet reads the signing keys described by the JSON file at path. Without a
+// file the service keeps signing HS256 tokens with JWT_SECRET as before.
+func LoadKeySet(path string) (*KeySet, error) {
+	legacy := &SigningKey{
+		ID:         legacyKeyID,
+		Algorithm:  jwt.SigningMethodHS256.Alg(),
+		Status:     KeyStatusActive,
+		method:     jwt.SigningMethodHS256,
+		privateKey: jwtSecret,
+		publicKey:  jwtSecret,
+	}
+
+	if path == "" {
+		return &KeySet{
+			keys:   map[string]*SigningKey{legacy.ID: legacy},
+			active: legacy,
+			legacy: legacy,
+		}, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyset: %w", err)
+	}
+
+	var file keySetFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse keyset: %w", err)
+	}
+
+	ks := &KeySet{
+		keys: make(map[string]*SigningKey, len(file.Keys)),
+	}
+
+	baseDir := filepath.Dir(path)
+	for _, entry := range file.Keys {
+		if entry.ID == "" || entry.ID == legacyKeyID {
+			return nil, fmt.Errorf("keyset: invalid kid %q", entry.ID)
+		}
+
+		if _, exists := ks.keys[entry.ID]; exists {
+			return nil, fmt.Errorf("keyset: duplicate kid %q", entry.ID)
+		}
+
+		key, err := loadSigningKey(baseDir, entry.ID, entry.Algorithm, entry.Status, entry.PrivateKeyFile, entry.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Status == KeyStatusActive {
+			if ks.active != nil {
+				return nil, fmt.Errorf("keyset: more than one active key")
+			}
+			ks.active = key
+		}
+
+		ks.keys[key.ID] = key
+	}
+
+	if ks.active == nil {
+		return nil, fmt.Errorf("keyset: no active key")
+	}
+
+	if file.AcceptLegacyHS256 {
+		legacy.Status = KeyStatusVerify
+		ks.legacy = legacy
+	}
+
+	return ks, nil
+}
+
+func loadSigningKey(baseDir, kid, alg string, status KeyStatus, privateKeyFile, publicKeyFile string) (*SigningKey, error) {
+	switch status {
+	case KeyStatusActive, KeyStatusVerify, KeyStatusRetired:
+	default:
+		return nil, fmt.Errorf("keyset: key %q has unknown status %q", kid, status)
+	}
+
+	key := &SigningKey{
+		ID:        kid,
+		Algorithm: alg,
+		Status:    status,
+	}
+
+	if status == KeyStatusRetired {
+		return key, nil
+	}
+
+	if status == KeyStatusActive && privateKeyFile == "" {
+		return nil, fmt.Errorf("keyset: active key %q needs a private key", kid)
+	}
+
+	readPEM := func(name string) ([]byte, error) {
+		if name == "" {
+			return nil, nil
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(baseDir, name)
+		}
+		return os.ReadFile(name)
+	}
+
+	privatePEM, err := readPEM(privateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("keyset: read private key %q: %w", kid, err)
+	}
+
+	publicPEM, err := readPEM(publicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("keyset: read public key %q: %w", kid, err)
+	}
+
+	if privatePEM == nil && publicPEM == nil {
+		return nil, fmt.Errorf("keyset: key %q has no key material", kid)
+	}
+
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if privatePEM != nil {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, fmt.Errorf("keyset: parse key %q: %w", kid, err)
+			}
+			key.privateKey, key.publicKey = private, &private.PublicKey
+		} else {
+			key.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+		}
+	case jwt.SigningMethodES256.Alg():
+		key.method = jwt.SigningMethodES256
+		if privatePEM != nil {
+			private, err := jwt.ParseECPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, fmt.Errorf("keyset: parse key %q: %w", kid, err)
+			}
+			if private.Curve != elliptic.P256() {
+				return nil, fmt.Errorf("keyset: key %q must use P-256 for ES256", kid)
+			}
+			key.privateKey, key.publicKey = private, &private.PublicKey
+		} else {
+			key.publicKey, err = jwt.ParseECPublicKeyFromPEM(publicPEM)
+		}
+	case jwt.SigningMethodEdDSA.Alg():
+		key.method = jwt.SigningMethodEdDSA
+		if privatePEM != nil {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, fmt.Errorf("keyset: parse key %q: %w", kid, err)
+			}
+			edPrivate, ok := private.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("keyset: key %q is not an Ed25519 key", kid)
+			}
+			key.privateKey, key.publicKey = edPrivate, edPrivate.Public()
+		} else {
+			key.publicKey, err = jwt.ParseEdPublicKeyFromPEM(publicPEM)
+		}
+	default:
+		return nil, fmt.Errorf("keyset: key %q uses unsupported algorithm %q", kid, alg)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("keyset: parse key %q: %w", kid, err)
+	}
+
+	return key, nil
+}
+
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// Algorithms lists every algorithm a token may legitimately be signed with.
+func (ks *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	algorithms := make([]string, 0)
+
+	add := func(key *SigningKey) {
+		if key == nil || key.Status == KeyStatusRetired || seen[key.Algorithm] {
+			return
+		}
+		seen[key.Algorithm] = true
+		algorithms = append(algorithms, key.Algorithm)
+	}
+
+	for _, key := range ks.keys {
+		add(key)
+	}
+	add(ks.legacy)
+
+	return algorithms
+}
+
+// VerificationKey resolves the key for a token header. Retired keys and keys
+// whose algorithm differs from the header are refused.
+func (ks *KeySet) VerificationKey(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	var key *SigningKey
+	if kid == "" {
+		key = ks.legacy
+	} else {
+		key = ks.keys[kid]
+	}
+
+	if key == nil || key.Status == KeyStatusRetired {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if t.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing algorithm %q", t.Method.Alg())
+	}
+
+	return key.publicKey, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	if ks.active != ks.legacy {
+		token.Header["kid"] = ks.active.ID
+	}
+
+	return token.SignedString(ks.active.privateKey)
+}
+
+// JWKS publishes the public half of every key that can still verify tokens.
+// Symmetric keys are never published.
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{
+		Keys: make([]JSONWebKey, 0, len(ks.keys)),
+	}
+
+	for _, key := range ks.keys {
+		if key.Status == KeyStatusRetired {
+			continue
+		}
+
+		jwk := JSONWebKey{
+			Use: "sig",
+			Kid: key.ID,
+			Alg: key.Algorithm,
+		}
+
+		switch public := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeSegment(public.N.Bytes())
+			jwk.E = encodeSegment(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = public.Curve.Params().Name
+			jwk.X = encodeSegment(public.X.FillBytes(make([]byte, size)))
+			jwk.Y = encodeSegment(public.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeSegment(public)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
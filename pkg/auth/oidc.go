@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"slices"
+	"time"
+
+	"github.com/dangLuan01/user-manager/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var IDTokenTTL = AccessTokenTTL
+
+// UserClaims returns the standard OIDC claims of user that scopes allow
+// a client to see. sub is always present.
+func UserClaims(user models.User, scopes []string) map[string]any {
+	claims := map[string]any{
+		"sub": user.UUID.String(),
+	}
+
+	if slices.Contains(scopes, "email") {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerifiedAt != nil
+	}
+
+	if slices.Contains(scopes, "profile") {
+		claims["name"] = user.Name
+	}
+
+	return claims
+}
+
+func (js *JWTService) GenerateIDToken(user models.User, clientID, nonce string, scopes []string) (string, error) {
+	claims := jwt.MapClaims{
+		"iss": js.issuer,
+		"aud": clientID,
+		"azp": clientID,
+		"exp": jwt.NewNumericDate(time.Now().Add(IDTokenTTL)),
+		"iat": jwt.NewNumericDate(time.Now()),
+	}
+
+	for key, value := range UserClaims(user, scopes) {
+		claims[key] = value
+	}
+
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	return js.keys.Sign(claims)
+}
+
+func (js *JWTService) Issuer() string {
+	return js.issuer
+}
+
+func (js *JWTService) SigningAlgorithm() string {
+	return js.keys.Active().Algorithm
+}
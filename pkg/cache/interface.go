@@ -5,6 +5,10 @@ import "time"
 type RedisCacheService interface {
 	Get(key string, dest any) error
 	Set(key string, value any, ttl time.Duration) error
+	SetNX(key string, value any, ttl time.Duration) (bool, error)
 	Exits(key string) (bool, error)
 	Clear(key string) error
+	AddToSet(key string, ttl time.Duration, members ...string) error
+	SetMembers(key string) ([]string, error)
+	RemoveFromSet(key string, members ...string) error
 }
\ No newline at end of file
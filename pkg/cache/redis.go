@@ -44,6 +44,17 @@ func (cs *redisCacheService) Set(key string, value any, ttl time.Duration) error
 	return cs.rdb.Set(cs.ctx, key, data, ttl).Err()
 }
 
+// SetNX stores value only when key is absent and reports whether it did, in a
+// single command so concurrent callers cannot both win.
+func (cs *redisCacheService) SetNX(key string, value any, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	return cs.rdb.SetNX(cs.ctx, key, data, ttl).Result()
+}
+
 func (cs *redisCacheService) Exits(key string) (bool, error) {
 	count, err := cs.rdb.Exists(cs.ctx, key).Result()
 	if err != nil {
@@ -60,4 +71,36 @@ func (cs *redisCacheService) Clear(key string) error {
 	}
 
 	return nil
+}
+
+func (cs *redisCacheService) AddToSet(key string, ttl time.Duration, members ...string) error {
+	values := make([]any, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+
+	pipe := cs.rdb.TxPipeline()
+	pipe.SAdd(cs.ctx, key, values...)
+	pipe.Expire(cs.ctx, key, ttl)
+
+	_, err := pipe.Exec(cs.ctx)
+	return err
+}
+
+func (cs *redisCacheService) SetMembers(key string) ([]string, error) {
+	members, err := cs.rdb.SMembers(cs.ctx, key).Result()
+	if err == redis.Nil {
+		return []string{}, nil
+	}
+
+	return members, err
+}
+
+func (cs *redisCacheService) RemoveFromSet(key string, members ...string) error {
+	values := make([]any, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+
+	return cs.rdb.SRem(cs.ctx, key, values...).Err()
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// jsonEquivalent compares two documents by value, ignoring member order.
+func jsonEquivalent(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+
+	var gotValue, wantValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("result %s is not JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("expected %s is not JSON: %v", want, err)
+	}
+
+	return reflect.DeepEqual(gotValue, wantValue)
+}
+
+func TestMergePatch(t *testing.T) {
+	// RFC 7396 appendix A, plus number handling.
+	tests := []struct {
+		doc 	string
+		patch 	string
+		want 	string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"age":30}`, `{"age":12345678901234567890}`, `{"age":12345678901234567890}`},
+	}
+
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s) error: %v", tt.doc, tt.patch, err)
+			continue
+		}
+
+		if !jsonEquivalent(t, got, tt.want) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	tests := []struct {
+		name 	string
+		doc 	string
+		patch 	string
+	}{
+		{"invalid document", `{"a":`, `{}`},
+		{"invalid patch", `{}`, `{"a":}`},
+		{"trailing data", `{}`, `{"a":1} {"b":2}`},
+		{"empty patch", `{}`, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := MergePatch([]byte(tt.doc), []byte(tt.patch)); err == nil {
+				t.Errorf("MergePatch(%s, %s) = %s, want an error", tt.doc, tt.patch, got)
+			}
+		})
+	}
+}
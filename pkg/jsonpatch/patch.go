@@ -0,0 +1,320 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned when a test operation does not hold, the
+// document changed since the patch was written.
+var ErrTestFailed = errors.New("test operation failed")
+
+// Operation is one step of an RFC 6902 JSON Patch.
+type Operation struct {
+	Op 		string `json:"op"`
+	Path 	string `json:"path"`
+	From 	string `json:"from,omitempty"`
+	// Nil when the operation carries no value, a JSON null is "null".
+	Value 	json.RawMessage `json:"value,omitempty"`
+}
+
+type Patch []Operation
+
+func DecodePatch(data []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("invalid json patch:%v", err)
+	}
+
+	for i, operation := range patch {
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return nil, fmt.Errorf("operation %d: %s needs a value", i, operation.Op)
+			}
+		case "move", "copy":
+			if _, err := parsePointer(operation.From); err != nil {
+				return nil, fmt.Errorf("operation %d: %v", i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, operation.Op)
+		}
+
+		if _, err := parsePointer(operation.Path); err != nil {
+			return nil, fmt.Errorf("operation %d: %v", i, err)
+		}
+	}
+
+	return patch, nil
+}
+
+// Apply runs the operations in order on doc. The patch is atomic, on any
+// error doc is left as it was and no document is returned.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	root, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document:%v", err)
+	}
+
+	for i, operation := range p {
+		root, err = apply(root, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func apply(root any, operation Operation) (any, error) {
+	path, _ := parsePointer(operation.Path)
+
+	switch operation.Op {
+	case "add":
+		value, err := decode(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "remove":
+		root, _, err := remove(root, path)
+		return root, err
+	case "replace":
+		value, err := decode(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		root, _, err = remove(root, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "move":
+		from, _ := parsePointer(operation.From)
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, fmt.Errorf("cannot move %s into itself", operation.From)
+		}
+		root, value, err := remove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "copy":
+		from, _ := parsePointer(operation.From)
+		value, err := get(root, from)
+		if err != nil {
+			return nil, err
+		}
+		value, err = deepCopy(value)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "test":
+		value, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+		equal, err := jsonEqual(value, operation.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			return nil, fmt.Errorf("%w at %s", ErrTestFailed, operation.Path)
+		}
+		return root, nil
+	}
+
+	return nil, fmt.Errorf("unknown op %q", operation.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch current := node.(type) {
+		case map[string]any:
+			value, ok := current[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", token)
+			}
+			node = value
+		case []any:
+			index, err := arrayIndex(token, len(current) - 1)
+			if err != nil {
+				return nil, err
+			}
+			node = current[index]
+		default:
+			return nil, fmt.Errorf("path %q not found", token)
+		}
+	}
+
+	return node, nil
+}
+
+// add returns node with value placed at path. Objects gain or overwrite a
+// member, arrays insert before the index or append for "-".
+func add(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch current := node.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			current[token] = value
+			return current, nil
+		}
+
+		child, ok := current[token]
+		if !ok {
+			return nil, fmt.Errorf("path %q not found", token)
+		}
+
+		child, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		current[token] = child
+		return current, nil
+	case []any:
+		if len(path) == 1 {
+			if token == "-" {
+				return append(current, value), nil
+			}
+
+			index, err := arrayIndex(token, len(current))
+			if err != nil {
+				return nil, err
+			}
+			return append(current[:index], append([]any{value}, current[index:]...)...), nil
+		}
+
+		index, err := arrayIndex(token, len(current) - 1)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := add(current[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		current[index] = child
+		return current, nil
+	}
+
+	return nil, fmt.Errorf("path %q not found", token)
+}
+
+// remove returns node without the value at path, together with that value.
+func remove(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	token := path[0]
+	switch current := node.(type) {
+	case map[string]any:
+		child, ok := current[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %q not found", token)
+		}
+
+		if len(path) == 1 {
+			delete(current, token)
+			return current, child, nil
+		}
+
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		current[token] = child
+		return current, removed, nil
+	case []any:
+		index, err := arrayIndex(token, len(current) - 1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(path) == 1 {
+			removed := current[index]
+			return append(current[:index], current[index+1:]...), removed, nil
+		}
+
+		child, removed, err := remove(current[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		current[index] = child
+		return current, removed, nil
+	}
+
+	return nil, nil, fmt.Errorf("path %q not found", token)
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max {
+		return 0, fmt.Errorf("array index %q out of range", token)
+	}
+
+	return index, nil
+}
+
+func deepCopy(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(data)
+}
+
+// jsonEqual compares by JSON value, so 1 and 1.0 are the same number.
+func jsonEqual(value any, raw json.RawMessage) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	var left, right any
+	if err := json.Unmarshal(data, &left); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, &right); err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(left, right), nil
+}
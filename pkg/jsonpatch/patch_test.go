@@ -0,0 +1,120 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	// RFC 6902 appendix A, then the operations it leaves out.
+	tests := []struct {
+		name 	string
+		doc 	string
+		patch 	string
+		want 	string
+	}{
+		{"add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"unrecognized members", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{"escaped pointer", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"append array value", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"replace with null", `{"name":"Ann"}`, `[{"op":"replace","path":"/name","value":null}]`, `{"name":null}`},
+		{"replace the document", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{"test number forms", `{"age":1}`, `[{"op":"test","path":"/age","value":1.0}]`, `{"age":1}`},
+		{"operations run in order", `{}`, `[{"op":"add","path":"/a","value":[]},{"op":"add","path":"/a/0","value":1},{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := DecodePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("DecodePatch(%s) error: %v", tt.patch, err)
+			}
+
+			got, err := patch.Apply([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Apply(%s) error: %v", tt.doc, err)
+			}
+
+			if !jsonEquivalent(t, got, tt.want) {
+				t.Errorf("Apply(%s) = %s, want %s", tt.doc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyFails(t *testing.T) {
+	tests := []struct {
+		name 		string
+		doc 		string
+		patch 		string
+		testFailed 	bool
+	}{
+		{"test mismatch", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, true},
+		{"test string against number", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, true},
+		{"add to a missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, false},
+		{"remove a missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, false},
+		{"replace a missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, false},
+		{"remove the document", `{"foo":"bar"}`, `[{"op":"remove","path":""}]`, false},
+		{"move into itself", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, false},
+		{"index past the end", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":2}]`, false},
+		{"index with a leading zero", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, false},
+		{"negative index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/-1"}]`, false},
+		{"index into an object", `{"a":"b"}`, `[{"op":"add","path":"/a/b","value":1}]`, false},
+		{"later operation fails", `{"a":1}`, `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, true},
+		{"invalid document", `{"a":`, `[]`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := DecodePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("DecodePatch(%s) error: %v", tt.patch, err)
+			}
+
+			got, err := patch.Apply([]byte(tt.doc))
+			if err == nil {
+				t.Fatalf("Apply(%s) = %s, want an error", tt.doc, got)
+			}
+
+			if got != nil {
+				t.Errorf("Apply(%s) returned %s alongside the error", tt.doc, got)
+			}
+
+			if errors.Is(err, ErrTestFailed) != tt.testFailed {
+				t.Errorf("Apply(%s) error %v, want ErrTestFailed %v", tt.doc, err, tt.testFailed)
+			}
+		})
+	}
+}
+
+func TestDecodePatchInvalid(t *testing.T) {
+	tests := []struct {
+		name 	string
+		patch 	string
+	}{
+		{"not an array", `{"op":"add","path":"/a","value":1}`},
+		{"unknown op", `[{"op":"merge","path":"/a","value":1}]`},
+		{"add without value", `[{"op":"add","path":"/a"}]`},
+		{"test without value", `[{"op":"test","path":"/a"}]`},
+		{"path without slash", `[{"op":"remove","path":"a"}]`},
+		{"from without slash", `[{"op":"move","from":"a","path":"/b"}]`},
+		{"invalid JSON", `[{"op":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodePatch([]byte(tt.patch)); err == nil {
+				t.Errorf("DecodePatch(%s) succeeded, want an error", tt.patch)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package search
+
+// Document is one searchable record, Fields maps a field name to its text.
+// Attributes are not searched, only compared exactly by a Filter.
+type Document struct {
+	ID 			string
+	Fields 		map[string]string
+	Attributes 	map[string]string
+}
+
+// Filter keeps the documents whose attributes equal every value it holds, a
+// missing attribute counts as empty. A nil Filter keeps everything.
+type Filter map[string]string
+
+// Hit is a ranked match. Highlights holds the matching fields, HTML escaped,
+// with every matched fragment wrapped in HighlightOpen and HighlightClose.
+type Hit struct {
+	ID 			string `json:"id"`
+	Score 		float64 `json:"score"`
+	Highlights 	map[string]string `json:"highlights"`
+}
+
+const (
+	HighlightOpen = "<em>"
+	HighlightClose = "</em>"
+)
+
+// SearchIndex is implemented by every search backend. Index replaces a
+// document with the same ID, Reindex replaces the whole content.
+type SearchIndex interface {
+	Index(docs ...Document) error
+	Remove(ids ...string) error
+	Search(query string, filter Filter, limit int) ([]Hit, error)
+	Reindex(docs []Document) error
+	Len() int
+}
@@ -0,0 +1,386 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type memoryIndex struct {
+	mu sync.RWMutex
+	weights map[string]float64
+	docs map[string]Document
+	// term -> document id -> fields the term occurs in
+	postings map[string]map[string][]string
+}
+
+// NewMemoryIndex returns an in-process index. weights ranks fields against
+// each other, fields without a weight count as 1.
+func NewMemoryIndex(weights map[string]float64) SearchIndex {
+	return &memoryIndex{
+		weights: weights,
+		docs: make(map[string]Document),
+		postings: make(map[string]map[string][]string),
+	}
+}
+
+func (mi *memoryIndex) Index(docs ...Document) error {
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+
+	for _, doc := range docs {
+		mi.remove(doc.ID)
+		mi.add(doc)
+	}
+
+	return nil
+}
+
+func (mi *memoryIndex) Remove(ids ...string) error {
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+
+	for _, id := range ids {
+		mi.remove(id)
+	}
+
+	return nil
+}
+
+func (mi *memoryIndex) Reindex(docs []Document) error {
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+
+	mi.docs = make(map[string]Document, len(docs))
+	mi.postings = make(map[string]map[string][]string)
+	for _, doc := range docs {
+		mi.add(doc)
+	}
+
+	return nil
+}
+
+func (mi *memoryIndex) Len() int {
+	mi.mu.RLock()
+	defer mi.mu.RUnlock()
+
+	return len(mi.docs)
+}
+
+// Search ranks the documents that pass filter and match every word of query,
+// each word exactly, as a prefix or within a small edit distance.
+func (mi *memoryIndex) Search(query string, filter Filter, limit int) ([]Hit, error) {
+	words := terms(query)
+	if len(words) == 0 {
+		return []Hit{}, nil
+	}
+
+	mi.mu.RLock()
+	defer mi.mu.RUnlock()
+
+	// document id -> best score per query word
+	scores := make(map[string][]float64)
+	for i, word := range words {
+		for term, postings := range mi.postings {
+			score := matchScore(word, term)
+			if score == 0 {
+				continue
+			}
+
+			for id, fields := range postings {
+				if !filter.matches(mi.docs[id]) {
+					continue
+				}
+
+				if scores[id] == nil {
+					scores[id] = make([]float64, len(words))
+				}
+
+				for _, field := range fields {
+					scores[id][i] = max(scores[id][i], score * mi.weight(field))
+				}
+			}
+		}
+	}
+
+	hits := make([]Hit, 0)
+	for id, wordScores := range scores {
+		if slices.Contains(wordScores, 0) {
+			continue
+		}
+
+		var total float64
+		for _, score := range wordScores {
+			total += score
+		}
+
+		hits = append(hits, Hit{
+			ID: id,
+			Score: total / float64(len(words)),
+		})
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	for i := range hits {
+		hits[i].Highlights = highlight(mi.docs[hits[i].ID], words)
+	}
+
+	return hits, nil
+}
+
+func (filter Filter) matches(doc Document) bool {
+	for key, value := range filter {
+		if doc.Attributes[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (mi *memoryIndex) add(doc Document) {
+	mi.docs[doc.ID] = doc
+
+	for field, text := range doc.Fields {
+		for _, term := range terms(text) {
+			if mi.postings[term] == nil {
+				mi.postings[term] = make(map[string][]string)
+			}
+
+			if !slices.Contains(mi.postings[term][doc.ID], field) {
+				mi.postings[term][doc.ID] = append(mi.postings[term][doc.ID], field)
+			}
+		}
+	}
+}
+
+func (mi *memoryIndex) remove(id string) {
+	doc, ok := mi.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, text := range doc.Fields {
+		for _, term := range terms(text) {
+			delete(mi.postings[term], id)
+			if len(mi.postings[term]) == 0 {
+				delete(mi.postings, term)
+			}
+		}
+	}
+
+	delete(mi.docs, id)
+}
+
+func (mi *memoryIndex) weight(field string) float64 {
+	if weight, ok := mi.weights[field]; ok {
+		return weight
+	}
+
+	return 1
+}
+
+type token struct {
+	term 		string
+	start, end 	int
+}
+
+// tokenize splits text into lowercase words of letters and digits, keeping
+// their byte offsets in text for highlighting.
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+	start := -1
+
+	for i, r := range text {
+		wordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if wordRune && start < 0 {
+			start = i
+		}
+		if !wordRune && start >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+func terms(text string) []string {
+	tokens := tokenize(text)
+	result := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !slices.Contains(result, token.term) {
+			result = append(result, token.term)
+		}
+	}
+
+	return result
+}
+
+// matchScore rates how well term matches a query word, 0 meaning not at all.
+// Longer words tolerate more typos.
+func matchScore(word, term string) float64 {
+	if word == term {
+		return 1
+	}
+
+	if len([]rune(word)) >= 2 && strings.HasPrefix(term, word) {
+		return 0.8
+	}
+
+	wordRunes, termRunes := []rune(word), []rune(term)
+	allowed := typoAllowance(len(wordRunes))
+	if allowed == 0 {
+		return 0
+	}
+
+	if distance := editDistance(wordRunes, termRunes, allowed); distance <= allowed {
+		return 0.6 - 0.15 * float64(distance - 1)
+	}
+
+	// A typo in a word that is still being typed.
+	if len(termRunes) > len(wordRunes) && editDistance(wordRunes, termRunes[:len(wordRunes)], 1) <= 1 {
+		return 0.4
+	}
+
+	return 0
+}
+
+func typoAllowance(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance between a and b,
+// giving up once it is certain to exceed limit.
+func editDistance(a, b []rune, limit int) int {
+	if abs(len(a) - len(b)) > limit {
+		return limit + 1
+	}
+
+	previous2 := make([]int, len(b) + 1)
+	previous := make([]int, len(b) + 1)
+	current := make([]int, len(b) + 1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j] + 1, current[j-1] + 1, previous[j-1] + cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2] + 1)
+			}
+
+			rowMin = min(rowMin, current[j])
+		}
+
+		if rowMin > limit {
+			return limit + 1
+		}
+
+		previous2, previous, current = previous, current, previous2
+	}
+
+	return previous[len(b)]
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// highlight marks the fragments of each field that matched a query word and
+// escapes the rest, so the result is safe to render as HTML. Prefix matches
+// only mark the typed part.
+func highlight(doc Document, words []string) map[string]string {
+	highlights := make(map[string]string)
+
+	for field, text := range doc.Fields {
+		var builder strings.Builder
+		last := 0
+		matched := false
+
+		for _, token := range tokenize(text) {
+			end := -1
+			for _, word := range words {
+				score := matchScore(word, token.term)
+				if score == 0 {
+					continue
+				}
+
+				if score == 0.8 {
+					end = max(end, token.start + prefixBytes(text[token.start:token.end], len([]rune(word))))
+				} else {
+					end = token.end
+				}
+			}
+
+			if end < 0 {
+				continue
+			}
+
+			builder.WriteString(html.EscapeString(text[last:token.start]))
+			builder.WriteString(HighlightOpen)
+			builder.WriteString(html.EscapeString(text[token.start:end]))
+			builder.WriteString(HighlightClose)
+			last = end
+			matched = true
+		}
+
+		if matched {
+			builder.WriteString(html.EscapeString(text[last:]))
+			highlights[field] = builder.String()
+		}
+	}
+
+	return highlights
+}
+
+// prefixBytes returns the byte length of the first n runes of text.
+func prefixBytes(text string, n int) int {
+	count := 0
+	for i := range text {
+		if count == n {
+			return i
+		}
+		count++
+	}
+
+	return len(text)
+}
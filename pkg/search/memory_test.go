@@ -0,0 +1,188 @@
+package search
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func testIndex(t *testing.T) SearchIndex {
+	t.Helper()
+
+	index := NewMemoryIndex(map[string]float64{"name": 2})
+	err := index.Index(
+		Document{ID: "1", Fields: map[string]string{"name": "Ann Smith", "email": "ann@example.com"}, Attributes: map[string]string{"org": "a"}},
+		Document{ID: "2", Fields: map[string]string{"name": "Annabel Jones", "email": "bel@example.com"}, Attributes: map[string]string{"org": "b"}},
+		Document{ID: "3", Fields: map[string]string{"name": "Bob <Admin>", "email": "bob@example.com"}},
+	)
+	if err != nil {
+		t.Fatalf("Index error: %v", err)
+	}
+
+	return index
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	index := testIndex(t)
+
+	tests := []struct {
+		name 	string
+		query 	string
+		filter 	Filter
+		limit 	int
+		want 	[]string
+	}{
+		{name: "exact before prefix", query: "ann", want: []string{"1", "2"}},
+		{name: "case insensitive", query: "ANN smith", want: []string{"1"}},
+		{name: "every word must match", query: "smith jones", want: []string{}},
+		{name: "transposed letters", query: "smiht", want: []string{"1"}},
+		{name: "one typo", query: "jomes", want: []string{"2"}},
+		{name: "typo while typing", query: "jonx", want: []string{"2"}},
+		{name: "short words need no typo", query: "bib", want: []string{}},
+		{name: "weighted field first", query: "bob", want: []string{"3"}},
+		{name: "ties by id", query: "example", want: []string{"1", "2", "3"}},
+		{name: "limit", query: "example", limit: 2, want: []string{"1", "2"}},
+		{name: "filter", query: "ann", filter: Filter{"org": "b"}, want: []string{"2"}},
+		{name: "missing attribute is empty", query: "example", filter: Filter{"org": ""}, want: []string{"3"}},
+		{name: "empty query", query: " ,. ", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := index.Search(tt.query, tt.filter, tt.limit)
+			if err != nil {
+				t.Fatalf("Search(%q) error: %v", tt.query, err)
+			}
+
+			if got := hitIDs(hits); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchScores(t *testing.T) {
+	index := testIndex(t)
+
+	tests := []struct {
+		query 	string
+		want 	float64
+	}{
+		{"ann", 2},
+		{"annab", 1.6},
+		{"smiht", 1.2},
+		{"jonx", 0.8},
+		{"example", 1},
+		{"ann smith", 2},
+	}
+
+	for _, tt := range tests {
+		hits, err := index.Search(tt.query, nil, 1)
+		if err != nil || len(hits) == 0 {
+			t.Fatalf("Search(%q) = %v, %v, want a hit", tt.query, hits, err)
+		}
+
+		if hits[0].Score != tt.want {
+			t.Errorf("Search(%q) score = %v, want %v", tt.query, hits[0].Score, tt.want)
+		}
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	index := testIndex(t)
+
+	tests := []struct {
+		query 	string
+		want 	map[string]string
+	}{
+		{"bob", map[string]string{"name": "<em>Bob</em> &lt;Admin&gt;", "email": "<em>bob</em>@example.com"}},
+		{"annab", map[string]string{"name": "<em>Annab</em>el Jones"}},
+		{"smiht ann", map[string]string{"name": "<em>Ann</em> <em>Smith</em>", "email": "<em>ann</em>@example.com"}},
+	}
+
+	for _, tt := range tests {
+		hits, err := index.Search(tt.query, nil, 1)
+		if err != nil || len(hits) == 0 {
+			t.Fatalf("Search(%q) = %v, %v, want a hit", tt.query, hits, err)
+		}
+
+		if !maps.Equal(hits[0].Highlights, tt.want) {
+			t.Errorf("Search(%q) highlights = %v, want %v", tt.query, hits[0].Highlights, tt.want)
+		}
+	}
+}
+
+func TestIndexUpdates(t *testing.T) {
+	index := testIndex(t)
+
+	search := func(query string) []string {
+		hits, err := index.Search(query, nil, 0)
+		if err != nil {
+			t.Fatalf("Search(%q) error: %v", query, err)
+		}
+		return hitIDs(hits)
+	}
+
+	if err := index.Index(Document{ID: "1", Fields: map[string]string{"name": "Carl"}}); err != nil {
+		t.Fatalf("Index error: %v", err)
+	}
+	if got := search("smith"); len(got) != 0 {
+		t.Errorf("after replacing 1, Search(smith) = %v, want none", got)
+	}
+	if got := search("carl"); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("after replacing 1, Search(carl) = %v, want [1]", got)
+	}
+	if index.Len() != 3 {
+		t.Errorf("after replacing 1, Len = %d, want 3", index.Len())
+	}
+
+	if err := index.Remove("2", "unknown"); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	if got := search("annabel"); len(got) != 0 {
+		t.Errorf("after removing 2, Search(annabel) = %v, want none", got)
+	}
+	if index.Len() != 2 {
+		t.Errorf("after removing 2, Len = %d, want 2", index.Len())
+	}
+
+	if err := index.Reindex([]Document{{ID: "4", Fields: map[string]string{"name": "Dana"}}}); err != nil {
+		t.Fatalf("Reindex error: %v", err)
+	}
+	if got := search("carl bob dana"); len(got) != 0 {
+		t.Errorf("after Reindex, Search = %v, want none", got)
+	}
+	if got := search("dana"); !slices.Equal(got, []string{"4"}) || index.Len() != 1 {
+		t.Errorf("after Reindex, Search(dana) = %v and Len = %d, want [4] and 1", got, index.Len())
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b 	string
+		limit 	int
+		want 	int
+	}{
+		{"smith", "smith", 2, 0},
+		{"smith", "smiht", 2, 1},
+		{"smith", "smyth", 2, 1},
+		{"smith", "smit", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 1, 2},
+		{"a", "abcd", 2, 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b), tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
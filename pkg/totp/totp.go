@@ -0,0 +1,105 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits     = 6
+	Period     = 30 * time.Second
+	SecretSize = 20
+	// Number of periods accepted before and after the current one to absorb clock drift.
+	Skew = 1
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return b32.EncodeToString(secret), nil
+}
+
+func GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := b32.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, uint64(t.Unix()/int64(Period.Seconds()))), nil
+}
+
+// Validate reports whether code matches the secret at time t, allowing Skew periods of drift.
+func Validate(code, secret string, t time.Time) bool {
+	_, ok := ValidateStep(code, secret, t)
+	return ok
+}
+
+// ValidateStep is Validate that also returns the time step the code belongs
+// to, so a caller can refuse a step that was already used.
+func ValidateStep(code, secret string, t time.Time) (uint64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	key, err := b32.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return 0, false
+	}
+
+	counter := t.Unix() / int64(Period.Seconds())
+	for i := -Skew; i <= Skew; i++ {
+		step := uint64(counter+int64(i))
+		expected := hotp(key, step)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// KeyURI builds the otpauth:// URI understood by authenticator apps.
+func KeyURI(issuer, account, secret string) string {
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, account))
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", Digits))
+	params.Set("period", fmt.Sprintf("%d", int(Period.Seconds())))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, strings.ReplaceAll(params.Encode(), "+", "%20"))
+}
+
+func hotp(key []byte, counter uint64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
@@ -0,0 +1,116 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// The SHA1 secret of RFC 6238 appendix B, "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to the last Digits digits.
+	tests := []struct {
+		unix 	int64
+		want 	string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode at %d error: %v", tt.unix, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("GenerateCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateStep(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	counter := uint64(now.Unix() / int64(Period.Seconds()))
+
+	codeAt := func(offset time.Duration) string {
+		code, err := GenerateCode(rfcSecret, now.Add(offset))
+		if err != nil {
+			t.Fatalf("GenerateCode error: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name 	string
+		code 	string
+		secret 	string
+		step 	uint64
+		ok 		bool
+	}{
+		{"current period", codeAt(0), rfcSecret, counter, true},
+		{"previous period", codeAt(-Period), rfcSecret, counter - 1, true},
+		{"next period", codeAt(Period), rfcSecret, counter + 1, true},
+		{"lowercase secret", codeAt(0), " gezdgnbvgy3tqojqgezdgnbvgy3tqojq ", counter, true},
+		{"beyond the skew", codeAt(-2 * Period), rfcSecret, 0, false},
+		{"wrong code", "000000", rfcSecret, 0, false},
+		{"too short", codeAt(0)[:Digits-1], rfcSecret, 0, false},
+		{"too long", codeAt(0) + "0", rfcSecret, 0, false},
+		{"invalid secret", codeAt(0), "not base32!", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateStep(tt.code, tt.secret, now)
+			if ok != tt.ok || step != tt.step {
+				t.Errorf("ValidateStep(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.step, tt.ok)
+			}
+
+			if Validate(tt.code, tt.secret, now) != tt.ok {
+				t.Errorf("Validate(%q) = %v, want %v", tt.code, !tt.ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret error: %v", err)
+	}
+
+	key, err := b32.DecodeString(secret)
+	if err != nil || len(key) != SecretSize {
+		t.Errorf("GenerateSecret = %q, want %d base32 bytes", secret, SecretSize)
+	}
+}
+
+func TestKeyURI(t *testing.T) {
+	uri, err := url.Parse(KeyURI("User Manager", "ann@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("KeyURI does not parse: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/User Manager:ann@example.com" {
+		t.Errorf("KeyURI = %s, want otpauth://totp/User Manager:ann@example.com", uri)
+	}
+
+	query := uri.Query()
+	for key, want := range map[string]string{
+		"secret": rfcSecret,
+		"issuer": "User Manager",
+		"algorithm": "SHA1",
+		"digits": "6",
+		"period": "30",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("KeyURI %s = %q, want %q", key, got, want)
+		}
+	}
+}
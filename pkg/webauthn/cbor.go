@@ -0,0 +1,151 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+const (
+	// Authenticator payloads nest a few levels at most, the limit keeps
+	// hostile input from exhausting the stack.
+	maxCBORDepth = 16
+	// Capacity reserved up front for an array or map, larger ones grow as
+	// their items are actually decoded.
+	maxCBORPrealloc = 64
+)
+
+// decodeCBOR decodes the subset of CBOR used by authenticators (RFC 8949 without
+// floats or indefinite lengths) and returns the remaining bytes. The input is
+// untrusted, lengths are checked against the bytes left before anything is
+// allocated.
+func decodeCBOR(data []byte) (any, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("cbor: nested too deeply")
+	}
+
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		default:
+			return nil, nil, errors.New("cbor: unsupported simple value")
+		}
+	}
+
+	length, data, err := readCBORLength(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0, 1:
+		if length > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflows int64")
+		}
+		if major == 1 {
+			return -1 - int64(length), data, nil
+		}
+		return int64(length), data, nil
+	case 2, 3:
+		if uint64(len(data)) < length {
+			return nil, nil, errCBORTruncated
+		}
+		value := data[:length]
+		if major == 3 {
+			return string(value), data[length:], nil
+		}
+		return append([]byte(nil), value...), data[length:], nil
+	case 4:
+		// Every item takes at least a byte.
+		if uint64(len(data)) < length {
+			return nil, nil, errCBORTruncated
+		}
+		items := make([]any, 0, min(length, maxCBORPrealloc))
+		for i := uint64(0); i < length; i++ {
+			var item any
+			item, data, err = decodeCBORItem(data, depth + 1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case 5:
+		if uint64(len(data)) / 2 < length {
+			return nil, nil, errCBORTruncated
+		}
+		items := make(map[any]any, min(length, maxCBORPrealloc))
+		for i := uint64(0); i < length; i++ {
+			var key, value any
+			key, data, err = decodeCBORItem(data, depth + 1)
+			if err != nil {
+				return nil, nil, err
+			}
+			// Arrays, maps and byte strings cannot be map keys in Go.
+			switch key.(type) {
+			case int64, string, bool, nil:
+			default:
+				return nil, nil, errors.New("cbor: unsupported map key")
+			}
+			value, data, err = decodeCBORItem(data, depth + 1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items[key] = value
+		}
+		return items, data, nil
+	case 6:
+		// Tags carry no meaning for WebAuthn payloads, return the tagged item.
+		return decodeCBORItem(data, depth + 1)
+	}
+
+	return nil, nil, errors.New("cbor: unsupported major type")
+}
+
+func readCBORLength(info byte, data []byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, nil, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data), data[8:], nil
+	}
+
+	return 0, nil, errors.New("cbor: indefinite lengths are not supported")
+}
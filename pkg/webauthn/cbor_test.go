@@ -0,0 +1,147 @@
+package webauthn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name 	string
+		input 	[]byte
+		want 	any
+		rest 	[]byte
+	}{
+		{"small uint", []byte{0x17}, int64(23), []byte{}},
+		{"uint8", []byte{0x18, 0xff}, int64(255), []byte{}},
+		{"uint16", []byte{0x19, 0x01, 0x00}, int64(256), []byte{}},
+		{"uint32", []byte{0x1a, 0x00, 0x01, 0x00, 0x00}, int64(65536), []byte{}},
+		{"negative", []byte{0x26}, int64(-7), []byte{}},
+		{"negative uint8", []byte{0x38, 0x63}, int64(-100), []byte{}},
+		{"bytes", []byte{0x43, 0x01, 0x02, 0x03}, []byte{1, 2, 3}, []byte{}},
+		{"text", []byte{0x63, 'f', 'm', 't'}, "fmt", []byte{}},
+		{"array", []byte{0x82, 0x01, 0x20}, []any{int64(1), int64(-1)}, []byte{}},
+		{"map", []byte{0xa2, 0x01, 0x02, 0x20, 0x41, 0xaa}, map[any]any{int64(1): int64(2), int64(-1): []byte{0xaa}}, []byte{}},
+		{"text key", []byte{0xa1, 0x61, 'a', 0xf5}, map[any]any{"a": true}, []byte{}},
+		{"tag", []byte{0xc2, 0x41, 0x01}, []byte{1}, []byte{}},
+		{"simple values", []byte{0xf4}, false, []byte{}},
+		{"null", []byte{0xf6}, nil, []byte{}},
+		{"trailing bytes", []byte{0x01, 0x02, 0x03}, int64(1), []byte{0x02, 0x03}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := decodeCBOR(tt.input)
+			if err != nil {
+				t.Fatalf("decodeCBOR(%x) error: %v", tt.input, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCBOR(%x) = %#v, want %#v", tt.input, got, tt.want)
+			}
+
+			if !bytes.Equal(rest, tt.rest) {
+				t.Errorf("decodeCBOR(%x) rest = %x, want %x", tt.input, rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	deep := bytes.Repeat([]byte{0x81}, maxCBORDepth + 2)
+	deep = append(deep, 0x01)
+	deepTags := bytes.Repeat([]byte{0xc0}, maxCBORDepth + 2)
+	deepTags = append(deepTags, 0x01)
+
+	tests := []struct {
+		name 	string
+		input 	[]byte
+	}{
+		{"empty", []byte{}},
+		{"missing uint8", []byte{0x18}},
+		{"short uint16", []byte{0x19, 0x01}},
+		{"short uint32", []byte{0x1a, 0x00, 0x01}},
+		{"short uint64", []byte{0x1b, 0x00, 0x00, 0x00}},
+		{"uint overflows int64", []byte{0x1b, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{"negative overflows int64", []byte{0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"short bytes", []byte{0x45, 0x01, 0x02}},
+		{"huge bytes", []byte{0x5b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge array", []byte{0x9b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"array larger than input", []byte{0x9a, 0x00, 0x10, 0x00, 0x00, 0x01}},
+		{"huge map", []byte{0xbb, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"map larger than input", []byte{0xa3, 0x01, 0x02, 0x03}},
+		{"array item truncated", []byte{0x82, 0x01}},
+		{"map value missing", []byte{0xa1, 0x01}},
+		{"byte string key", []byte{0xa1, 0x41, 0x01, 0x01}},
+		{"array key", []byte{0xa1, 0x80, 0x01}},
+		{"map key", []byte{0xa1, 0xa0, 0x01}},
+		{"indefinite length", []byte{0x9f, 0x01, 0xff}},
+		{"reserved length", []byte{0x1c}},
+		{"float", []byte{0xf9, 0x3c, 0x00}},
+		{"nested too deeply", deep},
+		{"tags nested too deeply", deepTags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, err := decodeCBOR(tt.input); err == nil {
+				t.Errorf("decodeCBOR(%x) = %#v, want an error", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyMalformed(t *testing.T) {
+	tests := []struct {
+		name 	string
+		input 	[]byte
+	}{
+		{"empty", []byte{}},
+		{"huge array", []byte{0x9b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"not a map", []byte{0x01}},
+		{"empty map", []byte{0xa0}},
+		{"unknown key type", []byte{0xa2, 0x01, 0x09, 0x03, 0x26}},
+		{"EC2 without coordinates", []byte{0xa3, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParsePublicKey(tt.input); err == nil {
+				t.Errorf("ParsePublicKey(%x) succeeded, want an error", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseAuthenticatorDataMalformed(t *testing.T) {
+	header := make([]byte, 37)
+	header[32] = flagAttestedCredData
+
+	withKey := func(key ...byte) []byte {
+		data := append([]byte(nil), header...)
+		data = append(data, make([]byte, 16)...)
+		data = append(data, 0x00, 0x01, 0xab)
+		return append(data, key...)
+	}
+
+	tests := []struct {
+		name 	string
+		input 	[]byte
+	}{
+		{"short", make([]byte, 36)},
+		{"missing AAGUID", header},
+		{"credential ID past the end", append(append(append([]byte(nil), header...), make([]byte, 16)...), 0xff, 0xff)},
+		{"missing public key", withKey()},
+		{"huge public key array", withKey(0x9b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)},
+		{"deep public key", withKey(bytes.Repeat([]byte{0x81}, maxCBORDepth + 2)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAuthenticatorData(tt.input); err == nil {
+				t.Errorf("ParseAuthenticatorData(%x) succeeded, want an error", tt.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithm identifiers supported for credentials.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+const (
+	coseKeyTypeOKP int64 = 1
+	coseKeyTypeEC2 int64 = 2
+	coseKeyTypeRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveEd25519 int64 = 6
+)
+
+var ErrUnsupportedKey = errors.New("webauthn: unsupported credential public key")
+
+// ParsePublicKey decodes a COSE_Key as stored for a credential.
+func ParsePublicKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	decoded, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, 0, ErrUnsupportedKey
+	}
+
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+
+	switch kty {
+	case coseKeyTypeEC2:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if alg != AlgES256 || crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, ErrUnsupportedKey
+		}
+
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, 0, ErrUnsupportedKey
+		}
+
+		return pub, alg, nil
+	case coseKeyTypeRSA:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if alg != AlgRS256 || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, ErrUnsupportedKey
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, alg, nil
+	case coseKeyTypeOKP:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		if alg != AlgEdDSA || crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, ErrUnsupportedKey
+		}
+
+		return ed25519.PublicKey(x), alg, nil
+	}
+
+	return nil, 0, ErrUnsupportedKey
+}
+
+func verifySignature(pub crypto.PublicKey, alg int64, data, signature []byte) bool {
+	switch alg {
+	case AlgES256:
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case AlgRS256:
+		key, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case AlgEdDSA:
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return false
+		}
+		return ed25519.Verify(key, data, signature)
+	}
+
+	return false
+}
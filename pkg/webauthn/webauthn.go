@@ -0,0 +1,320 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+const (
+	flagUserPresent      byte = 0x01
+	flagUserVerified     byte = 0x04
+	flagBackupEligible   byte = 0x08
+	flagAttestedCredData byte = 0x40
+
+	ChallengeSize = 32
+)
+
+var (
+	ErrInvalidClientData = errors.New("webauthn: invalid client data")
+	ErrInvalidAuthData   = errors.New("webauthn: invalid authenticator data")
+	ErrInvalidSignature  = errors.New("webauthn: invalid signature")
+)
+
+type Config struct {
+	RPID    string
+	RPName  string
+	Origins []string
+}
+
+type WebAuthn struct {
+	config Config
+}
+
+// Credential is the result of a successful registration ceremony.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         string
+	BackupEligible bool
+}
+
+type AuthenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	AAGUID    []byte
+	CredID    []byte
+	PublicKey []byte
+}
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int                    `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+func New(config Config) *WebAuthn {
+	return &WebAuthn{
+		config: config,
+	}
+}
+
+func NewChallenge() (string, error) {
+	challenge := make([]byte, ChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+
+	return EncodeBase64(challenge), nil
+}
+
+func EncodeBase64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBase64 accepts both padded and unpadded base64url, browsers disagree on which to send.
+func DecodeBase64(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+func (w *WebAuthn) CreationOptions(challenge string, user UserEntity, exclude []CredentialDescriptor) CreationOptions {
+	return CreationOptions{
+		Challenge: challenge,
+		RP: RelyingParty{
+			ID:   w.config.RPID,
+			Name: w.config.RPName,
+		},
+		User: user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:            300000,
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+}
+
+func (w *WebAuthn) RequestOptions(challenge string, allow []CredentialDescriptor) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		RPID:             w.config.RPID,
+		Timeout:          300000,
+		AllowCredentials: allow,
+		UserVerification: "required",
+	}
+}
+
+// VerifyRegistration checks a navigator.credentials.create() response. Attestation
+// statements are not verified since the options always request "none".
+func (w *WebAuthn) VerifyRegistration(clientDataJSON, attestationObject []byte, challenge string) (Credential, error) {
+	if err := w.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return Credential{}, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	attestation, ok := decoded.(map[any]any)
+	if !ok {
+		return Credential{}, ErrInvalidAuthData
+	}
+
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return Credential{}, ErrInvalidAuthData
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	if err := w.verifyAuthData(authData); err != nil {
+		return Credential{}, err
+	}
+
+	if authData.Flags&flagAttestedCredData == 0 || len(authData.CredID) == 0 {
+		return Credential{}, ErrInvalidAuthData
+	}
+
+	if _, _, err := ParsePublicKey(authData.PublicKey); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:             authData.CredID,
+		PublicKey:      authData.PublicKey,
+		SignCount:      authData.SignCount,
+		AAGUID:         hex.EncodeToString(authData.AAGUID),
+		BackupEligible: authData.Flags&flagBackupEligible != 0,
+	}, nil
+}
+
+// VerifyAssertion checks a navigator.credentials.get() response against the stored
+// public key and returns the authenticator's signature counter.
+func (w *WebAuthn) VerifyAssertion(clientDataJSON, rawAuthData, signature []byte, challenge string, publicKey []byte) (uint32, error) {
+	if err := w.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.verifyAuthData(authData); err != nil {
+		return 0, err
+	}
+
+	pub, alg, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+
+	if !verifySignature(pub, alg, signed, signature) {
+		return 0, ErrInvalidSignature
+	}
+
+	return authData.SignCount, nil
+}
+
+func (w *WebAuthn) verifyClientData(clientDataJSON []byte, ceremony, challenge string) error {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return ErrInvalidClientData
+	}
+
+	if data.Type != ceremony {
+		return ErrInvalidClientData
+	}
+
+	if subtle.ConstantTimeCompare([]byte(strings.TrimRight(data.Challenge, "=")), []byte(challenge)) != 1 {
+		return ErrInvalidClientData
+	}
+
+	if !slices.Contains(w.config.Origins, data.Origin) {
+		return ErrInvalidClientData
+	}
+
+	return nil
+}
+
+func (w *WebAuthn) verifyAuthData(authData AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(w.config.RPID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return ErrInvalidAuthData
+	}
+
+	if authData.Flags&flagUserPresent == 0 || authData.Flags&flagUserVerified == 0 {
+		return ErrInvalidAuthData
+	}
+
+	return nil
+}
+
+func ParseAuthenticatorData(data []byte) (AuthenticatorData, error) {
+	if len(data) < 37 {
+		return AuthenticatorData{}, ErrInvalidAuthData
+	}
+
+	authData := AuthenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if authData.Flags&flagAttestedCredData == 0 {
+		return authData, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return AuthenticatorData{}, ErrInvalidAuthData
+	}
+
+	authData.AAGUID = rest[:16]
+	credIDLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < credIDLength {
+		return AuthenticatorData{}, ErrInvalidAuthData
+	}
+
+	authData.CredID = rest[:credIDLength]
+	rest = rest[credIDLength:]
+
+	_, remaining, err := decodeCBOR(rest)
+	if err != nil {
+		return AuthenticatorData{}, ErrInvalidAuthData
+	}
+
+	authData.PublicKey = rest[:len(rest)-len(remaining)]
+
+	return authData, nil
+}
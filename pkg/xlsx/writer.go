@@ -0,0 +1,115 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	// A worksheet holds at most this many rows, header included.
+	MaxRows = 1048576
+)
+
+// The parts a workbook with a single sheet needs besides the sheet itself.
+var parts = []struct {
+	name string
+	body string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// Writer streams a workbook with one sheet. Rows go straight into the zip
+// archive, so memory does not grow with the sheet. Strings are written inline
+// rather than through a shared string table, which would have to be kept
+// until the end.
+type Writer struct {
+	zip 	*zip.Writer
+	sheet 	*bufio.Writer
+	rows 	int
+}
+
+func NewWriter(w io.Writer) (*Writer, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.WriteString(file, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := bufio.NewWriter(file)
+	if _, err := sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		zip: archive,
+		sheet: sheet,
+	}, nil
+}
+
+// WriteRow appends a row. Integers and floats become numeric cells, nil an
+// empty one and anything else text.
+func (w *Writer) WriteRow(cells []any) error {
+	if w.rows >= MaxRows {
+		return fmt.Errorf("xlsx: a sheet holds at most %d rows", MaxRows)
+	}
+	w.rows++
+
+	w.sheet.WriteString(`<row r="` + strconv.Itoa(w.rows) + `">`)
+	for _, cell := range cells {
+		switch value := cell.(type) {
+		case nil:
+			w.sheet.WriteString(`<c/>`)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			fmt.Fprintf(w.sheet, `<c><v>%d</v></c>`, value)
+		case float32, float64:
+			fmt.Fprintf(w.sheet, `<c><v>%v</v></c>`, value)
+		default:
+			w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(w.sheet, []byte(fmt.Sprint(value))); err != nil {
+				return err
+			}
+			w.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Close ends the sheet and the archive, it does not close the underlying
+// writer.
+func (w *Writer) Close() error {
+	if _, err := w.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}